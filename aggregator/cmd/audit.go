@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/pkg"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var auditBatchFlag = &cli.StringFlag{
+	Name:     "batch",
+	Required: true,
+	Usage:    "Batch identifier hash or batch merkle root, 0x prefixed",
+}
+
+// auditCommand reads the config file given to the aggregator, as in
+// `aggregator --config config.yaml audit filter --batch 0x...`
+var auditCommand = &cli.Command{
+	Name:  "audit",
+	Usage: "Read the audit log of the aggregator decisions",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "filter",
+			Usage:  "Print the records of a batch as JSON lines, oldest first",
+			Flags:  []cli.Flag{auditBatchFlag},
+			Action: filterAuditLog,
+		},
+		{
+			Name:   "verify",
+			Usage:  "Check that no record of the audit log was modified, removed or reordered",
+			Action: verifyAuditLog,
+		},
+	},
+}
+
+// auditLogPath only reads the aggregator section of the config, so the log can be read
+// without a connection to Ethereum
+func auditLogPath(ctx *cli.Context) (string, error) {
+	var aggregatorConfigFromYaml config.AggregatorConfigFromYaml
+	err := sdkutils.ReadYamlConfig(ctx.String(config.ConfigFileFlag.Name), &aggregatorConfigFromYaml)
+	if err != nil {
+		return "", err
+	}
+	if aggregatorConfigFromYaml.Aggregator.AuditLogPath == "" {
+		return "", fmt.Errorf("audit_log_path is not set in the aggregator config")
+	}
+	return aggregatorConfigFromYaml.Aggregator.AuditLogPath, nil
+}
+
+func filterAuditLog(ctx *cli.Context) error {
+	path, err := auditLogPath(ctx)
+	if err != nil {
+		return err
+	}
+	batch := common.HexToHash(ctx.String(auditBatchFlag.Name))
+
+	return pkg.ReadAuditLog(path, func(record pkg.AuditRecord) error {
+		if record.BatchIdentifierHash != batch && record.BatchMerkleRoot != batch {
+			return nil
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(ctx.App.Writer, string(line))
+		return nil
+	})
+}
+
+func verifyAuditLog(ctx *cli.Context) error {
+	path, err := auditLogPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := 0
+	err = pkg.ReadAuditLog(path, func(pkg.AuditRecord) error {
+		records++
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("audit log verification failed after %d valid records: %w", records, err)
+	}
+	fmt.Fprintf(ctx.App.Writer, "Audit log is valid, %d records\n", records)
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/pkg"
+)
+
+func TestAuditFilterPrintsRecordsOfBatch(t *testing.T) {
+	dir := t.TempDir()
+	auditLogPath := filepath.Join(dir, "audit.jsonl")
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("aggregator:\n  audit_log_path: "+auditLogPath+"\n"), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	batch, otherBatch, batchMerkleRoot := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	auditLog, err := pkg.NewAuditLog(auditLogPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	records := []pkg.AuditRecord{
+		{Event: pkg.AuditTaskAdded, BatchIdentifierHash: batch, BatchMerkleRoot: batchMerkleRoot},
+		{Event: pkg.AuditTaskAdded, BatchIdentifierHash: otherBatch},
+		{Event: pkg.AuditQuorumReached, BatchIdentifierHash: batch, BatchMerkleRoot: batchMerkleRoot},
+	}
+	for _, record := range records {
+		if err := auditLog.Append(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	auditLog.Close()
+
+	// The batch is found by its identifier hash and by its merkle root
+	for _, filter := range []common.Hash{batch, batchMerkleRoot} {
+		var out bytes.Buffer
+		app := &cli.App{Flags: flags, Commands: []*cli.Command{auditCommand}, Writer: &out}
+		err := app.Run([]string{"aggregator", "--config", configPath, "audit", "filter", "--batch", filter.Hex()})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 records of the batch, got %q", out.String())
+		}
+		for i, expectedEvent := range []string{pkg.AuditTaskAdded, pkg.AuditQuorumReached} {
+			var record pkg.AuditRecord
+			if err := json.Unmarshal([]byte(lines[i]), &record); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if record.Event != expectedEvent || record.BatchIdentifierHash != batch {
+				t.Fatalf("Expected a %s record of the batch, got %+v", expectedEvent, record)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/aggregator/internal/pkg"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var batchIdentifierHashFlag = &cli.StringFlag{
+	Name:     "batch-identifier-hash",
+	Required: true,
+	Usage:    "Batch identifier hash of the dead letter, 0x prefixed",
+}
+
+// deadLettersCommand reads the config file given to the aggregator, as in
+// `aggregator --config config.yaml dead-letters list`
+var deadLettersCommand = &cli.Command{
+	Name:  "dead-letters",
+	Usage: "Manage the aggregated responses that could not be sent",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List the dead letters, oldest first",
+			Action: listDeadLetters,
+		},
+		{
+			Name:   "inspect",
+			Usage:  "Print a dead letter as JSON",
+			Flags:  []cli.Flag{batchIdentifierHashFlag},
+			Action: inspectDeadLetter,
+		},
+		{
+			Name:   "resubmit",
+			Usage:  "Send a dead letter again with the aggregator wallet, removing it once the batch is responded",
+			Flags:  []cli.Flag{batchIdentifierHashFlag},
+			Action: resubmitDeadLetter,
+		},
+	},
+}
+
+// openDeadLetterStore only reads the aggregator section of the config, so listing and
+// inspecting don't need a connection to Ethereum
+func openDeadLetterStore(ctx *cli.Context) (*pkg.DeadLetterStore, error) {
+	var aggregatorConfigFromYaml config.AggregatorConfigFromYaml
+	err := sdkutils.ReadYamlConfig(ctx.String(config.ConfigFileFlag.Name), &aggregatorConfigFromYaml)
+	if err != nil {
+		return nil, err
+	}
+	if aggregatorConfigFromYaml.Aggregator.DeadLetterStorePath == "" {
+		return nil, fmt.Errorf("dead_letter_store_path is not set in the aggregator config")
+	}
+	return pkg.NewDeadLetterStore(aggregatorConfigFromYaml.Aggregator.DeadLetterStorePath)
+}
+
+func listDeadLetters(ctx *cli.Context) error {
+	store, err := openDeadLetterStore(ctx)
+	if err != nil {
+		return err
+	}
+	deadLetters, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(deadLetters) == 0 {
+		fmt.Println("No dead letters")
+		return nil
+	}
+	for _, deadLetter := range deadLetters {
+		fmt.Printf("%s  merkle_root=%s sender=%s task_created_block=%d attempts=%d created_at=%s last_error=%q\n",
+			deadLetter.BatchIdentifierHash.Hex(),
+			deadLetter.BatchMerkleRoot.Hex(),
+			deadLetter.SenderAddress.Hex(),
+			deadLetter.TaskCreatedBlock,
+			deadLetter.Attempts,
+			deadLetter.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			deadLetter.LastError)
+	}
+	return nil
+}
+
+func inspectDeadLetter(ctx *cli.Context) error {
+	store, err := openDeadLetterStore(ctx)
+	if err != nil {
+		return err
+	}
+	deadLetter, err := store.Get(common.HexToHash(ctx.String(batchIdentifierHashFlag.Name)))
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(deadLetter, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+func resubmitDeadLetter(ctx *cli.Context) error {
+	store, err := openDeadLetterStore(ctx)
+	if err != nil {
+		return err
+	}
+	deadLetter, err := store.Get(common.HexToHash(ctx.String(batchIdentifierHashFlag.Name)))
+	if err != nil {
+		return err
+	}
+
+	aggregatorConfig := config.NewAggregatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	avsReader, err := chainio.NewAvsReaderFromConfig(aggregatorConfig.BaseConfig, aggregatorConfig.EcdsaConfig)
+	if err != nil {
+		return err
+	}
+	avsWriter, err := chainio.NewAvsWriterFromConfig(aggregatorConfig.BaseConfig, aggregatorConfig.EcdsaConfig)
+	if err != nil {
+		return err
+	}
+	avsWriter.TxManager.SetConfig(pkg.TxManagerConfigFromAggregatorConfig(aggregatorConfig))
+	avsWriter.SimulationOnly = aggregatorConfig.Aggregator.ShadowMode
+
+	txCtx, cancel := context.WithTimeout(context.Background(), pkg.SendAggregatedResponseTimeout)
+	defer cancel()
+	txHash, err := pkg.ResubmitDeadLetter(txCtx, store, avsReader, avsWriter, deadLetter)
+	if err != nil {
+		return err
+	}
+
+	if txHash == nil {
+		fmt.Println("Batch was already responded, dead letter removed")
+	} else {
+		fmt.Println("Aggregated response sent, dead letter removed. Transaction hash:", txHash.Hex())
+	}
+	return nil
+}
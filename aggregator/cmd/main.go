@@ -31,6 +31,7 @@ func main() {
 	app.Usage = "Aligned Layer Aggregator"
 	app.Description = "Service that aggregates signed responses from operator nodes."
 	app.Action = aggregatorMain
+	app.Commands = []*cli.Command{deadLettersCommand, auditCommand}
 
 	err := app.Run(os.Args)
 	if err != nil {
@@ -60,6 +61,10 @@ func aggregatorMain(ctx *cli.Context) error {
 
 	// Listen for new task created in the ServiceManager contract in a separate goroutine, both V1 and V2 subscriptions:
 	go func() {
+		// Tasks that were pending before a restart are recovered first
+		aggregator.RecoverPendingTasks()
+		// Batches created while the aggregator was down, or missed by the subscription, are initialized too
+		go aggregator.BackfillTasks()
 		listenErr := aggregator.SubscribeToNewTasks()
 		if listenErr != nil {
 			aggregatorConfig.BaseConfig.Logger.Fatal("Error subscribing for new tasks", "err", listenErr)
@@ -0,0 +1,277 @@
+package pkg
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AdminTask is a task as listed by the admin API
+type AdminTask struct {
+	TaskIndex           uint32         `json:"task_index"`
+	BatchIdentifierHash common.Hash    `json:"batch_identifier_hash"`
+	BatchMerkleRoot     common.Hash    `json:"batch_merkle_root"`
+	SenderAddress       common.Address `json:"sender_address"`
+	TaskCreatedBlock    uint64         `json:"task_created_block"`
+	State               TaskState      `json:"state"`
+}
+
+// AdminTaskDetail adds the signers and the outcome of the response to an AdminTask
+type AdminTaskDetail struct {
+	AdminTask
+	Signers      []AdminTaskSigner `json:"signers"`
+	ResponseTx   *common.Hash      `json:"response_tx,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	InDeadLetter bool              `json:"in_dead_letter"`
+}
+
+// AdminTaskSigner is an operator that signed a task. StakeShare is its share of the total stake
+// of each quorum at the task creation block, keyed by quorum number.
+type AdminTaskSigner struct {
+	OperatorId string            `json:"operator_id"`
+	StakeShare map[uint8]float64 `json:"stake_share,omitempty"`
+}
+
+// ServeAdminApi starts the admin HTTP API. Every request needs the `admin_api_token` as a bearer token.
+func (agg *Aggregator) ServeAdminApi() error {
+	server := http.Server{
+		Addr:              agg.AggregatorConfig.Aggregator.AdminApiIpPortAddress,
+		Handler:           agg.adminApiHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	agg.logger.Info("Starting admin API on address", "address",
+		agg.AggregatorConfig.Aggregator.AdminApiIpPortAddress)
+	return server.ListenAndServe()
+}
+
+func (agg *Aggregator) adminApiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", agg.handleAdminListTasks)
+	mux.HandleFunc("GET /tasks/{batchIdentifierHash}", agg.handleAdminGetTask)
+	mux.HandleFunc("POST /tasks/{batchIdentifierHash}/resubmit", agg.handleAdminResubmitTask)
+	mux.HandleFunc("GET /operators", agg.handleAdminListOperators)
+	return agg.adminAuth(mux)
+}
+
+func (agg *Aggregator) adminAuth(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + agg.AggregatorConfig.Aggregator.AdminApiToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeAdminError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (agg *Aggregator) handleAdminListTasks(w http.ResponseWriter, r *http.Request) {
+	agg.taskMutex.Lock()
+	tasks := make([]AdminTask, 0, len(agg.batchesIdentifierHashByIdx))
+	for taskIndex := range agg.batchesIdentifierHashByIdx {
+		tasks = append(tasks, agg.adminTask(taskIndex))
+	}
+	agg.taskMutex.Unlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].TaskIndex < tasks[j].TaskIndex
+	})
+	writeAdmin(w, http.StatusOK, tasks)
+}
+
+func (agg *Aggregator) handleAdminGetTask(w http.ResponseWriter, r *http.Request) {
+	batchIdentifierHash, ok := parseBatchIdentifierHash(r.PathValue("batchIdentifierHash"))
+	if !ok {
+		writeAdminError(w, http.StatusBadRequest, "invalid batch identifier hash")
+		return
+	}
+
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	if !ok {
+		agg.taskMutex.Unlock()
+		writeAdminError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	detail := AdminTaskDetail{AdminTask: agg.adminTask(taskIndex)}
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		detail.ResponseTx = progress.txHash
+		detail.Error = progress.err
+	}
+	signers := make([]eigentypes.OperatorId, 0, len(agg.signersByIdx[taskIndex]))
+	for operatorId := range agg.signersByIdx[taskIndex] {
+		signers = append(signers, operatorId)
+	}
+	operatorsAvsState := agg.operatorsAvsStateByIdx[taskIndex]
+	agg.taskMutex.Unlock()
+
+	detail.Signers = signersWithStakeShare(signers, operatorsAvsState)
+	if agg.deadLetterStore != nil {
+		_, err := agg.deadLetterStore.Get(batchIdentifierHash)
+		detail.InDeadLetter = err == nil
+	}
+	writeAdmin(w, http.StatusOK, detail)
+}
+
+// handleAdminResubmitTask sends the aggregated response of a failed task again, in the background
+func (agg *Aggregator) handleAdminResubmitTask(w http.ResponseWriter, r *http.Request) {
+	batchIdentifierHash, ok := parseBatchIdentifierHash(r.PathValue("batchIdentifierHash"))
+	if !ok {
+		writeAdminError(w, http.StatusBadRequest, "invalid batch identifier hash")
+		return
+	}
+	// Standbys don't send responses, resubmitting from one could race with the leader
+	if !agg.leaderLease.IsLeader() {
+		writeAdminError(w, http.StatusConflict, "this aggregator is not the leader")
+		return
+	}
+
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	progress := agg.taskProgressByIdx[taskIndex]
+	if !ok || progress == nil {
+		agg.taskMutex.Unlock()
+		writeAdminError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if progress.state != TaskStateFailed {
+		agg.taskMutex.Unlock()
+		writeAdminError(w, http.StatusConflict, "only failed tasks can be resubmitted, task is "+string(progress.state))
+		return
+	}
+	if progress.nonSignerStakesAndSignature == nil {
+		agg.taskMutex.Unlock()
+		writeAdminError(w, http.StatusConflict, "task never reached quorum, there is no aggregated response to resubmit")
+		return
+	}
+	// Set while holding the mutex so concurrent requests don't resubmit twice
+	progress.state = TaskStateTxSent
+	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
+	taskCreatedBlock := agg.batchCreatedBlockByIdx[taskIndex]
+	nonSignerStakesAndSignature := *progress.nonSignerStakesAndSignature
+	agg.taskMutex.Unlock()
+
+	agg.logger.Info("Resubmitting task from the admin API", "taskIndex", taskIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+
+	go func() {
+		if agg.deadLetterStore != nil {
+			deadLetter, err := agg.deadLetterStore.Get(batchIdentifierHash)
+			if err == nil {
+				agg.resubmitTaskDeadLetter(taskIndex, deadLetter)
+				return
+			}
+			if !errors.Is(err, ErrDeadLetterNotFound) {
+				agg.logger.Error("Could not read dead letter", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+			}
+		}
+		agg.respondTask(taskIndex, batchIdentifierHash, batchData, taskCreatedBlock, nonSignerStakesAndSignature)
+	}()
+
+	writeAdmin(w, http.StatusAccepted, map[string]string{"status": "resubmitting"})
+}
+
+// handleAdminListOperators returns the operator scoreboard, least reliable operators first
+func (agg *Aggregator) handleAdminListOperators(w http.ResponseWriter, r *http.Request) {
+	writeAdmin(w, http.StatusOK, agg.operatorScoreboard.Scores())
+}
+
+// resubmitTaskDeadLetter sends a dead letter once, keeping it in the store if it fails again
+func (agg *Aggregator) resubmitTaskDeadLetter(taskIndex uint32, deadLetter DeadLetter) {
+	ctx, cancel := context.WithTimeout(context.Background(), SendAggregatedResponseTimeout)
+	defer cancel()
+	txHash, err := ResubmitDeadLetter(ctx, agg.deadLetterStore, agg.avsReader, agg.avsWriter, deadLetter)
+	if err != nil {
+		agg.logger.Error("Dead letter resubmission failed", "batchIdentifierHash", deadLetter.BatchIdentifierHash.Hex(), "err", err)
+		agg.setTaskFailed(taskIndex, err)
+		return
+	}
+	if txHash != nil {
+		agg.metrics.IncAggregatedResponses()
+	}
+	agg.setTaskConfirmed(taskIndex, txHash)
+	agg.forgetTask(deadLetter.BatchIdentifierHash)
+}
+
+// adminTask must be called while holding taskMutex
+func (agg *Aggregator) adminTask(taskIndex uint32) AdminTask {
+	batchIdentifierHash := agg.batchesIdentifierHashByIdx[taskIndex]
+	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
+	task := AdminTask{
+		TaskIndex:           taskIndex,
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchData.BatchMerkleRoot,
+		SenderAddress:       batchData.SenderAddress,
+		TaskCreatedBlock:    agg.batchCreatedBlockByIdx[taskIndex],
+	}
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		task.State = progress.state
+	}
+	return task
+}
+
+// signersWithStakeShare computes the stake share of each signer over every operator registered at the
+// task creation block. The share is left out if the operators state was not fetched for the task.
+func signersWithStakeShare(signers []eigentypes.OperatorId, operatorsAvsState map[eigentypes.OperatorId]eigentypes.OperatorAvsState) []AdminTaskSigner {
+	totalStakePerQuorum := make(map[eigentypes.QuorumNum]*big.Int)
+	for _, operatorAvsState := range operatorsAvsState {
+		for quorumNum, stake := range operatorAvsState.StakePerQuorum {
+			if _, ok := totalStakePerQuorum[quorumNum]; !ok {
+				totalStakePerQuorum[quorumNum] = new(big.Int)
+			}
+			totalStakePerQuorum[quorumNum].Add(totalStakePerQuorum[quorumNum], stake)
+		}
+	}
+
+	result := make([]AdminTaskSigner, 0, len(signers))
+	for _, operatorId := range signers {
+		signer := AdminTaskSigner{OperatorId: "0x" + hex.EncodeToString(operatorId[:])}
+		if operatorAvsState, ok := operatorsAvsState[operatorId]; ok {
+			signer.StakeShare = make(map[uint8]float64)
+			for quorumNum, stake := range operatorAvsState.StakePerQuorum {
+				total := totalStakePerQuorum[quorumNum]
+				if total.Sign() == 0 {
+					continue
+				}
+				share, _ := new(big.Rat).SetFrac(stake, total).Float64()
+				signer.StakeShare[uint8(quorumNum)] = share
+			}
+		}
+		result = append(result, signer)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OperatorId < result[j].OperatorId
+	})
+	return result
+}
+
+func parseBatchIdentifierHash(value string) ([32]byte, bool) {
+	var batchIdentifierHash [32]byte
+	decoded, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil || len(decoded) != len(batchIdentifierHash) {
+		return batchIdentifierHash, false
+	}
+	copy(batchIdentifierHash[:], decoded)
+	return batchIdentifierHash, true
+}
+
+func writeAdmin(w http.ResponseWriter, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	writeAdmin(w, status, map[string]string{"error": message})
+}
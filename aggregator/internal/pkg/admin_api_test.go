@@ -0,0 +1,191 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+const testAdminApiToken = "admin-token"
+
+func newTestAdminApi(t *testing.T, agg *testAggregator) *httptest.Server {
+	t.Helper()
+	agg.AggregatorConfig.Aggregator.AdminApiToken = testAdminApiToken
+	server := httptest.NewServer(agg.adminApiHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// adminRequest sends a request with the admin token and returns the response status
+func adminRequest(t *testing.T, server *httptest.Server, method string, path string, token string) int {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	response.Body.Close()
+	return response.StatusCode
+}
+
+func TestAdminApiRequiresToken(t *testing.T) {
+	server := newTestAdminApi(t, newTestAggregator(t))
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong token", "other-token", http.StatusUnauthorized},
+		{"admin token", testAdminApiToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := adminRequest(t, server, http.MethodGet, "/tasks", tt.token); status != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestAdminApiResubmitTask(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := newTestAdminApi(t, agg)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	taskPath := "/tasks/0x" + hex.EncodeToString(batchIdentifierHash[:])
+
+	if status := adminRequest(t, server, http.MethodGet, "/tasks/0x"+hex.EncodeToString(make([]byte, 32)), testAdminApiToken); status != http.StatusNotFound {
+		t.Fatalf("Expected an unknown task to be not found, got %d", status)
+	}
+	if status := adminRequest(t, server, http.MethodPost, "/tasks/0x"+hex.EncodeToString(make([]byte, 32))+"/resubmit", testAdminApiToken); status != http.StatusNotFound {
+		t.Fatalf("Expected an unknown task to be not found, got %d", status)
+	}
+
+	// Only failed tasks can be resubmitted
+	if status := adminRequest(t, server, http.MethodPost, taskPath+"/resubmit", testAdminApiToken); status != http.StatusConflict {
+		t.Fatalf("Expected a collecting task not to be resubmitted, got %d", status)
+	}
+	agg.setTaskFailed(0, errors.New("insufficient funds"))
+	if status := adminRequest(t, server, http.MethodPost, taskPath+"/resubmit", testAdminApiToken); status != http.StatusConflict {
+		t.Fatalf("Expected a task without aggregated response not to be resubmitted, got %d", status)
+	}
+
+	agg.taskMutex.Lock()
+	agg.taskProgressByIdx[0].nonSignerStakesAndSignature = &servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{}
+	agg.taskMutex.Unlock()
+	if status := adminRequest(t, server, http.MethodPost, taskPath+"/resubmit", testAdminApiToken); status != http.StatusAccepted {
+		t.Fatalf("Expected the failed task to be resubmitted, got %d", status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(agg.writer.Responses()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the task to be responded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAdminApiStandbyDoesNotResubmit(t *testing.T) {
+	agg := newTestStandby(t)
+	server := newTestAdminApi(t, agg)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	agg.setTaskFailed(0, errors.New("insufficient funds"))
+	agg.taskMutex.Lock()
+	agg.taskProgressByIdx[0].nonSignerStakesAndSignature = &servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{}
+	agg.taskMutex.Unlock()
+
+	status := adminRequest(t, server, http.MethodPost, "/tasks/0x"+hex.EncodeToString(batchIdentifierHash[:])+"/resubmit", testAdminApiToken)
+	if status != http.StatusConflict {
+		t.Fatalf("Expected the standby to refuse the resubmission, got %d", status)
+	}
+	if state, _ := agg.taskProgress(t, 0); state != TaskStateFailed {
+		t.Fatalf("Expected the task to stay failed, got %s", state)
+	}
+}
+
+func TestSignersWithStakeShare(t *testing.T) {
+	operatorA, operatorB, operatorC := eigentypes.OperatorId{1}, eigentypes.OperatorId{2}, eigentypes.OperatorId{3}
+	operatorsAvsState := map[eigentypes.OperatorId]eigentypes.OperatorAvsState{
+		operatorA: {StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{0: big.NewInt(30), 1: big.NewInt(10)}},
+		operatorB: {StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{0: big.NewInt(70), 1: big.NewInt(0)}},
+		// Registered without stake in the quorum
+		operatorC: {StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{2: big.NewInt(0)}},
+	}
+
+	signers := signersWithStakeShare([]eigentypes.OperatorId{operatorB, operatorA, operatorC, {4}}, operatorsAvsState)
+
+	expected := []struct {
+		operatorId string
+		stakeShare map[uint8]float64
+	}{
+		{"0x01", map[uint8]float64{0: 0.3, 1: 1}},
+		{"0x02", map[uint8]float64{0: 0.7, 1: 0}},
+		{"0x03", map[uint8]float64{}},
+		// Not registered at the task creation block
+		{"0x04", nil},
+	}
+	if len(signers) != len(expected) {
+		t.Fatalf("Expected %d signers, got %d", len(expected), len(signers))
+	}
+	for i, signer := range signers {
+		if signer.OperatorId[:4] != expected[i].operatorId {
+			t.Fatalf("Expected signer %s at position %d, got %s", expected[i].operatorId, i, signer.OperatorId)
+		}
+		if (signer.StakeShare == nil) != (expected[i].stakeShare == nil) || len(signer.StakeShare) != len(expected[i].stakeShare) {
+			t.Fatalf("Expected stake share %v for %s, got %v", expected[i].stakeShare, signer.OperatorId, signer.StakeShare)
+		}
+		for quorumNum, share := range expected[i].stakeShare {
+			if signer.StakeShare[quorumNum] != share {
+				t.Fatalf("Expected stake share %v for %s, got %v", expected[i].stakeShare, signer.OperatorId, signer.StakeShare)
+			}
+		}
+	}
+}
+
+func TestAdminApiGetTaskSigners(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := newTestAdminApi(t, agg)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	operatorId := eigentypes.OperatorId{1}
+	agg.taskMutex.Lock()
+	agg.signersByIdx[0][operatorId] = time.Now()
+	agg.operatorsAvsStateByIdx[0] = map[eigentypes.OperatorId]eigentypes.OperatorAvsState{
+		operatorId:               {StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{0: big.NewInt(1)}},
+		eigentypes.OperatorId{2}: {StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{0: big.NewInt(3)}},
+	}
+	agg.taskMutex.Unlock()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/tasks/0x"+hex.EncodeToString(batchIdentifierHash[:]), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+testAdminApiToken)
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+	var detail AdminTaskDetail
+	if err := json.NewDecoder(response.Body).Decode(&detail); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if detail.State != TaskStateCollecting || len(detail.Signers) != 1 || detail.Signers[0].StakeShare[0] != 0.25 {
+		t.Fatalf("Expected the signer with a quarter of the stake, got %+v", detail)
+	}
+}
@@ -2,8 +2,12 @@ package pkg
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
+	"os"
 	"sync"
 	"time"
 
@@ -18,6 +22,7 @@ import (
 	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
 	oppubkeysserv "github.com/Layr-Labs/eigensdk-go/services/operatorsinfo"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
@@ -26,10 +31,6 @@ import (
 	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
-// FIXME(marian): Read this from Aligned contract directly
-const QUORUM_NUMBER = byte(0)
-const QUORUM_THRESHOLD = byte(67)
-
 // Aggregator stores TaskResponse for a task here
 type TaskResponses = []types.SignedTaskResponse
 
@@ -42,22 +43,27 @@ type BatchData struct {
 type Aggregator struct {
 	AggregatorConfig      *config.AggregatorConfig
 	NewBatchChan          chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
-	avsReader             *chainio.AvsReader
-	avsSubscriber         *chainio.AvsSubscriber
-	avsWriter             *chainio.AvsWriter
+	avsReader             chainio.AvsReaderer
+	avsSubscriber         chainio.AvsSubscriberer
+	avsWriter             chainio.AvsWriterer
 	taskSubscriber        chan error
-	blsAggregationService blsagg.BlsAggregationService
+	blsAggregationService *blsAggregator
+	avsRegistryService    avsregistry.AvsRegistryService
+
+	// Quorums every task is initialized with, from `quorum_numbers` and `quorum_threshold_percentages`
+	quorumNums                 eigentypes.QuorumNums
+	quorumThresholdPercentages eigentypes.QuorumThresholdPercentages
 
 	// BLS Signature Service returns an Index
 	// Since our ID is not an idx, we build this cache
 	// Note: In case of a reboot, this doesn't need to be loaded,
-	// and can start from zero
+	// and can start from zero. Pending tasks are added again from the task store
 	batchesIdentifierHashByIdx map[uint32][32]byte
 
 	// This is the counterpart,
 	// to use when we have the batch but not the index
 	// Note: In case of a reboot, this doesn't need to be loaded,
-	// and can start from zero
+	// and can start from zero. Pending tasks are added again from the task store
 	batchesIdxByIdentifierHash map[[32]byte]uint32
 
 	// Stores the taskCreatedBlock for each batch by batch index
@@ -66,16 +72,59 @@ type Aggregator struct {
 	// Stores the TaskResponse for each batch by batchIdentifierHash
 	batchDataByIdentifierHash map[[32]byte]BatchData
 
+	// Operators that sent a signature for each batch by batch index, and when they did. Used to
+	// reject duplicates before reaching the BLS aggregation service
+	signersByIdx map[uint32]map[eigentypes.OperatorId]time.Time
+
+	// Operators state at the task creation block for each batch by batch index.
+	// Fetched on the first response of the task, to check the operator and its signature
+	operatorsAvsStateByIdx map[uint32]map[eigentypes.OperatorId]eigentypes.OperatorAvsState
+
+	// Progress of each batch by batch index, shown on the admin API
+	taskProgressByIdx map[uint32]*taskProgress
+
+	// Open while the task collects signatures after reaching quorum
+	postQuorumSignaturesByIdx map[uint32]chan postQuorumSignature
+
+	operatorScoreboard *OperatorScoreboard
+
+	// Responses that arrived before their task, replayed by AddNewTask
+	pendingResponses *PendingResponses
+
+	// TLS config of the operators RPC server, nil if `server_tls_cert_path` is not set
+	serverTlsConfig *tls.Config
+
+	// Limits of the operators RPC, by remote address and by operator. nil if disabled
+	ipRateLimiter       *rateLimiter
+	operatorRateLimiter *rateLimiter
+	submissionPool      *submissionPool
+
+	// Address each operator registered with, to check request signatures
+	operatorAddressById  map[eigentypes.OperatorId]common.Address
+	operatorAddressMutex sync.Mutex
+
 	// This task index is to communicate with the local BLS
 	// Service.
 	// Note: In case of a reboot it can start from 0 again
 	nextBatchIndex uint32
 
-	// Mutex to protect batchesIdentifierHashByIdx, batchesIdxByIdentifierHash and nextBatchIndex
+	// Mutex to protect the task maps and nextBatchIndex
 	taskMutex *sync.Mutex
 
-	// Mutex to protect ethereum wallet
-	walletMutex *sync.Mutex
+	// Persists pending tasks and their signatures to recover them on restart.
+	// nil if `task_store_path` is not set
+	taskStore *TaskStore
+
+	// Stores the aggregated responses that could not be sent, to retry them later.
+	// nil if `dead_letter_store_path` is not set
+	deadLetterStore *DeadLetterStore
+
+	// Hash-chained record of every decision of the aggregator.
+	// nil if `audit_log_path` is not set
+	auditLog *AuditLog
+
+	// Only the leader submits aggregated responses, standbys keep their state warm to take over
+	leaderLease *LeaderLease
 
 	logger logging.Logger
 
@@ -105,6 +154,16 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 		return nil, err
 	}
 
+	err = avsReader.CheckQuorumsExist(aggregatorConfig.Aggregator.QuorumNumbers)
+	if err != nil {
+		return nil, err
+	}
+	avsWriter.TxManager.SetConfig(TxManagerConfigFromAggregatorConfig(&aggregatorConfig))
+
+	quorumNums := utils.BytesToQuorumNumbers(aggregatorConfig.Aggregator.QuorumNumbers)
+	quorumThresholdPercentages := utils.BytesToQuorumThresholdPercentages(aggregatorConfig.Aggregator.QuorumThresholdPercentages)
+	aggregatorConfig.BaseConfig.Logger.Info("Aggregator quorums", "quorumNumbers", quorumNums, "quorumThresholdPercentages", quorumThresholdPercentages)
+
 	batchesIdentifierHashByIdx := make(map[uint32][32]byte)
 	batchesIdxByIdentifierHash := make(map[[32]byte]uint32)
 	batchDataByIdentifierHash := make(map[[32]byte]BatchData)
@@ -145,17 +204,82 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 
 	operatorPubkeysService := oppubkeysserv.NewOperatorsInfoServiceInMemory(context.Background(), clients.AvsRegistryChainSubscriber, clients.AvsRegistryChainReader, nil, oppubkeysserv.Opts{}, logger)
 	avsRegistryService := avsregistry.NewAvsRegistryServiceChainCaller(avsReader.ChainReader, operatorPubkeysService, logger)
-	blsAggregationService := blsagg.NewBlsAggregatorService(avsRegistryService, hashFunction, logger)
+	blsAggregationService := newBlsAggregator(blsagg.NewBlsAggregatorService(avsRegistryService, hashFunction, logger))
 
 	// Metrics
 	reg := prometheus.NewRegistry()
 	aggregatorMetrics := metrics.NewMetrics(aggregatorConfig.Aggregator.MetricsIpPortAddress, reg, logger)
 
 	// Telemetry
-	aggregatorTelemetry := NewTelemetry(aggregatorConfig.Aggregator.TelemetryIpPortAddress, logger)
+	aggregatorTelemetry := NewTelemetry(TelemetryConfig{
+		ApiAddress:         aggregatorConfig.Aggregator.TelemetryIpPortAddress,
+		OtlpTracesEndpoint: aggregatorConfig.Aggregator.OtlpTracesEndpoint,
+		QueueSize:          aggregatorConfig.Aggregator.TelemetryQueueSize,
+		BatchSize:          aggregatorConfig.Aggregator.TelemetryBatchSize,
+		FlushInterval:      aggregatorConfig.Aggregator.TelemetryFlushInterval,
+		RequestTimeout:     aggregatorConfig.Aggregator.TelemetryRequestTimeout,
+		MaxRetries:         aggregatorConfig.Aggregator.TelemetryMaxRetries,
+	}, aggregatorMetrics, logger)
 
 	nextBatchIndex := uint32(0)
 
+	var taskStore *TaskStore
+	if aggregatorConfig.Aggregator.TaskStorePath != "" {
+		taskStore, err = NewTaskStore(aggregatorConfig.Aggregator.TaskStorePath)
+		if err != nil {
+			logger.Errorf("Cannot open task store", "err", err)
+			return nil, err
+		}
+	}
+
+	// Shadow aggregators never send responses, so there is nothing to retry
+	var deadLetterStore *DeadLetterStore
+	if aggregatorConfig.Aggregator.DeadLetterStorePath != "" && !aggregatorConfig.Aggregator.ShadowMode {
+		deadLetterStore, err = NewDeadLetterStore(aggregatorConfig.Aggregator.DeadLetterStorePath)
+		if err != nil {
+			logger.Errorf("Cannot open dead letter store", "err", err)
+			return nil, err
+		}
+	}
+
+	var auditLog *AuditLog
+	if aggregatorConfig.Aggregator.AuditLogPath != "" {
+		auditLog, err = NewAuditLog(aggregatorConfig.Aggregator.AuditLogPath)
+		if err != nil {
+			logger.Errorf("Cannot open audit log", "err", err)
+			return nil, err
+		}
+	}
+
+	// Shadow aggregators simulate every batch, and must not take the lease from the real leader
+	var leaderLock LeaderLock = alwaysLeaderLock{}
+	if !aggregatorConfig.Aggregator.ShadowMode {
+		if aggregatorConfig.Aggregator.LeaderLockEtcdUrl != "" {
+			hostname, _ := os.Hostname()
+			leaderLock = NewEtcdLeaderLock(aggregatorConfig.Aggregator.LeaderLockEtcdUrl, aggregatorConfig.Aggregator.LeaderLockKey,
+				fmt.Sprintf("%s:%d", hostname, os.Getpid()), aggregatorConfig.Aggregator.LeaderLockTtl)
+		} else if aggregatorConfig.Aggregator.LeaderLockPath != "" {
+			leaderLock = NewFileLeaderLock(aggregatorConfig.Aggregator.LeaderLockPath)
+		}
+	}
+	leaderLease := NewLeaderLease(leaderLock, logger, aggregatorMetrics.SetAggregatorIsLeader)
+
+	avsWriter.OnSimulatedCost = aggregatorMetrics.ObserveAggregatorSimulatedCostToFeeLimit
+	avsWriter.SimulationOnly = aggregatorConfig.Aggregator.ShadowMode
+	if aggregatorConfig.Aggregator.ShadowMode {
+		logger.Warn("Shadow mode enabled, aggregated responses are simulated and never sent")
+	}
+
+	var serverTlsConfig *tls.Config
+	if aggregatorConfig.Aggregator.ServerTlsCertPath != "" {
+		serverTlsConfig, err = config.NewServerTLSConfig(aggregatorConfig.Aggregator.ServerTlsCertPath,
+			aggregatorConfig.Aggregator.ServerTlsKeyPath, aggregatorConfig.Aggregator.ServerTlsClientCaPath)
+		if err != nil {
+			logger.Errorf("Cannot load the RPC server TLS config", "err", err)
+			return nil, err
+		}
+	}
+
 	aggregator := Aggregator{
 		AggregatorConfig: &aggregatorConfig,
 		avsReader:        avsReader,
@@ -167,11 +291,28 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 		batchesIdxByIdentifierHash: batchesIdxByIdentifierHash,
 		batchDataByIdentifierHash:  batchDataByIdentifierHash,
 		batchCreatedBlockByIdx:     batchCreatedBlockByIdx,
+		signersByIdx:               make(map[uint32]map[eigentypes.OperatorId]time.Time),
+		operatorsAvsStateByIdx:     make(map[uint32]map[eigentypes.OperatorId]eigentypes.OperatorAvsState),
+		taskProgressByIdx:          make(map[uint32]*taskProgress),
+		postQuorumSignaturesByIdx:  make(map[uint32]chan postQuorumSignature),
+		operatorScoreboard:         NewOperatorScoreboard(),
+		pendingResponses:           NewPendingResponses(aggregatorConfig.Aggregator.PendingResponseTtl, aggregatorConfig.Aggregator.MaxPendingResponses),
+		serverTlsConfig:            serverTlsConfig,
+		ipRateLimiter:              newRateLimiter(aggregatorConfig.Aggregator.RpcRateLimitPerIp, aggregatorConfig.Aggregator.RpcRateLimitBurst),
+		operatorRateLimiter:        newRateLimiter(aggregatorConfig.Aggregator.RpcRateLimitPerOperator, aggregatorConfig.Aggregator.RpcRateLimitBurst),
+		submissionPool:             newSubmissionPool(aggregatorConfig.Aggregator.RpcWorkers, aggregatorConfig.Aggregator.RpcQueueSize),
+		operatorAddressById:        make(map[eigentypes.OperatorId]common.Address),
 		nextBatchIndex:             nextBatchIndex,
 		taskMutex:                  &sync.Mutex{},
-		walletMutex:                &sync.Mutex{},
+		taskStore:                  taskStore,
+		deadLetterStore:            deadLetterStore,
+		auditLog:                   auditLog,
+		leaderLease:                leaderLease,
+		quorumNums:                 quorumNums,
+		quorumThresholdPercentages: quorumThresholdPercentages,
 
 		blsAggregationService: blsAggregationService,
+		avsRegistryService:    avsRegistryService,
 		logger:                logger,
 		metricsReg:            reg,
 		metrics:               aggregatorMetrics,
@@ -181,9 +322,29 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	return &aggregator, nil
 }
 
+// TxManagerConfigFromAggregatorConfig builds the fee configuration of the aggregated responses
+func TxManagerConfigFromAggregatorConfig(aggregatorConfig *config.AggregatorConfig) chainio.TxManagerConfig {
+	return chainio.TxManagerConfig{
+		MaxFeePerGas:         new(big.Int).SetUint64(aggregatorConfig.Aggregator.TxMaxFeePerGas),
+		MaxPriorityFeePerGas: new(big.Int).SetUint64(aggregatorConfig.Aggregator.TxMaxPriorityFeePerGas),
+		GasBumpPercentage:    aggregatorConfig.Aggregator.TxGasBumpPercentage,
+		StuckTimeout:         aggregatorConfig.Aggregator.TxStuckTimeout,
+	}
+}
+
 func (agg *Aggregator) Start(ctx context.Context) error {
 	agg.logger.Infof("Starting aggregator...")
 
+	agg.leaderLease.Renew()
+	if !agg.leaderLease.IsLeader() {
+		agg.logger.Info("Another aggregator instance holds the leader lease, starting as standby")
+	}
+	go agg.leaderLease.Run()
+	go agg.ProcessDeadLetters()
+	if agg.AggregatorConfig.Aggregator.EnableMetrics {
+		go agg.ReportWalletBalance()
+	}
+
 	go func() {
 		err := agg.ServeOperators()
 		if err != nil {
@@ -191,6 +352,24 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 		}
 	}()
 
+	if agg.AggregatorConfig.Aggregator.JsonRpcServerIpPortAddress != "" {
+		go func() {
+			err := agg.ServeJsonRpc()
+			if err != nil {
+				agg.logger.Fatal("Error listening for JSON-RPC requests", "err", err)
+			}
+		}()
+	}
+
+	if agg.AggregatorConfig.Aggregator.AdminApiIpPortAddress != "" {
+		go func() {
+			err := agg.ServeAdminApi()
+			if err != nil {
+				agg.logger.Fatal("Error serving the admin API", "err", err)
+			}
+		}()
+	}
+
 	var metricsErrChan <-chan error
 	if agg.AggregatorConfig.Aggregator.EnableMetrics {
 		metricsErrChan = agg.metrics.Start(ctx, agg.metricsReg)
@@ -213,27 +392,78 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 	}
 }
 
+// Retries cover simulation and send errors, stuck transactions are replaced by the tx manager
 const MaxSentTxRetries = 5
 
+// How long to wait between retries. A variable so tests don't wait
+var sentTxRetryDelay = 2 * time.Second
+
+// How long a single send waits for the transaction, including fee bumps
+const SendAggregatedResponseTimeout = 10 * time.Minute
+
 const BLS_AGG_SERVICE_TIMEOUT = 100 * time.Second
 
 func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
 	agg.taskMutex.Lock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Locked Resources: Fetching task data")
-	batchIdentifierHash := agg.batchesIdentifierHashByIdx[blsAggServiceResp.TaskIndex]
+	batchIdentifierHash, ok := agg.batchesIdentifierHashByIdx[blsAggServiceResp.TaskIndex]
 	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
 	taskCreatedBlock := agg.batchCreatedBlockByIdx[blsAggServiceResp.TaskIndex]
 	agg.taskMutex.Unlock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Fetching task data")
 
+	// The garbage collector evicted the task while the BLS aggregation service still had it
+	if !ok {
+		agg.logger.Warn("BLS aggregation response for an evicted task, ignoring it", "taskIndex", blsAggServiceResp.TaskIndex)
+		return
+	}
+
 	// Finish task trace once the task is processed (either successfully or not)
 	defer agg.telemetry.FinishTrace(batchData.BatchMerkleRoot)
 
 	if blsAggServiceResp.Err != nil {
+		agg.setTaskFailed(blsAggServiceResp.TaskIndex, blsAggServiceResp.Err)
 		agg.telemetry.LogTaskError(batchData.BatchMerkleRoot, blsAggServiceResp.Err)
 		agg.logger.Error("BlsAggregationServiceResponse contains an error", "err", blsAggServiceResp.Err, "batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
+		agg.recordTaskParticipation(blsAggServiceResp.TaskIndex)
+		return
+	}
+	agg.setTaskQuorumReached(blsAggServiceResp.TaskIndex)
+	agg.telemetry.LogQuorumReached(batchData.BatchMerkleRoot)
+	agg.auditQuorumReached(blsAggServiceResp.TaskIndex, batchIdentifierHash, batchData, len(blsAggServiceResp.NonSignersPubkeysG1))
+
+	agg.logger.Info("Threshold reached", "taskIndex", blsAggServiceResp.TaskIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+
+	atQuorum := blsAggServiceResp
+	blsAggServiceResp = agg.collectPostQuorumSignatures(blsAggServiceResp, taskCreatedBlock)
+	nonSignerStakesAndSignature := toNonSignerStakesAndSignature(blsAggServiceResp)
+	agg.setTaskAggregatedResponse(blsAggServiceResp.TaskIndex, nonSignerStakesAndSignature)
+	agg.recordTaskParticipation(blsAggServiceResp.TaskIndex)
+
+	// Standbys don't submit responses unless they take over before the leader responds the batch
+	if !agg.waitForLeadership(blsAggServiceResp.TaskIndex, batchIdentifierHash) {
 		return
 	}
+
+	agg.logger.Info("Maybe waiting one block to send aggregated response onchain",
+		"taskIndex", blsAggServiceResp.TaskIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+		"taskCreatedBlock", taskCreatedBlock)
+
+	err := agg.avsSubscriber.WaitForOneBlock(taskCreatedBlock)
+	if err != nil {
+		agg.logger.Error("Error waiting for one block, sending anyway", "err", err)
+	}
+
+	if len(blsAggServiceResp.NonSignersPubkeysG1) < len(atQuorum.NonSignersPubkeysG1) {
+		go agg.reportPostQuorumGasSaved(batchIdentifierHash, batchData, atQuorum, blsAggServiceResp)
+	}
+
+	agg.respondTask(blsAggServiceResp.TaskIndex, batchIdentifierHash, batchData, taskCreatedBlock, nonSignerStakesAndSignature)
+}
+
+func toNonSignerStakesAndSignature(blsAggServiceResp blsagg.BlsAggregationServiceResponse) servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature {
 	nonSignerPubkeys := []servicemanager.BN254G1Point{}
 	for _, nonSignerPubkey := range blsAggServiceResp.NonSignersPubkeysG1 {
 		nonSignerPubkeys = append(nonSignerPubkeys, utils.ConvertToBN254G1Point(nonSignerPubkey))
@@ -243,7 +473,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		quorumApks = append(quorumApks, utils.ConvertToBN254G1Point(quorumApk))
 	}
 
-	nonSignerStakesAndSignature := servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{
+	return servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{
 		NonSignerPubkeys:             nonSignerPubkeys,
 		QuorumApks:                   quorumApks,
 		ApkG2:                        utils.ConvertToBN254G2Point(blsAggServiceResp.SignersApkG2),
@@ -253,80 +483,173 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		TotalStakeIndices:            blsAggServiceResp.TotalStakeIndices,
 		NonSignerStakeIndices:        blsAggServiceResp.NonSignerStakeIndices,
 	}
+}
 
-	agg.telemetry.LogQuorumReached(batchData.BatchMerkleRoot)
-
-	agg.logger.Info("Threshold reached", "taskIndex", blsAggServiceResp.TaskIndex,
-		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
-
-	agg.logger.Info("Maybe waiting one block to send aggregated response onchain",
-		"taskIndex", blsAggServiceResp.TaskIndex,
-		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
-		"taskCreatedBlock", taskCreatedBlock)
-
-	err := agg.avsSubscriber.WaitForOneBlock(taskCreatedBlock)
-	if err != nil {
-		agg.logger.Error("Error waiting for one block, sending anyway", "err", err)
+// respondTask sends the aggregated response of a task, retrying up to MaxSentTxRetries times.
+// If every attempt fails, the response is stored as a dead letter.
+func (agg *Aggregator) respondTask(taskIndex uint32, batchIdentifierHash [32]byte, batchData BatchData, taskCreatedBlock uint64, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) {
+	if agg.AggregatorConfig.Aggregator.ShadowMode {
+		agg.simulateTaskResponse(taskIndex, batchIdentifierHash, batchData, nonSignerStakesAndSignature)
+		return
 	}
 
-	agg.logger.Info("Sending aggregated response onchain", "taskIndex", blsAggServiceResp.TaskIndex,
+	agg.logger.Info("Sending aggregated response onchain", "taskIndex", taskIndex,
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+
+	var err error
 	for i := 0; i < MaxSentTxRetries; i++ {
-		_, err = agg.sendAggregatedResponse(batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
+		// Another instance may have responded the batch before a failover
+		responded, respondedErr := agg.avsReader.IsBatchResponded(batchIdentifierHash)
+		if respondedErr == nil && responded {
+			agg.logger.Info("Batch already responded, not sending aggregated response",
+				"taskIndex", taskIndex,
+				"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+			agg.setTaskConfirmed(taskIndex, nil)
+			agg.audit(AuditRecord{
+				Event:               AuditTxConfirmed,
+				BatchIdentifierHash: batchIdentifierHash,
+				BatchMerkleRoot:     batchData.BatchMerkleRoot,
+				TaskIndex:           &taskIndex,
+				Reason:              "already_responded",
+			})
+			agg.forgetTask(batchIdentifierHash)
+			return
+		}
+
+		agg.setTaskState(taskIndex, TaskStateTxSent)
+		var receipt *gethtypes.Receipt
+		receipt, err = agg.sendAggregatedResponse(batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
 		if err == nil {
 			agg.logger.Info("Aggregator successfully responded to task",
-				"taskIndex", blsAggServiceResp.TaskIndex,
+				"taskIndex", taskIndex,
 				"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+			agg.setTaskConfirmed(taskIndex, &receipt.TxHash)
+			agg.telemetry.LogTaskResponded(batchData.BatchMerkleRoot, receipt.TxHash)
+			agg.forgetTask(batchIdentifierHash)
 
 			return
 		}
 
 		// Sleep for a bit before retrying
-		time.Sleep(2 * time.Second)
+		time.Sleep(sentTxRetryDelay)
 	}
 
-	agg.logger.Error("Aggregator failed to respond to task, this batch will be lost",
+	agg.logger.Error("Aggregator failed to respond to task",
 		"err", err,
-		"taskIndex", blsAggServiceResp.TaskIndex,
+		"taskIndex", taskIndex,
 		"merkleRoot", "0x"+hex.EncodeToString(batchData.BatchMerkleRoot[:]),
 		"senderAddress", "0x"+hex.EncodeToString(batchData.SenderAddress[:]),
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+	agg.setTaskFailed(taskIndex, err)
 	agg.telemetry.LogTaskError(batchData.BatchMerkleRoot, err)
+	agg.addDeadLetter(batchIdentifierHash, batchData, taskCreatedBlock, nonSignerStakesAndSignature, err)
 }
 
 // / Sends response to contract and waits for transaction receipt
 // / Returns error if it fails to send tx or receipt is not found
+// / Several responses may be sent at once, the tx manager assigns their nonces
 func (agg *Aggregator) sendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
-
-	agg.walletMutex.Lock()
-	agg.logger.Infof("- Locked Wallet Resources: Sending aggregated response for batch",
+	agg.logger.Info("Sending aggregated response for batch",
 		"merkleRoot", hex.EncodeToString(batchMerkleRoot[:]),
 		"senderAddress", hex.EncodeToString(senderAddress[:]),
 		"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
 
-	txHash, err := agg.avsWriter.SendAggregatedResponse(batchIdentifierHash, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
-	if err != nil {
-		agg.walletMutex.Unlock()
-		agg.logger.Infof("- Unlocked Wallet Resources: Error sending aggregated response for batch %s. Error: %s", hex.EncodeToString(batchIdentifierHash[:]), err)
-		agg.telemetry.LogTaskError(batchMerkleRoot, err)
-		return nil, err
+	ctx, cancel := context.WithTimeout(context.Background(), SendAggregatedResponseTimeout)
+	defer cancel()
+
+	// Simulated and sent in two steps, so both are in the audit log
+	var receipt *gethtypes.Receipt
+	simulation, err := agg.avsWriter.SimulateAggregatedResponse(batchIdentifierHash, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+	agg.auditCostSimulated(batchIdentifierHash, batchMerkleRoot, simulation, err)
+	if err == nil {
+		receipt, err = agg.avsWriter.SendAggregatedResponseSimulation(ctx, simulation, func(tx *gethtypes.Transaction) {
+			nonce := tx.Nonce()
+			agg.audit(AuditRecord{
+				Event:               AuditTxSent,
+				BatchIdentifierHash: batchIdentifierHash,
+				BatchMerkleRoot:     batchMerkleRoot,
+				GasLimit:            tx.Gas(),
+				TxHash:              tx.Hash().Hex(),
+				Nonce:               &nonce,
+			})
+		})
 	}
-
-	agg.walletMutex.Unlock()
-	agg.logger.Infof("- Unlocked Wallet Resources: Sending aggregated response for batch %s", hex.EncodeToString(batchIdentifierHash[:]))
-
-	receipt, err := utils.WaitForTransactionReceipt(
-		agg.AggregatorConfig.BaseConfig.EthRpcClient, context.Background(), *txHash)
 	if err != nil {
+		agg.logger.Error("Error sending aggregated response for batch", "batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]), "err", err)
 		agg.telemetry.LogTaskError(batchMerkleRoot, err)
+		agg.metrics.IncAggregatorRespondToTaskFailures(respondToTaskFailureReason(err))
+		agg.audit(AuditRecord{
+			Event:               AuditTxFailed,
+			BatchIdentifierHash: batchIdentifierHash,
+			BatchMerkleRoot:     batchMerkleRoot,
+			Reason:              respondToTaskFailureReason(err),
+			Error:               err.Error(),
+		})
 		return nil, err
 	}
 
+	agg.logger.Info("Aggregated response included", "batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]),
+		"txHash", receipt.TxHash.Hex(), "blockNumber", receipt.BlockNumber)
 	agg.metrics.IncAggregatedResponses()
+	confirmed := AuditRecord{
+		Event:               AuditTxConfirmed,
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchMerkleRoot,
+		TxHash:              receipt.TxHash.Hex(),
+		GasUsed:             receipt.GasUsed,
+	}
+	if receipt.EffectiveGasPrice != nil {
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+		agg.metrics.ObserveAggregatorRespondToTask(receipt.GasUsed, cost)
+		confirmed.Cost = cost.String()
+	}
+	agg.audit(confirmed)
+	go agg.updateWalletBalance()
 
 	return receipt, nil
 }
 
+// respondToTaskFailureReason is the label of the failure in the respond to task failures metric
+func respondToTaskFailureReason(err error) string {
+	switch {
+	case errors.Is(err, chainio.ErrSimulationFailed):
+		return "simulation_failed"
+	case errors.Is(err, chainio.ErrFeeLimitExceeded):
+		return "fee_limit_exceeded"
+	case errors.Is(err, chainio.ErrAggregatorBalanceLow):
+		return "aggregator_balance_low"
+	case errors.Is(err, chainio.ErrBatcherBalanceLow):
+		return "batcher_balance_low"
+	default:
+		return "send_failed"
+	}
+}
+
+// How often the aggregator wallet balance metric is refreshed, besides after each response
+const walletBalanceInterval = 1 * time.Minute
+
+// ReportWalletBalance is a long-lived goroutine that keeps the aggregator wallet balance metric up to date
+func (agg *Aggregator) ReportWalletBalance() {
+	ticker := time.NewTicker(walletBalanceInterval)
+	defer ticker.Stop()
+
+	agg.updateWalletBalance()
+	for range ticker.C {
+		agg.updateWalletBalance()
+	}
+}
+
+func (agg *Aggregator) updateWalletBalance() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	balance, err := agg.avsWriter.AggregatorBalance(ctx)
+	if err != nil {
+		agg.logger.Warn("Could not get aggregator wallet balance", "err", err)
+		return
+	}
+	agg.metrics.SetAggregatorWalletBalance(balance)
+}
+
 func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]byte, taskCreatedBlock uint32) {
 	agg.telemetry.InitNewTrace(batchMerkleRoot)
 	batchIdentifier := append(batchMerkleRoot[:], senderAddress[:]...)
@@ -337,6 +660,22 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 		"Sender Address", "0x"+hex.EncodeToString(senderAddress[:]),
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
 
+	agg.taskMutex.Lock()
+	_, known := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	agg.taskMutex.Unlock()
+	if known {
+		agg.logger.Warn("Batch already exists", "batchIdentifierHash", batchIdentifierHash)
+		return
+	}
+	// Stored before the task is visible, so it can't be responded and forgotten before being stored.
+	// Written without the lock, so signature handlers don't wait on disk
+	agg.persistTask(StoredTask{
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchMerkleRoot,
+		SenderAddress:       senderAddress,
+		TaskCreatedBlock:    taskCreatedBlock,
+	})
+
 	agg.taskMutex.Lock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Locked Resources: Adding new task")
 
@@ -364,6 +703,8 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 		BatchMerkleRoot: batchMerkleRoot,
 		SenderAddress:   senderAddress,
 	}
+	agg.signersByIdx[batchIndex] = make(map[eigentypes.OperatorId]time.Time)
+	agg.taskProgressByIdx[batchIndex] = &taskProgress{state: TaskStateCollecting, createdAt: time.Now()}
 	agg.logger.Info(
 		"Task Info added in aggregator:",
 		"Task", batchIndex,
@@ -371,65 +712,26 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 	)
 	agg.nextBatchIndex += 1
 
-	quorumNums := eigentypes.QuorumNums{eigentypes.QuorumNum(QUORUM_NUMBER)}
-	quorumThresholdPercentages := eigentypes.QuorumThresholdPercentages{eigentypes.QuorumThresholdPercentage(QUORUM_THRESHOLD)}
-
-	err := agg.blsAggregationService.InitializeNewTask(batchIndex, taskCreatedBlock, quorumNums, quorumThresholdPercentages, BLS_AGG_SERVICE_TIMEOUT)
+	err := agg.blsAggregationService.InitializeNewTask(batchIndex, taskCreatedBlock, agg.quorumNums, agg.quorumThresholdPercentages, BLS_AGG_SERVICE_TIMEOUT)
 	// FIXME(marian): When this errors, should we retry initializing new task? Logging fatal for now.
 	if err != nil {
 		agg.logger.Fatalf("BLS aggregation service error when initializing new task: %s", err)
 	}
 
 	agg.metrics.IncAggregatorReceivedTasks()
+	pendingResponses := agg.pendingResponses.Take(batchIdentifierHash, time.Now())
 	agg.taskMutex.Unlock()
 	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Adding new task")
-	agg.logger.Info("New task added", "batchIndex", batchIndex, "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
-}
-
-// Long-lived goroutine that periodically checks and removes old Tasks from stored Maps
-// It runs every GarbageCollectorPeriod and removes all tasks older than GarbageCollectorTasksAge
-// This was added because each task occupies memory in the maps, and we need to free it to avoid a memory leak
-func (agg *Aggregator) ClearTasksFromMaps() {
-	defer func() {
-		err := recover() //stops panics
-		if err != nil {
-			agg.logger.Error("Recovered from panic", "err", err)
-		}
-	}()
-
-	agg.AggregatorConfig.BaseConfig.Logger.Info(fmt.Sprintf("- Removing finalized Task Infos from Maps every %v", agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod))
-	lastIdxDeleted := uint32(0)
 
-	for {
-		time.Sleep(agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod)
-
-		agg.AggregatorConfig.BaseConfig.Logger.Info("Cleaning finalized tasks from maps")
-		oldTaskIdHash, err := agg.avsReader.GetOldTaskHash(agg.AggregatorConfig.Aggregator.GarbageCollectorTasksAge, agg.AggregatorConfig.Aggregator.GarbageCollectorTasksInterval)
-		if err != nil {
-			agg.logger.Error("Error getting old task hash, skipping this garbage collect", "err", err)
-			continue // Retry in the next iteration
-		}
-		if oldTaskIdHash == nil {
-			agg.logger.Warn("No old tasks found")
-			continue // Retry in the next iteration
-		}
-
-		taskIdxToDelete := agg.batchesIdxByIdentifierHash[*oldTaskIdHash]
-		agg.logger.Info("Old task found", "taskIndex", taskIdxToDelete)
-		// delete from lastIdxDeleted to taskIdxToDelete
-		for i := lastIdxDeleted + 1; i <= taskIdxToDelete; i++ {
-			batchIdentifierHash, exists := agg.batchesIdentifierHashByIdx[i]
-			if exists {
-				agg.logger.Info("Cleaning up finalized task", "taskIndex", i)
-				delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
-				delete(agg.batchCreatedBlockByIdx, i)
-				delete(agg.batchesIdentifierHashByIdx, i)
-				delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
-			} else {
-				agg.logger.Warn("Task not found in maps", "taskIndex", i)
-			}
-		}
-		lastIdxDeleted = taskIdxToDelete
-		agg.AggregatorConfig.BaseConfig.Logger.Info("Done cleaning finalized tasks from maps")
-	}
+	agg.logger.Info("New task added", "batchIndex", batchIndex, "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+		"pendingResponses", len(pendingResponses))
+	agg.audit(AuditRecord{
+		Event:               AuditTaskAdded,
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchMerkleRoot,
+		TaskIndex:           &batchIndex,
+		TaskCreatedBlock:    taskCreatedBlock,
+	})
+
+	agg.replayPendingResponses(pendingResponses)
 }
@@ -0,0 +1,315 @@
+package pkg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	opstateretriever "github.com/Layr-Labs/eigensdk-go/contracts/bindings/OperatorStateRetriever"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// fakeBlsAggregationService accepts every signature of an initialized task. Tests complete
+// tasks by sending on responses.
+type fakeBlsAggregationService struct {
+	mutex      sync.Mutex
+	tasks      map[uint32]bool
+	signatures map[uint32][]eigentypes.OperatorId
+	// Returned by ProcessNewSignature if set
+	err       error
+	responses chan blsagg.BlsAggregationServiceResponse
+}
+
+func newFakeBlsAggregationService() *fakeBlsAggregationService {
+	return &fakeBlsAggregationService{
+		tasks:      make(map[uint32]bool),
+		signatures: make(map[uint32][]eigentypes.OperatorId),
+		responses:  make(chan blsagg.BlsAggregationServiceResponse, 16),
+	}
+}
+
+func (s *fakeBlsAggregationService) InitializeNewTask(taskIndex eigentypes.TaskIndex, taskCreatedBlock uint32, quorumNumbers eigentypes.QuorumNums, quorumThresholdPercentages eigentypes.QuorumThresholdPercentages, timeToExpiry time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tasks[taskIndex] = true
+	return nil
+}
+
+func (s *fakeBlsAggregationService) ProcessNewSignature(ctx context.Context, taskIndex eigentypes.TaskIndex, taskResponse eigentypes.TaskResponse, blsSignature *bls.Signature, operatorId eigentypes.OperatorId) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if !s.tasks[taskIndex] {
+		return blsagg.TaskNotFoundErrorFn(taskIndex)
+	}
+	s.signatures[taskIndex] = append(s.signatures[taskIndex], operatorId)
+	return nil
+}
+
+func (s *fakeBlsAggregationService) GetResponseChannel() <-chan blsagg.BlsAggregationServiceResponse {
+	return s.responses
+}
+
+func (s *fakeBlsAggregationService) Signatures(taskIndex uint32) []eigentypes.OperatorId {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]eigentypes.OperatorId(nil), s.signatures[taskIndex]...)
+}
+
+// fakeAvsRegistryService returns the registered operators at every block
+type fakeAvsRegistryService struct {
+	mutex     sync.Mutex
+	operators map[eigentypes.OperatorId]eigentypes.OperatorAvsState
+}
+
+func (s *fakeAvsRegistryService) GetOperatorsAvsStateAtBlock(ctx context.Context, quorumNumbers eigentypes.QuorumNums, blockNumber eigentypes.BlockNum) (map[eigentypes.OperatorId]eigentypes.OperatorAvsState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	operators := make(map[eigentypes.OperatorId]eigentypes.OperatorAvsState, len(s.operators))
+	for operatorId, operator := range s.operators {
+		operators[operatorId] = operator
+	}
+	return operators, nil
+}
+
+func (s *fakeAvsRegistryService) GetQuorumsAvsStateAtBlock(ctx context.Context, quorumNumbers eigentypes.QuorumNums, blockNumber eigentypes.BlockNum) (map[eigentypes.QuorumNum]eigentypes.QuorumAvsState, error) {
+	return map[eigentypes.QuorumNum]eigentypes.QuorumAvsState{}, nil
+}
+
+func (s *fakeAvsRegistryService) GetCheckSignaturesIndices(opts *bind.CallOpts, referenceBlockNumber eigentypes.BlockNum, quorumNumbers eigentypes.QuorumNums, nonSignerOperatorIds []eigentypes.OperatorId) (opstateretriever.OperatorStateRetrieverCheckSignaturesIndices, error) {
+	return opstateretriever.OperatorStateRetrieverCheckSignaturesIndices{}, nil
+}
+
+// testOperator is an operator registered in the fake registry and the fake reader
+type testOperator struct {
+	id         eigentypes.OperatorId
+	blsKeyPair *bls.KeyPair
+	ecdsaKey   *ecdsa.PrivateKey
+}
+
+// testAggregator is an aggregator backed by the chainio fakes and a fake BLS aggregation service
+type testAggregator struct {
+	*Aggregator
+	reader     *fakes.FakeAvsReader
+	writer     *fakes.FakeAvsWriter
+	subscriber *fakes.FakeAvsSubscriber
+	bls        *fakeBlsAggregationService
+	registry   *fakeAvsRegistryService
+}
+
+func newTestAggregator(t *testing.T) *testAggregator {
+	t.Helper()
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+
+	reader := fakes.NewFakeAvsReader()
+	writer := fakes.NewFakeAvsWriter(reader)
+	subscriber := fakes.NewFakeAvsSubscriber(reader)
+	blsService := newFakeBlsAggregationService()
+	registry := &fakeAvsRegistryService{operators: make(map[eigentypes.OperatorId]eigentypes.OperatorAvsState)}
+	aggregatorMetrics := metrics.NewMetrics("", prometheus.NewRegistry(), logger)
+
+	aggregatorConfig := &config.AggregatorConfig{BaseConfig: &config.BaseConfig{Logger: logger}}
+
+	agg := &Aggregator{
+		AggregatorConfig: aggregatorConfig,
+		avsReader:        reader,
+		avsSubscriber:    subscriber,
+		avsWriter:        writer,
+
+		batchesIdentifierHashByIdx: make(map[uint32][32]byte),
+		batchesIdxByIdentifierHash: make(map[[32]byte]uint32),
+		batchDataByIdentifierHash:  make(map[[32]byte]BatchData),
+		batchCreatedBlockByIdx:     make(map[uint32]uint64),
+		signersByIdx:               make(map[uint32]map[eigentypes.OperatorId]time.Time),
+		operatorsAvsStateByIdx:     make(map[uint32]map[eigentypes.OperatorId]eigentypes.OperatorAvsState),
+		taskProgressByIdx:          make(map[uint32]*taskProgress),
+		postQuorumSignaturesByIdx:  make(map[uint32]chan postQuorumSignature),
+		operatorScoreboard:         NewOperatorScoreboard(),
+		pendingResponses:           NewPendingResponses(time.Minute, 100),
+		submissionPool:             newSubmissionPool(4, 16),
+		operatorAddressById:        make(map[eigentypes.OperatorId]common.Address),
+		taskMutex:                  &sync.Mutex{},
+		leaderLease:                NewLeaderLease(alwaysLeaderLock{}, logger, nil),
+		quorumNums:                 eigentypes.QuorumNums{0},
+		quorumThresholdPercentages: eigentypes.QuorumThresholdPercentages{67},
+
+		blsAggregationService: newBlsAggregator(blsService),
+		avsRegistryService:    registry,
+		logger:                logger,
+		metricsReg:            prometheus.NewRegistry(),
+		metrics:               aggregatorMetrics,
+		telemetry:             NewTelemetry(TelemetryConfig{}, aggregatorMetrics, logger),
+	}
+	agg.leaderLease.Renew()
+
+	return &testAggregator{
+		Aggregator: agg,
+		reader:     reader,
+		writer:     writer,
+		subscriber: subscriber,
+		bls:        blsService,
+		registry:   registry,
+	}
+}
+
+// newTestOperator registers a new operator in the registry and the reader
+func (a *testAggregator) newTestOperator(t *testing.T) *testOperator {
+	t.Helper()
+	blsKeyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("Could not generate BLS keys: %v", err)
+	}
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Could not generate ECDSA key: %v", err)
+	}
+	operatorId := eigentypes.OperatorId(crypto.Keccak256Hash(blsKeyPair.GetPubKeyG1().Serialize()))
+
+	a.registry.mutex.Lock()
+	a.registry.operators[operatorId] = eigentypes.OperatorAvsState{
+		OperatorId: operatorId,
+		OperatorInfo: eigentypes.OperatorInfo{Pubkeys: eigentypes.OperatorPubkeys{
+			G1Pubkey: blsKeyPair.GetPubKeyG1(),
+			G2Pubkey: blsKeyPair.GetPubKeyG2(),
+		}},
+		StakePerQuorum: map[eigentypes.QuorumNum]eigentypes.StakeAmount{},
+	}
+	a.registry.mutex.Unlock()
+	a.reader.RegisterOperator(operatorId, crypto.PubkeyToAddress(privateKey.PublicKey))
+
+	return &testOperator{id: operatorId, blsKeyPair: blsKeyPair, ecdsaKey: privateKey}
+}
+
+// sign returns the operator response for the batch
+func (o *testOperator) sign(batchMerkleRoot [32]byte, senderAddress [20]byte) *types.SignedTaskResponse {
+	batchIdentifierHash := fakes.BatchIdentifierHash(batchMerkleRoot, senderAddress)
+	return &types.SignedTaskResponse{
+		BatchMerkleRoot:     batchMerkleRoot,
+		SenderAddress:       senderAddress,
+		BatchIdentifierHash: batchIdentifierHash,
+		BlsSignature:        *o.blsKeyPair.SignMessage(batchIdentifierHash),
+		OperatorId:          o.id,
+	}
+}
+
+// taskProgress returns the state of a tracked task and the hash of the transaction that responded it
+func (a *testAggregator) taskProgress(t *testing.T, taskIndex uint32) (TaskState, *common.Hash) {
+	t.Helper()
+	a.taskMutex.Lock()
+	defer a.taskMutex.Unlock()
+	progress, ok := a.taskProgressByIdx[taskIndex]
+	if !ok {
+		t.Fatalf("Task %d is not tracked", taskIndex)
+	}
+	return progress.state, progress.txHash
+}
+
+// addTestTask adds a task, and returns its batch identifier hash and data
+func (a *testAggregator) addTestTask(batchMerkleRoot [32]byte, senderAddress [20]byte, taskCreatedBlock uint32) ([32]byte, BatchData) {
+	a.AddNewTask(batchMerkleRoot, senderAddress, taskCreatedBlock)
+	batchIdentifierHash := fakes.BatchIdentifierHash(batchMerkleRoot, senderAddress)
+	a.taskMutex.Lock()
+	defer a.taskMutex.Unlock()
+	return batchIdentifierHash, a.batchDataByIdentifierHash[batchIdentifierHash]
+}
+
+// useTaskStores gives the aggregator a task store and a dead letter store in temporary directories
+func (a *testAggregator) useTaskStores(t *testing.T) {
+	t.Helper()
+	taskStore, err := NewTaskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = taskStore.Close() })
+	deadLetterStore, err := NewDeadLetterStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	a.taskStore = taskStore
+	a.deadLetterStore = deadLetterStore
+}
+
+func TestRespondTask(t *testing.T) {
+	agg := newTestAggregator(t)
+	batchIdentifierHash, batchData := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+
+	agg.respondTask(0, batchIdentifierHash, batchData, 10, servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{})
+
+	responses := agg.writer.Responses()
+	if len(responses) != 1 || responses[0].BatchIdentifierHash != batchIdentifierHash {
+		t.Fatalf("Expected the batch to be responded once, got %v", responses)
+	}
+	state, txHash := agg.taskProgress(t, 0)
+	if state != TaskStateConfirmed || txHash == nil || *txHash != responses[0].TxHash {
+		t.Fatalf("Expected the task to be confirmed by %s, got %s with %v", responses[0].TxHash.Hex(), state, txHash)
+	}
+}
+
+func TestRespondTaskAlreadyResponded(t *testing.T) {
+	agg := newTestAggregator(t)
+	agg.useTaskStores(t)
+	batchIdentifierHash, batchData := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	// Another instance responded the batch
+	agg.reader.SetBatchResponded(batchIdentifierHash, true)
+
+	agg.respondTask(0, batchIdentifierHash, batchData, 10, servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{})
+
+	if responses := agg.writer.Responses(); len(responses) != 0 {
+		t.Fatalf("Expected no response to be sent, got %v", responses)
+	}
+	if state, txHash := agg.taskProgress(t, 0); state != TaskStateConfirmed || txHash != nil {
+		t.Fatalf("Expected the task to be confirmed without a transaction, got %s with %v", state, txHash)
+	}
+	if tasks, err := agg.taskStore.Tasks(); err != nil || len(tasks) != 0 {
+		t.Fatalf("Expected the task to be forgotten, got %v, %v", tasks, err)
+	}
+}
+
+func TestRespondTaskRetriesToDeadLetter(t *testing.T) {
+	retryDelay := sentTxRetryDelay
+	sentTxRetryDelay = 0
+	t.Cleanup(func() { sentTxRetryDelay = retryDelay })
+
+	agg := newTestAggregator(t)
+	agg.useTaskStores(t)
+	batchIdentifierHash, batchData := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	sendErr := errors.New("connection refused")
+	agg.writer.SetSendError(sendErr)
+
+	agg.respondTask(0, batchIdentifierHash, batchData, 10, servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{})
+
+	if state, _ := agg.taskProgress(t, 0); state != TaskStateFailed {
+		t.Fatalf("Expected the task to fail, got %s", state)
+	}
+	deadLetter, err := agg.deadLetterStore.Get(batchIdentifierHash)
+	if err != nil {
+		t.Fatalf("Expected a dead letter for the batch: %v", err)
+	}
+	if deadLetter.Attempts != MaxSentTxRetries || deadLetter.LastError != sendErr.Error() || deadLetter.TaskCreatedBlock != 10 {
+		t.Fatalf("Expected a dead letter after %d attempts failing with %q, got %+v", MaxSentTxRetries, sendErr, deadLetter)
+	}
+	// The dead letter store retries it from now on
+	if tasks, err := agg.taskStore.Tasks(); err != nil || len(tasks) != 0 {
+		t.Fatalf("Expected the task to be forgotten, got %v, %v", tasks, err)
+	}
+}
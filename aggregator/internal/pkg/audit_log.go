@@ -0,0 +1,280 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Events recorded in the audit log
+const (
+	AuditTaskAdded         = "task_added"
+	AuditSignatureAccepted = "signature_accepted"
+	AuditSignatureRejected = "signature_rejected"
+	AuditQuorumReached     = "quorum_reached"
+	AuditCostSimulated     = "cost_simulated"
+	AuditTxSent            = "tx_sent"
+	AuditTxConfirmed       = "tx_confirmed"
+	AuditTxFailed          = "tx_failed"
+)
+
+// Audit log lines are small, but a quorum record lists every signer
+const auditLogMaxLineSize = 1024 * 1024
+
+// AuditRecord is a line of the audit log. Only the fields of its event are set.
+// Hash is the keccak256 of the record encoded with an empty hash, and PrevHash is the hash of the previous
+// record, so changing, removing or reordering records breaks the chain.
+type AuditRecord struct {
+	Sequence            uint64      `json:"seq"`
+	Time                time.Time   `json:"time"`
+	Event               string      `json:"event"`
+	BatchIdentifierHash common.Hash `json:"batch_identifier_hash"`
+	BatchMerkleRoot     common.Hash `json:"batch_merkle_root"`
+	TaskIndex           *uint32     `json:"task_index,omitempty"`
+	TaskCreatedBlock    uint32      `json:"task_created_block,omitempty"`
+	OperatorId          string      `json:"operator_id,omitempty"`
+	// Reply to a signature, or failure reason of a transaction
+	Reason     string   `json:"reason,omitempty"`
+	Signers    []string `json:"signers,omitempty"`
+	NonSigners int      `json:"non_signers,omitempty"`
+	GasLimit   uint64   `json:"gas_limit,omitempty"`
+	GasUsed    uint64   `json:"gas_used,omitempty"`
+	// In wei, as decimal strings
+	Cost                  string      `json:"cost,omitempty"`
+	RespondToTaskFeeLimit string      `json:"respond_to_task_fee_limit,omitempty"`
+	TxHash                string      `json:"tx_hash,omitempty"`
+	Nonce                 *uint64     `json:"nonce,omitempty"`
+	Error                 string      `json:"error,omitempty"`
+	PrevHash              common.Hash `json:"prev_hash"`
+	Hash                  common.Hash `json:"hash"`
+}
+
+// computeHash returns the hash of the record, ignoring its Hash field
+func (r AuditRecord) computeHash() (common.Hash, error) {
+	r.Hash = common.Hash{}
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// AuditLog is an append-only JSONL file with a hash-chained record per aggregator decision, for incident reviews.
+// Records are numbered from 1, and the numbering and the chain continue across restarts.
+type AuditLog struct {
+	mutex    sync.Mutex
+	file     *os.File
+	sequence uint64
+	lastHash common.Hash
+}
+
+func NewAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	auditLog := &AuditLog{file: file}
+	end, err := auditLog.loadLastRecord()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// A crash may leave a partial last line, it is dropped so the next record starts on its own line
+	if err := file.Truncate(end); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate audit log: %w", err)
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// loadLastRecord continues the sequence and the chain from the last complete record,
+// and returns the offset where it ends
+func (l *AuditLog) loadLastRecord() (int64, error) {
+	reader := bufio.NewReaderSize(l.file, 64*1024)
+	var end int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return end, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &record); err != nil {
+			return 0, fmt.Errorf("invalid audit log record after sequence %d: %w", l.sequence, err)
+		}
+		l.sequence = record.Sequence
+		l.lastHash = record.Hash
+		end += int64(len(line))
+	}
+}
+
+// Append numbers, chains and writes the record
+func (l *AuditLog) Append(record AuditRecord) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	record.Sequence = l.sequence + 1
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	record.PrevHash = l.lastHash
+	hash, err := record.computeHash()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	l.sequence = record.Sequence
+	l.lastHash = record.Hash
+	return nil
+}
+
+func (l *AuditLog) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.file.Close()
+}
+
+// ReadAuditLog calls visit with every record of the log in order. It fails on the first record that
+// breaks the sequence or the hash chain, after visiting the records before it.
+func ReadAuditLog(path string, visit func(record AuditRecord) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), auditLogMaxLineSize)
+	var previous AuditRecord
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("invalid audit log record after sequence %d: %w", previous.Sequence, err)
+		}
+		if err := verifyAuditRecord(record, previous); err != nil {
+			return err
+		}
+		if err := visit(record); err != nil {
+			return err
+		}
+		previous = record
+	}
+	return scanner.Err()
+}
+
+var ErrAuditLogTampered = errors.New("audit log chain is broken")
+
+func verifyAuditRecord(record AuditRecord, previous AuditRecord) error {
+	if record.Sequence != previous.Sequence+1 {
+		return fmt.Errorf("%w: record %d follows record %d", ErrAuditLogTampered, record.Sequence, previous.Sequence)
+	}
+	if record.PrevHash != previous.Hash {
+		return fmt.Errorf("%w: record %d does not chain to the previous record", ErrAuditLogTampered, record.Sequence)
+	}
+	hash, err := record.computeHash()
+	if err != nil {
+		return err
+	}
+	if hash != record.Hash {
+		return fmt.Errorf("%w: record %d was modified", ErrAuditLogTampered, record.Sequence)
+	}
+	return nil
+}
+
+// audit appends the record to the audit log, if it is enabled. Failed writes are logged, and never stop the aggregator
+func (agg *Aggregator) audit(record AuditRecord) {
+	if agg.auditLog == nil {
+		return
+	}
+	if err := agg.auditLog.Append(record); err != nil {
+		agg.logger.Error("Could not write audit log record", "event", record.Event,
+			"batchIdentifierHash", "0x"+hex.EncodeToString(record.BatchIdentifierHash[:]), "err", err)
+	}
+}
+
+// auditSignedTaskResponse records the reply to an operator response, with the reply as the reason
+func (agg *Aggregator) auditSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse, reply uint8) {
+	event := AuditSignatureRejected
+	if types.SignedTaskResponseReplyIsAccepted(reply) {
+		event = AuditSignatureAccepted
+	}
+	agg.audit(AuditRecord{
+		Event:               event,
+		BatchIdentifierHash: signedTaskResponse.BatchIdentifierHash,
+		BatchMerkleRoot:     signedTaskResponse.BatchMerkleRoot,
+		OperatorId:          "0x" + hex.EncodeToString(signedTaskResponse.OperatorId[:]),
+		Reason:              types.SignedTaskResponseReplyString(reply),
+	})
+}
+
+// auditQuorumReached records the operators that signed the task when it reached quorum
+func (agg *Aggregator) auditQuorumReached(taskIndex uint32, batchIdentifierHash [32]byte, batchData BatchData, nonSigners int) {
+	if agg.auditLog == nil {
+		return
+	}
+	agg.taskMutex.Lock()
+	signers := make([]string, 0, len(agg.signersByIdx[taskIndex]))
+	for operatorId := range agg.signersByIdx[taskIndex] {
+		signers = append(signers, "0x"+hex.EncodeToString(operatorId[:]))
+	}
+	agg.taskMutex.Unlock()
+	sort.Strings(signers)
+
+	agg.audit(AuditRecord{
+		Event:               AuditQuorumReached,
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchData.BatchMerkleRoot,
+		TaskIndex:           &taskIndex,
+		Signers:             signers,
+		NonSigners:          nonSigners,
+	})
+}
+
+// auditCostSimulated records the simulation of a response, and why it won't be sent if a check failed
+func (agg *Aggregator) auditCostSimulated(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, simulation *chainio.AggregatedResponseSimulation, simulationErr error) {
+	record := AuditRecord{
+		Event:               AuditCostSimulated,
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchMerkleRoot,
+	}
+	if simulation != nil {
+		record.GasLimit = simulation.GasLimit
+		record.Cost = simulation.Cost.String()
+		if simulation.RespondToTaskFeeLimit != nil {
+			record.RespondToTaskFeeLimit = simulation.RespondToTaskFeeLimit.String()
+		}
+	}
+	if simulationErr != nil {
+		record.Reason = respondToTaskFailureReason(simulationErr)
+		record.Error = simulationErr.Error()
+	}
+	agg.audit(record)
+}
@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestAuditLog(t *testing.T, path string) *AuditLog {
+	t.Helper()
+	auditLog, err := NewAuditLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return auditLog
+}
+
+// writeTestAuditLog writes a log with a task_added record per batch
+func writeTestAuditLog(t *testing.T, batches ...common.Hash) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog := newTestAuditLog(t, path)
+	defer auditLog.Close()
+	for _, batch := range batches {
+		if err := auditLog.Append(AuditRecord{Event: AuditTaskAdded, BatchIdentifierHash: batch}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return path
+}
+
+func readTestAuditLog(path string) ([]AuditRecord, error) {
+	var records []AuditRecord
+	err := ReadAuditLog(path, func(record AuditRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+func readAuditLogLines(t *testing.T, path string) [][]byte {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return bytes.SplitAfter(content, []byte("\n"))
+}
+
+func writeAuditLogLines(t *testing.T, path string, lines [][]byte) {
+	t.Helper()
+	if err := os.WriteFile(path, bytes.Join(lines, nil), 0644); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestAuditLogContinuesAfterReopen(t *testing.T) {
+	path := writeTestAuditLog(t, common.Hash{1}, common.Hash{2})
+
+	auditLog := newTestAuditLog(t, path)
+	if err := auditLog.Append(AuditRecord{Event: AuditTaskAdded, BatchIdentifierHash: common.Hash{3}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	auditLog.Close()
+
+	records, err := readTestAuditLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	for i, record := range records {
+		if record.Sequence != uint64(i+1) {
+			t.Fatalf("Expected record %d to have sequence %d, got %d", i, i+1, record.Sequence)
+		}
+	}
+	if records[2].PrevHash != records[1].Hash {
+		t.Fatalf("Expected the record after the reopen to chain to the last record")
+	}
+}
+
+func TestAuditLogDropsTornLastLineOnReopen(t *testing.T) {
+	path := writeTestAuditLog(t, common.Hash{1}, common.Hash{2})
+
+	// A crash in the middle of the write of the second record
+	lines := readAuditLogLines(t, path)
+	lines[1] = lines[1][:len(lines[1])/2]
+	writeAuditLogLines(t, path, lines)
+
+	auditLog := newTestAuditLog(t, path)
+	if err := auditLog.Append(AuditRecord{Event: AuditTaskAdded, BatchIdentifierHash: common.Hash{3}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	auditLog.Close()
+
+	records, err := readTestAuditLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[1].Sequence != 2 || records[1].BatchIdentifierHash != (common.Hash{3}) {
+		t.Fatalf("Expected the torn record to be replaced by the new one, got %+v", records)
+	}
+}
+
+func TestReadAuditLogDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines [][]byte) [][]byte
+	}{
+		{
+			name: "edited record",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte(AuditTaskAdded), []byte(AuditTxFailed), 1)
+				return lines
+			},
+		},
+		{
+			name: "deleted record",
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+		},
+		{
+			name: "swapped records",
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTestAuditLog(t, common.Hash{1}, common.Hash{2}, common.Hash{3})
+			writeAuditLogLines(t, path, tt.tamper(readAuditLogLines(t, path)))
+
+			records, err := readTestAuditLog(path)
+			if !errors.Is(err, ErrAuditLogTampered) {
+				t.Fatalf("Expected %v, got %v", ErrAuditLogTampered, err)
+			}
+			// The records before the tampered one are still visited
+			if len(records) != 1 {
+				t.Fatalf("Expected 1 valid record, got %d", len(records))
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// Max blocks queried for NewBatchV3 logs at once, RPC providers limit the range of eth_getLogs
+const backfillMaxBlockRange = 2000
+
+const backfillBlockNumberTimeout = 10 * time.Second
+
+// BackfillCheckpoint is the last block scanned by the backfill, stored in `backfill_checkpoint_path`
+type BackfillCheckpoint struct {
+	LastScannedBlock uint64 `json:"last_scanned_block"`
+}
+
+// BackfillTasks is a long-lived goroutine that initializes the unresponded batches the task subscription
+// missed, like the ones created while the aggregator was down or during a websocket gap.
+// It scans the NewBatchV3 logs from the last checkpoint on startup, and then every `backfill_interval`.
+// Batches older than `backfill_window_blocks` are not aggregated anymore, so they are skipped.
+func (agg *Aggregator) BackfillTasks() {
+	checkpointPath := agg.AggregatorConfig.Aggregator.BackfillCheckpointPath
+	lastScannedBlock := uint64(0)
+	if checkpointPath != "" {
+		checkpoint, err := readBackfillCheckpoint(checkpointPath)
+		if err != nil {
+			agg.logger.Error("Could not read backfill checkpoint, scanning the whole window", "path", checkpointPath, "err", err)
+		} else {
+			lastScannedBlock = checkpoint.LastScannedBlock
+		}
+	}
+
+	for {
+		lastScannedBlock = agg.backfillTasks(lastScannedBlock)
+		time.Sleep(agg.AggregatorConfig.Aggregator.BackfillInterval)
+	}
+}
+
+// backfillTasks scans the blocks after lastScannedBlock, and returns the last block it scanned
+func (agg *Aggregator) backfillTasks(lastScannedBlock uint64) uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), backfillBlockNumberTimeout)
+	latestBlock, err := agg.avsReader.BlockNumber(ctx)
+	cancel()
+	if err != nil {
+		agg.logger.Error("Could not get latest block, skipping backfill", "err", err)
+		return lastScannedBlock
+	}
+
+	fromBlock := uint64(0)
+	if latestBlock > agg.AggregatorConfig.Aggregator.BackfillWindowBlocks {
+		fromBlock = latestBlock - agg.AggregatorConfig.Aggregator.BackfillWindowBlocks
+	}
+	if lastScannedBlock >= fromBlock {
+		fromBlock = lastScannedBlock + 1
+	}
+
+	backfilled := 0
+	for start := fromBlock; start <= latestBlock; start += backfillMaxBlockRange {
+		end := min(start+backfillMaxBlockRange-1, latestBlock)
+		tasks, err := agg.avsReader.GetNotRespondedTasksBetween(start, end)
+		if err != nil {
+			// The checkpoint stays at the last range scanned, so the rest is retried on the next run
+			agg.logger.Error("Could not get unresponded tasks, backfill will resume on the next run",
+				"fromBlock", start, "toBlock", end, "err", err)
+			break
+		}
+
+		for _, task := range tasks {
+			batchIdentifier := append(task.BatchMerkleRoot[:], task.SenderAddress[:]...)
+			batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+			if agg.isTaskTracked(batchIdentifierHash) {
+				continue
+			}
+			agg.logger.Info("Backfilling task missed by the subscription",
+				"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+				"taskCreatedBlock", task.TaskCreatedBlock)
+			agg.AddNewTask(task.BatchMerkleRoot, task.SenderAddress, task.TaskCreatedBlock)
+			backfilled++
+		}
+
+		lastScannedBlock = end
+		agg.persistBackfillCheckpoint(lastScannedBlock)
+	}
+
+	if backfilled > 0 {
+		agg.metrics.AddAggregatorBackfilledTasks(backfilled)
+	}
+	agg.logger.Debug("Backfill done", "lastScannedBlock", lastScannedBlock, "backfilledTasks", backfilled)
+	return lastScannedBlock
+}
+
+func (agg *Aggregator) isTaskTracked(batchIdentifierHash [32]byte) bool {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	_, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	return ok
+}
+
+func (agg *Aggregator) persistBackfillCheckpoint(lastScannedBlock uint64) {
+	checkpointPath := agg.AggregatorConfig.Aggregator.BackfillCheckpointPath
+	if checkpointPath == "" {
+		return
+	}
+	if err := writeBackfillCheckpoint(checkpointPath, BackfillCheckpoint{LastScannedBlock: lastScannedBlock}); err != nil {
+		agg.logger.Error("Could not write backfill checkpoint", "path", checkpointPath, "err", err)
+	}
+}
+
+// readBackfillCheckpoint returns an empty checkpoint if the file does not exist yet
+func readBackfillCheckpoint(path string) (BackfillCheckpoint, error) {
+	var checkpoint BackfillCheckpoint
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(file, &checkpoint)
+	return checkpoint, err
+}
+
+func writeBackfillCheckpoint(path string, checkpoint BackfillCheckpoint) error {
+	file, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, file, 0644)
+}
@@ -0,0 +1,170 @@
+package pkg
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+)
+
+// rangeRecordingAvsReader records the ranges queried for unresponded tasks, and fails the one starting at failFrom
+type rangeRecordingAvsReader struct {
+	*fakes.FakeAvsReader
+	mutex    sync.Mutex
+	ranges   [][2]uint64
+	failFrom uint64
+}
+
+func (r *rangeRecordingAvsReader) GetNotRespondedTasksBetween(fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	r.mutex.Lock()
+	r.ranges = append(r.ranges, [2]uint64{fromBlock, toBlock})
+	failing := r.failFrom != 0 && fromBlock == r.failFrom
+	r.mutex.Unlock()
+	if failing {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	return r.FakeAvsReader.GetNotRespondedTasksBetween(fromBlock, toBlock)
+}
+
+func (r *rangeRecordingAvsReader) queriedRanges() [][2]uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	ranges := r.ranges
+	r.ranges = nil
+	return ranges
+}
+
+// newBackfillTestAggregator returns an aggregator at latestBlock, with a task created at each of the blocks
+func newBackfillTestAggregator(t *testing.T, windowBlocks uint64, latestBlock uint64, taskBlocks ...uint64) (*testAggregator, *rangeRecordingAvsReader) {
+	t.Helper()
+	agg := newTestAggregator(t)
+	agg.AggregatorConfig.Aggregator.BackfillWindowBlocks = windowBlocks
+	agg.AggregatorConfig.Aggregator.BackfillCheckpointPath = filepath.Join(t.TempDir(), "backfill.json")
+	for i, block := range taskBlocks {
+		agg.reader.AddTask(servicemanager.ContractAlignedLayerServiceManagerNewBatchV3{
+			BatchMerkleRoot:  [32]byte{byte(i + 1)},
+			SenderAddress:    [20]byte{1},
+			TaskCreatedBlock: uint32(block),
+			Raw:              ethtypes.Log{BlockNumber: block},
+		})
+	}
+	agg.reader.SetBlockNumber(latestBlock)
+	reader := &rangeRecordingAvsReader{FakeAvsReader: agg.reader}
+	agg.avsReader = reader
+	return agg, reader
+}
+
+// isBackfilled returns true if the task created at the i-th block given to newBackfillTestAggregator is tracked
+func (a *testAggregator) isBackfilled(i int) bool {
+	return a.isTaskTracked(fakes.BatchIdentifierHash([32]byte{byte(i + 1)}, [20]byte{1}))
+}
+
+func (a *testAggregator) backfillCheckpoint(t *testing.T) uint64 {
+	t.Helper()
+	checkpoint, err := readBackfillCheckpoint(a.AggregatorConfig.Aggregator.BackfillCheckpointPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return checkpoint.LastScannedBlock
+}
+
+func TestBackfillTasksScansOnlyTheWindow(t *testing.T) {
+	agg, reader := newBackfillTestAggregator(t, 300, 5000, 4000, 4800)
+
+	if lastScannedBlock := agg.backfillTasks(0); lastScannedBlock != 5000 {
+		t.Fatalf("Expected the scan to reach block 5000, got %d", lastScannedBlock)
+	}
+	if ranges := reader.queriedRanges(); len(ranges) != 1 || ranges[0] != [2]uint64{4700, 5000} {
+		t.Fatalf("Expected the scan to start at the window, got %v", ranges)
+	}
+	if agg.isBackfilled(0) || !agg.isBackfilled(1) {
+		t.Fatalf("Expected only the task in the window to be backfilled")
+	}
+	if checkpoint := agg.backfillCheckpoint(t); checkpoint != 5000 {
+		t.Fatalf("Expected the checkpoint at block 5000, got %d", checkpoint)
+	}
+}
+
+func TestBackfillTasksResumesAfterLastScannedBlock(t *testing.T) {
+	agg, reader := newBackfillTestAggregator(t, 300, 5000, 4900, 4950)
+
+	if lastScannedBlock := agg.backfillTasks(4900); lastScannedBlock != 5000 {
+		t.Fatalf("Expected the scan to reach block 5000, got %d", lastScannedBlock)
+	}
+	if ranges := reader.queriedRanges(); len(ranges) != 1 || ranges[0] != [2]uint64{4901, 5000} {
+		t.Fatalf("Expected the scan to resume after the last scanned block, got %v", ranges)
+	}
+	if agg.isBackfilled(0) || !agg.isBackfilled(1) {
+		t.Fatalf("Expected only the task after the last scanned block to be backfilled")
+	}
+}
+
+func TestBackfillTasksQueriesInChunks(t *testing.T) {
+	agg, reader := newBackfillTestAggregator(t, 5000, 5000, 100, 2500, 4500)
+
+	agg.backfillTasks(0)
+
+	expected := [][2]uint64{{1, 2000}, {2001, 4000}, {4001, 5000}}
+	ranges := reader.queriedRanges()
+	if len(ranges) != len(expected) {
+		t.Fatalf("Expected ranges %v, got %v", expected, ranges)
+	}
+	for i := range expected {
+		if ranges[i] != expected[i] {
+			t.Fatalf("Expected ranges %v, got %v", expected, ranges)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !agg.isBackfilled(i) {
+			t.Fatalf("Expected task %d to be backfilled", i)
+		}
+	}
+}
+
+func TestBackfillTasksStopsAtFailedRange(t *testing.T) {
+	agg, reader := newBackfillTestAggregator(t, 5000, 5000, 100, 2500, 4500)
+	reader.failFrom = 2001
+
+	if lastScannedBlock := agg.backfillTasks(0); lastScannedBlock != 2000 {
+		t.Fatalf("Expected the scan to stop at block 2000, got %d", lastScannedBlock)
+	}
+	if ranges := reader.queriedRanges(); len(ranges) != 2 {
+		t.Fatalf("Expected no range to be queried after the failed one, got %v", ranges)
+	}
+	if checkpoint := agg.backfillCheckpoint(t); checkpoint != 2000 {
+		t.Fatalf("Expected the checkpoint to stay at block 2000, got %d", checkpoint)
+	}
+	if !agg.isBackfilled(0) || agg.isBackfilled(1) || agg.isBackfilled(2) {
+		t.Fatalf("Expected only the task before the failed range to be backfilled")
+	}
+
+	// The next run retries from the failed range
+	reader.failFrom = 0
+	if lastScannedBlock := agg.backfillTasks(2000); lastScannedBlock != 5000 {
+		t.Fatalf("Expected the scan to reach block 5000, got %d", lastScannedBlock)
+	}
+	if ranges := reader.queriedRanges(); ranges[0] != [2]uint64{2001, 4000} {
+		t.Fatalf("Expected the scan to resume at the failed range, got %v", ranges)
+	}
+	if !agg.isBackfilled(1) || !agg.isBackfilled(2) {
+		t.Fatalf("Expected the remaining tasks to be backfilled")
+	}
+}
+
+func TestBackfillTasksSkipsTrackedTasks(t *testing.T) {
+	agg, _ := newBackfillTestAggregator(t, 300, 5000, 4800)
+	// Received by the subscription
+	agg.AddNewTask([32]byte{1}, [20]byte{1}, 4800)
+
+	agg.backfillTasks(0)
+
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	if len(agg.batchesIdxByIdentifierHash) != 1 || agg.nextBatchIndex != 1 {
+		t.Fatalf("Expected the tracked task not to be added again, got %d tasks", agg.nextBatchIndex)
+	}
+}
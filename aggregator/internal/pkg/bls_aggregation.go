@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+var (
+	// The task was never initialized, or the BLS aggregation service already finished it
+	ErrBlsTaskNotFound = errors.New("task not initialized or already completed")
+	// The operator signature was already aggregated for the task
+	ErrBlsDuplicateSignature = errors.New("duplicate signature")
+)
+
+// blsAggregator wraps the BLS aggregation service, keeping the tasks it is aggregating and the operators
+// that signed them. The service only reports unknown tasks and duplicates in the message of its errors,
+// so they are checked here first and returned as ErrBlsTaskNotFound and ErrBlsDuplicateSignature.
+// The service can't cancel a task either, so evicted tasks are dropped here instead.
+type blsAggregator struct {
+	service   blsagg.BlsAggregationService
+	responses chan blsagg.BlsAggregationServiceResponse
+
+	mutex sync.Mutex
+	// Signers of each task being aggregated, by task index
+	signersByIdx map[uint32]map[eigentypes.OperatorId]struct{}
+	// Tasks evicted before the service finished them. Their responses are dropped
+	evictedIdx map[uint32]struct{}
+}
+
+var _ blsagg.BlsAggregationService = (*blsAggregator)(nil)
+
+func newBlsAggregator(service blsagg.BlsAggregationService) *blsAggregator {
+	aggregator := &blsAggregator{
+		service:      service,
+		responses:    make(chan blsagg.BlsAggregationServiceResponse),
+		signersByIdx: make(map[uint32]map[eigentypes.OperatorId]struct{}),
+		evictedIdx:   make(map[uint32]struct{}),
+	}
+	go aggregator.forwardResponses()
+	return aggregator
+}
+
+func (a *blsAggregator) InitializeNewTask(taskIndex eigentypes.TaskIndex, taskCreatedBlock uint32, quorumNumbers eigentypes.QuorumNums, quorumThresholdPercentages eigentypes.QuorumThresholdPercentages, timeToExpiry time.Duration) error {
+	// Tracked before the service gets it, so its response always finds the task
+	a.mutex.Lock()
+	a.signersByIdx[taskIndex] = make(map[eigentypes.OperatorId]struct{})
+	a.mutex.Unlock()
+
+	err := a.service.InitializeNewTask(taskIndex, taskCreatedBlock, quorumNumbers, quorumThresholdPercentages, timeToExpiry)
+	if err != nil {
+		a.mutex.Lock()
+		delete(a.signersByIdx, taskIndex)
+		a.mutex.Unlock()
+	}
+	return err
+}
+
+func (a *blsAggregator) ProcessNewSignature(ctx context.Context, taskIndex eigentypes.TaskIndex, taskResponse eigentypes.TaskResponse, blsSignature *bls.Signature, operatorId eigentypes.OperatorId) error {
+	a.mutex.Lock()
+	signers, ok := a.signersByIdx[taskIndex]
+	_, duplicate := signers[operatorId]
+	a.mutex.Unlock()
+	if !ok {
+		return ErrBlsTaskNotFound
+	}
+	if duplicate {
+		return ErrBlsDuplicateSignature
+	}
+
+	err := a.service.ProcessNewSignature(ctx, taskIndex, taskResponse, blsSignature, operatorId)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	if signers, ok := a.signersByIdx[taskIndex]; ok {
+		signers[operatorId] = struct{}{}
+	}
+	a.mutex.Unlock()
+	return nil
+}
+
+func (a *blsAggregator) GetResponseChannel() <-chan blsagg.BlsAggregationServiceResponse {
+	return a.responses
+}
+
+// Evict drops a task the aggregator stopped tracking. Its signatures are answered with ErrBlsTaskNotFound,
+// and its response is not forwarded. The service still holds the task until it expires.
+func (a *blsAggregator) Evict(taskIndex uint32) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, ok := a.signersByIdx[taskIndex]; ok {
+		delete(a.signersByIdx, taskIndex)
+		a.evictedIdx[taskIndex] = struct{}{}
+	}
+}
+
+// forwardResponses is a long-lived goroutine that forgets the tasks the service finishes, either
+// reaching quorum or expiring, and forwards their responses unless the task was evicted
+func (a *blsAggregator) forwardResponses() {
+	for response := range a.service.GetResponseChannel() {
+		a.mutex.Lock()
+		delete(a.signersByIdx, response.TaskIndex)
+		_, evicted := a.evictedIdx[response.TaskIndex]
+		delete(a.evictedIdx, response.TaskIndex)
+		a.mutex.Unlock()
+
+		if !evicted {
+			a.responses <- response
+		}
+	}
+}
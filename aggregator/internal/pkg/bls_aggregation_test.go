@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+func TestBlsAggregatorErrors(t *testing.T) {
+	service := newFakeBlsAggregationService()
+	aggregator := newBlsAggregator(service)
+	operatorId := eigentypes.OperatorId{1}
+	signature := bls.NewZeroSignature()
+
+	err := aggregator.ProcessNewSignature(context.Background(), 0, [32]byte{}, signature, operatorId)
+	if !errors.Is(err, ErrBlsTaskNotFound) {
+		t.Fatalf("Expected ErrBlsTaskNotFound for a task never initialized, got %v", err)
+	}
+
+	if err := aggregator.InitializeNewTask(0, 10, eigentypes.QuorumNums{0}, eigentypes.QuorumThresholdPercentages{67}, time.Minute); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := aggregator.ProcessNewSignature(context.Background(), 0, [32]byte{}, signature, operatorId); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	err = aggregator.ProcessNewSignature(context.Background(), 0, [32]byte{}, signature, operatorId)
+	if !errors.Is(err, ErrBlsDuplicateSignature) {
+		t.Fatalf("Expected ErrBlsDuplicateSignature, got %v", err)
+	}
+
+	// Other service errors are returned as they are
+	service.mutex.Lock()
+	service.err = blsagg.IncorrectSignatureError
+	service.mutex.Unlock()
+	err = aggregator.ProcessNewSignature(context.Background(), 0, [32]byte{}, signature, eigentypes.OperatorId{2})
+	if !errors.Is(err, blsagg.IncorrectSignatureError) {
+		t.Fatalf("Expected IncorrectSignatureError, got %v", err)
+	}
+	service.mutex.Lock()
+	service.err = nil
+	service.mutex.Unlock()
+
+	// Once the service finishes the task, it is forwarded and forgotten
+	service.responses <- blsagg.BlsAggregationServiceResponse{TaskIndex: 0}
+	select {
+	case response := <-aggregator.GetResponseChannel():
+		if response.TaskIndex != 0 {
+			t.Fatalf("Expected the response of task 0, got task %d", response.TaskIndex)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The response was not forwarded")
+	}
+	err = aggregator.ProcessNewSignature(context.Background(), 0, [32]byte{}, signature, eigentypes.OperatorId{3})
+	if !errors.Is(err, ErrBlsTaskNotFound) {
+		t.Fatalf("Expected ErrBlsTaskNotFound for a finished task, got %v", err)
+	}
+}
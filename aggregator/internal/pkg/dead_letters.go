@@ -0,0 +1,228 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+const DeadLetterRetryInterval = 1 * time.Minute
+
+const deadLetterFileExtension = ".json"
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is an aggregated response that could not be sent after MaxSentTxRetries attempts.
+// It keeps everything needed to send the response again.
+type DeadLetter struct {
+	BatchIdentifierHash         common.Hash                                                    `json:"batch_identifier_hash"`
+	BatchMerkleRoot             common.Hash                                                    `json:"batch_merkle_root"`
+	SenderAddress               common.Address                                                 `json:"sender_address"`
+	TaskCreatedBlock            uint64                                                         `json:"task_created_block"`
+	NonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature `json:"non_signer_stakes_and_signature"`
+	LastError                   string                                                         `json:"last_error"`
+	Attempts                    int                                                            `json:"attempts"`
+	CreatedAt                   time.Time                                                      `json:"created_at"`
+	LastAttemptAt               time.Time                                                      `json:"last_attempt_at"`
+}
+
+// DeadLetterStore keeps one JSON file per dead letter in a directory, named after the batch identifier hash.
+// Plain files let the admin commands read and change the store while the aggregator is running.
+type DeadLetterStore struct {
+	dir string
+}
+
+func NewDeadLetterStore(dir string) (*DeadLetterStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter store: %w", err)
+	}
+	return &DeadLetterStore{dir: dir}, nil
+}
+
+func (s *DeadLetterStore) Put(deadLetter DeadLetter) error {
+	file, err := json.MarshalIndent(deadLetter, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(s.path(deadLetter.BatchIdentifierHash), file, 0644); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+func (s *DeadLetterStore) Get(batchIdentifierHash common.Hash) (DeadLetter, error) {
+	var deadLetter DeadLetter
+	file, err := os.ReadFile(s.path(batchIdentifierHash))
+	if errors.Is(err, os.ErrNotExist) {
+		return deadLetter, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return deadLetter, err
+	}
+	if err := json.Unmarshal(file, &deadLetter); err != nil {
+		return deadLetter, fmt.Errorf("failed to unmarshal dead letter: %w", err)
+	}
+	return deadLetter, nil
+}
+
+// List returns every dead letter, oldest first
+func (s *DeadLetterStore) List() ([]DeadLetter, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var deadLetters []DeadLetter
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, deadLetterFileExtension) {
+			continue
+		}
+		batchIdentifierHash := common.HexToHash(strings.TrimSuffix(name, deadLetterFileExtension))
+		deadLetter, err := s.Get(batchIdentifierHash)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].CreatedAt.Before(deadLetters[j].CreatedAt)
+	})
+	return deadLetters, nil
+}
+
+func (s *DeadLetterStore) Delete(batchIdentifierHash common.Hash) error {
+	err := os.Remove(s.path(batchIdentifierHash))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrDeadLetterNotFound
+	}
+	return err
+}
+
+func (s *DeadLetterStore) path(batchIdentifierHash common.Hash) string {
+	return filepath.Join(s.dir, batchIdentifierHash.Hex()+deadLetterFileExtension)
+}
+
+// ResubmitDeadLetter sends a dead letter again. It is removed from the store once the batch is
+// responded, either by this transaction or by someone else. Otherwise the attempt is recorded on it.
+func ResubmitDeadLetter(ctx context.Context, store *DeadLetterStore, avsReader chainio.AvsReaderer, avsWriter chainio.AvsWriterer, deadLetter DeadLetter) (*common.Hash, error) {
+	responded, err := avsReader.IsBatchResponded(deadLetter.BatchIdentifierHash)
+	if err == nil && responded {
+		return nil, store.Delete(deadLetter.BatchIdentifierHash)
+	}
+
+	receipt, err := avsWriter.SendAggregatedResponse(ctx, deadLetter.BatchIdentifierHash, deadLetter.BatchMerkleRoot, deadLetter.SenderAddress, deadLetter.NonSignerStakesAndSignature)
+	if err != nil {
+		deadLetter.Attempts++
+		deadLetter.LastError = err.Error()
+		deadLetter.LastAttemptAt = time.Now()
+		if putErr := store.Put(deadLetter); putErr != nil {
+			return nil, fmt.Errorf("%w, and could not update dead letter: %v", err, putErr)
+		}
+		return nil, err
+	}
+
+	txHash := receipt.TxHash
+	return &txHash, store.Delete(deadLetter.BatchIdentifierHash)
+}
+
+// addDeadLetter stores a response that could not be sent, if the dead letter store is enabled.
+// Without it, the task is kept in the task store so it is recovered on restart.
+func (agg *Aggregator) addDeadLetter(batchIdentifierHash [32]byte, batchData BatchData, taskCreatedBlock uint64, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, sendErr error) {
+	if agg.deadLetterStore == nil {
+		return
+	}
+
+	now := time.Now()
+	deadLetter := DeadLetter{
+		BatchIdentifierHash:         batchIdentifierHash,
+		BatchMerkleRoot:             batchData.BatchMerkleRoot,
+		SenderAddress:               batchData.SenderAddress,
+		TaskCreatedBlock:            taskCreatedBlock,
+		NonSignerStakesAndSignature: nonSignerStakesAndSignature,
+		Attempts:                    MaxSentTxRetries,
+		CreatedAt:                   now,
+		LastAttemptAt:               now,
+	}
+	if sendErr != nil {
+		deadLetter.LastError = sendErr.Error()
+	}
+
+	if err := agg.deadLetterStore.Put(deadLetter); err != nil {
+		agg.logger.Error("Could not store dead letter, this batch will be lost", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+		return
+	}
+	agg.logger.Warn("Aggregated response stored as dead letter, it will be retried in the background", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+
+	// The dead letter store retries it from now on, recovering it on restart would send it twice
+	agg.forgetTask(batchIdentifierHash)
+}
+
+// ProcessDeadLetters is a long-lived goroutine that retries the dead letters every DeadLetterRetryInterval.
+// Each retry simulates the response first, so it is only sent once the conditions that made it fail change,
+// such as the aggregator balance being refilled or gas falling under the batch fee limit.
+func (agg *Aggregator) ProcessDeadLetters() {
+	if agg.deadLetterStore == nil {
+		return
+	}
+
+	ticker := time.NewTicker(DeadLetterRetryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// Standbys leave the retries to the leader
+		if !agg.leaderLease.IsLeader() {
+			continue
+		}
+
+		deadLetters, err := agg.deadLetterStore.List()
+		if err != nil {
+			agg.logger.Error("Could not list dead letters", "err", err)
+			continue
+		}
+
+		for _, deadLetter := range deadLetters {
+			ctx, cancel := context.WithTimeout(context.Background(), SendAggregatedResponseTimeout)
+			txHash, err := ResubmitDeadLetter(ctx, agg.deadLetterStore, agg.avsReader, agg.avsWriter, deadLetter)
+			cancel()
+			if err != nil {
+				agg.logger.Debug("Dead letter retry failed", "batchIdentifierHash", deadLetter.BatchIdentifierHash.Hex(), "err", err)
+				continue
+			}
+			if txHash == nil {
+				agg.logger.Info("Dead letter batch already responded, removing it", "batchIdentifierHash", deadLetter.BatchIdentifierHash.Hex())
+			} else {
+				agg.logger.Info("Dead letter resubmitted", "batchIdentifierHash", deadLetter.BatchIdentifierHash.Hex(), "txHash", txHash.Hex())
+				agg.metrics.IncAggregatedResponses()
+				agg.audit(AuditRecord{
+					Event:               AuditTxConfirmed,
+					BatchIdentifierHash: deadLetter.BatchIdentifierHash,
+					BatchMerkleRoot:     deadLetter.BatchMerkleRoot,
+					TxHash:              txHash.Hex(),
+					Reason:              "dead_letter",
+				})
+			}
+			agg.taskMutex.Lock()
+			taskIndex, ok := agg.batchesIdxByIdentifierHash[deadLetter.BatchIdentifierHash]
+			agg.taskMutex.Unlock()
+			if ok {
+				agg.setTaskConfirmed(taskIndex, txHash)
+			}
+			agg.forgetTask(deadLetter.BatchIdentifierHash)
+		}
+	}
+}
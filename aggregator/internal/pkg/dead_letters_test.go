@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+)
+
+func newTestDeadLetter(t *testing.T, store *DeadLetterStore) DeadLetter {
+	t.Helper()
+	batchMerkleRoot := [32]byte{1}
+	senderAddress := [20]byte{2}
+	deadLetter := DeadLetter{
+		BatchIdentifierHash: fakes.BatchIdentifierHash(batchMerkleRoot, senderAddress),
+		BatchMerkleRoot:     batchMerkleRoot,
+		SenderAddress:       senderAddress,
+		TaskCreatedBlock:    10,
+		Attempts:            MaxSentTxRetries,
+		CreatedAt:           time.Now(),
+	}
+	if err := store.Put(deadLetter); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return deadLetter
+}
+
+func TestResubmitDeadLetter(t *testing.T) {
+	store, err := NewDeadLetterStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reader := fakes.NewFakeAvsReader()
+	writer := fakes.NewFakeAvsWriter(reader)
+	deadLetter := newTestDeadLetter(t, store)
+
+	// A failed attempt is recorded on the dead letter
+	sendErr := errors.New("insufficient funds")
+	writer.SetSendError(sendErr)
+	if _, err := ResubmitDeadLetter(context.Background(), store, reader, writer, deadLetter); !errors.Is(err, sendErr) {
+		t.Fatalf("Expected %v, got %v", sendErr, err)
+	}
+	stored, err := store.Get(deadLetter.BatchIdentifierHash)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stored.Attempts != MaxSentTxRetries+1 || stored.LastError != sendErr.Error() {
+		t.Fatalf("Expected the failed attempt to be recorded, got %+v", stored)
+	}
+
+	writer.SetSendError(nil)
+	txHash, err := ResubmitDeadLetter(context.Background(), store, reader, writer, stored)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	responses := writer.Responses()
+	if len(responses) != 1 || txHash == nil || *txHash != responses[0].TxHash {
+		t.Fatalf("Expected the batch to be responded by the returned transaction, got %v and %v", txHash, responses)
+	}
+	if _, err := store.Get(deadLetter.BatchIdentifierHash); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Fatalf("Expected the dead letter to be removed, got %v", err)
+	}
+}
+
+func TestResubmitDeadLetterAlreadyResponded(t *testing.T) {
+	store, err := NewDeadLetterStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reader := fakes.NewFakeAvsReader()
+	writer := fakes.NewFakeAvsWriter(reader)
+	deadLetter := newTestDeadLetter(t, store)
+	reader.SetBatchResponded(deadLetter.BatchIdentifierHash, true)
+
+	txHash, err := ResubmitDeadLetter(context.Background(), store, reader, writer, deadLetter)
+	if err != nil || txHash != nil {
+		t.Fatalf("Expected no transaction and no error, got %v, %v", txHash, err)
+	}
+	if responses := writer.Responses(); len(responses) != 0 {
+		t.Fatalf("Expected no response to be sent, got %v", responses)
+	}
+	if _, err := store.Get(deadLetter.BatchIdentifierHash); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Fatalf("Expected the dead letter to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const maxJsonRpcBodySize = 1 << 20 // 1 MiB
+
+// ServeJsonRpc starts the versioned JSON-RPC 2.0 API. It runs alongside the net/rpc server,
+// so operators not written in Go can take part.
+func (agg *Aggregator) ServeJsonRpc() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(types.AggregatorApiV1Path, agg.handleJsonRpcV1)
+
+	server := http.Server{
+		Addr:              agg.AggregatorConfig.Aggregator.JsonRpcServerIpPortAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         agg.serverTlsConfig,
+	}
+
+	agg.logger.Info("Starting JSON-RPC server on address", "address",
+		agg.AggregatorConfig.Aggregator.JsonRpcServerIpPortAddress, "tls", agg.serverTlsConfig != nil)
+	if agg.serverTlsConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
+
+func (agg *Aggregator) handleJsonRpcV1(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxJsonRpcBodySize))
+	if err != nil {
+		writeJsonRpc(w, jsonRpcErrorResponse(nil, types.JsonRpcParseError, "could not read request"))
+		return
+	}
+
+	clientIp := remoteIp(r.RemoteAddr)
+
+	// A batch is a JSON array of requests, answered with an array of responses
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var requests []types.JsonRpcRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			writeJsonRpc(w, jsonRpcErrorResponse(nil, types.JsonRpcParseError, "invalid JSON"))
+			return
+		}
+		if len(requests) == 0 || len(requests) > types.MaxJsonRpcBatchRequests {
+			writeJsonRpc(w, jsonRpcErrorResponse(nil, types.JsonRpcInvalidRequest, "invalid batch size"))
+			return
+		}
+
+		responses := make([]*types.JsonRpcResponse, 0, len(requests))
+		for i := range requests {
+			if response := agg.dispatchJsonRpcV1(clientIp, &requests[i]); response != nil {
+				responses = append(responses, response)
+			}
+		}
+		if len(responses) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJsonRpc(w, responses)
+		return
+	}
+
+	var request types.JsonRpcRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeJsonRpc(w, jsonRpcErrorResponse(nil, types.JsonRpcParseError, "invalid JSON"))
+		return
+	}
+	response := agg.dispatchJsonRpcV1(clientIp, &request)
+	if response == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJsonRpc(w, response)
+}
+
+// dispatchJsonRpcV1 runs a single request. Returns nil for notifications, which have no id.
+func (agg *Aggregator) dispatchJsonRpcV1(remoteIp string, request *types.JsonRpcRequest) *types.JsonRpcResponse {
+	if request.JsonRpc != types.JsonRpcVersion {
+		return jsonRpcErrorResponse(request.Id, types.JsonRpcInvalidRequest, "jsonrpc must be \"2.0\"")
+	}
+
+	result, rpcErr := agg.callJsonRpcV1(remoteIp, request.Method, request.Params)
+	if len(request.Id) == 0 {
+		return nil
+	}
+	if rpcErr != nil {
+		return &types.JsonRpcResponse{JsonRpc: types.JsonRpcVersion, Error: rpcErr, Id: request.Id}
+	}
+	return &types.JsonRpcResponse{JsonRpc: types.JsonRpcVersion, Result: result, Id: request.Id}
+}
+
+func (agg *Aggregator) callJsonRpcV1(remoteIp string, method string, params json.RawMessage) (interface{}, *types.JsonRpcError) {
+	switch method {
+	case types.SubmitSignedTaskMethod:
+		var signedTaskResponseV1 types.SignedTaskResponseV1
+		if err := json.Unmarshal(params, &signedTaskResponseV1); err != nil {
+			return nil, &types.JsonRpcError{Code: types.JsonRpcInvalidParams, Message: err.Error()}
+		}
+		signedTaskResponse, err := signedTaskResponseV1.ToSignedTaskResponse()
+		if err != nil {
+			return nil, &types.JsonRpcError{Code: types.JsonRpcInvalidParams, Message: err.Error()}
+		}
+		authenticated := signedTaskResponseV1.ToAuthenticatedSignedTaskResponse(signedTaskResponse)
+		reply := agg.submitSignedTaskResponse(remoteIp, signedTaskResponse, authenticated)
+		if !types.SignedTaskResponseReplyIsAccepted(reply) {
+			return nil, &types.JsonRpcError{
+				Code:    types.SignedTaskResponseReplyJsonRpcCode(reply),
+				Message: "signed task response rejected: " + types.SignedTaskResponseReplyString(reply),
+				Data:    reply,
+			}
+		}
+		return types.SubmitSignedTaskResultV1{Accepted: true, Pending: reply == types.SignedTaskResponseAcceptedPending}, nil
+
+	case types.GetTaskStatusMethod:
+		var taskStatusParams types.TaskStatusParamsV1
+		if err := json.Unmarshal(params, &taskStatusParams); err != nil {
+			return nil, &types.JsonRpcError{Code: types.JsonRpcInvalidParams, Message: err.Error()}
+		}
+		return agg.taskStatusV1(taskStatusParams.BatchIdentifierHash), nil
+
+	case types.AggregatorHealthMethod:
+		agg.taskMutex.Lock()
+		pendingTasks := len(agg.batchesIdxByIdentifierHash)
+		agg.taskMutex.Unlock()
+		return types.HealthV1{
+			Status:       "ok",
+			ApiVersion:   types.AggregatorApiV1,
+			Leader:       agg.leaderLease.IsLeader(),
+			PendingTasks: pendingTasks,
+		}, nil
+	}
+
+	return nil, &types.JsonRpcError{Code: types.JsonRpcMethodNotFound, Message: "method not found: " + method}
+}
+
+func (agg *Aggregator) taskStatusV1(batchIdentifierHash [32]byte) types.TaskStatusV1 {
+	status := types.TaskStatusV1{BatchIdentifierHash: batchIdentifierHash}
+
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	if ok {
+		status.Known = true
+		status.TaskIndex = taskIndex
+		status.TaskCreatedBlock = agg.batchCreatedBlockByIdx[taskIndex]
+	}
+	agg.taskMutex.Unlock()
+
+	if ok {
+		responded, err := agg.avsReader.IsBatchResponded(batchIdentifierHash)
+		if err != nil {
+			agg.logger.Warn("Could not check if batch was responded", "err", err)
+		}
+		status.Responded = responded
+	}
+	return status
+}
+
+func jsonRpcErrorResponse(id json.RawMessage, code int, message string) *types.JsonRpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &types.JsonRpcResponse{
+		JsonRpc: types.JsonRpcVersion,
+		Error:   &types.JsonRpcError{Code: code, Message: message},
+		Id:      id,
+	}
+}
+
+func writeJsonRpc(w http.ResponseWriter, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,223 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+type jsonRpcTestResponse struct {
+	JsonRpc string              `json:"jsonrpc"`
+	Result  json.RawMessage     `json:"result"`
+	Error   *types.JsonRpcError `json:"error"`
+	Id      json.RawMessage     `json:"id"`
+}
+
+func postJsonRpc(t *testing.T, url string, body string) (int, []byte) {
+	t.Helper()
+	res, err := http.Post(url+types.AggregatorApiV1Path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Could not send request: %v", err)
+	}
+	defer res.Body.Close()
+	var raw json.RawMessage
+	if res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+			t.Fatalf("Could not decode response: %v", err)
+		}
+	}
+	return res.StatusCode, raw
+}
+
+func jsonRpcRequestBody(t *testing.T, method string, params interface{}) string {
+	t.Helper()
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Could not encode params: %v", err)
+	}
+	body, err := json.Marshal(types.JsonRpcRequest{JsonRpc: types.JsonRpcVersion, Method: method, Params: encodedParams, Id: json.RawMessage("1")})
+	if err != nil {
+		t.Fatalf("Could not encode request: %v", err)
+	}
+	return string(body)
+}
+
+func TestJsonRpcV1(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := httptest.NewServer(http.HandlerFunc(agg.handleJsonRpcV1))
+	defer server.Close()
+
+	operator := agg.newTestOperator(t)
+	batchMerkleRoot := [32]byte{1}
+	senderAddress := [20]byte{2}
+	agg.AddNewTask(batchMerkleRoot, senderAddress, 10)
+	signedTaskResponse := operator.sign(batchMerkleRoot, senderAddress)
+	unknownBatchIdentifierHash := common.Hash{0xff}
+
+	tests := []struct {
+		name string
+		body string
+		// Expected error code, 0 if the request must succeed
+		errorCode   int
+		checkResult func(t *testing.T, result json.RawMessage)
+	}{
+		{
+			name:      "malformed JSON",
+			body:      `{"jsonrpc": "2.0", "method": `,
+			errorCode: types.JsonRpcParseError,
+		},
+		{
+			name:      "malformed batch",
+			body:      `[{"jsonrpc": "2.0"`,
+			errorCode: types.JsonRpcParseError,
+		},
+		{
+			name:      "wrong version",
+			body:      `{"jsonrpc": "1.0", "method": "health", "id": 1}`,
+			errorCode: types.JsonRpcInvalidRequest,
+		},
+		{
+			name:      "unknown method",
+			body:      `{"jsonrpc": "2.0", "method": "deleteEverything", "id": 1}`,
+			errorCode: types.JsonRpcMethodNotFound,
+		},
+		{
+			name:      "invalid params",
+			body:      `{"jsonrpc": "2.0", "method": "submitSignedTaskResponse", "params": {"bls_signature": "0x01"}, "id": 1}`,
+			errorCode: types.JsonRpcInvalidParams,
+		},
+		{
+			name: "task status of an unknown batch",
+			body: jsonRpcRequestBody(t, types.GetTaskStatusMethod, types.TaskStatusParamsV1{BatchIdentifierHash: unknownBatchIdentifierHash}),
+			checkResult: func(t *testing.T, result json.RawMessage) {
+				var status types.TaskStatusV1
+				if err := json.Unmarshal(result, &status); err != nil {
+					t.Fatalf("Could not decode result: %v", err)
+				}
+				if status.Known || status.BatchIdentifierHash != unknownBatchIdentifierHash {
+					t.Errorf("Expected an unknown task, got %+v", status)
+				}
+			},
+		},
+		{
+			name: "task status of a known batch",
+			body: jsonRpcRequestBody(t, types.GetTaskStatusMethod, types.TaskStatusParamsV1{BatchIdentifierHash: signedTaskResponse.BatchIdentifierHash}),
+			checkResult: func(t *testing.T, result json.RawMessage) {
+				var status types.TaskStatusV1
+				if err := json.Unmarshal(result, &status); err != nil {
+					t.Fatalf("Could not decode result: %v", err)
+				}
+				if !status.Known || status.TaskCreatedBlock != 10 || status.Responded {
+					t.Errorf("Expected a known pending task created at block 10, got %+v", status)
+				}
+			},
+		},
+		{
+			name: "submit signed task response",
+			body: jsonRpcRequestBody(t, types.SubmitSignedTaskMethod, types.NewSignedTaskResponseV1(signedTaskResponse)),
+			checkResult: func(t *testing.T, result json.RawMessage) {
+				var submitResult types.SubmitSignedTaskResultV1
+				if err := json.Unmarshal(result, &submitResult); err != nil {
+					t.Fatalf("Could not decode result: %v", err)
+				}
+				if !submitResult.Accepted || submitResult.Pending {
+					t.Errorf("Expected the response to be accepted, got %+v", submitResult)
+				}
+				if signatures := agg.bls.Signatures(0); len(signatures) != 1 || signatures[0] != operator.id {
+					t.Errorf("Expected the signature to reach the BLS aggregation service, got %v", signatures)
+				}
+			},
+		},
+		{
+			name:      "submit a duplicate signed task response",
+			body:      jsonRpcRequestBody(t, types.SubmitSignedTaskMethod, types.NewSignedTaskResponseV1(signedTaskResponse)),
+			errorCode: types.JsonRpcDuplicate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, body := postJsonRpc(t, server.URL, test.body)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", status)
+			}
+			var response jsonRpcTestResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatalf("Could not decode response %s: %v", body, err)
+			}
+			if response.JsonRpc != types.JsonRpcVersion {
+				t.Errorf("Expected jsonrpc %q, got %q", types.JsonRpcVersion, response.JsonRpc)
+			}
+
+			if test.errorCode != 0 {
+				if response.Error == nil || response.Error.Code != test.errorCode {
+					t.Fatalf("Expected error code %d, got %s", test.errorCode, body)
+				}
+				return
+			}
+			if response.Error != nil {
+				t.Fatalf("Unexpected error: %v", response.Error)
+			}
+			test.checkResult(t, response.Result)
+		})
+	}
+}
+
+func TestJsonRpcV1Notification(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := httptest.NewServer(http.HandlerFunc(agg.handleJsonRpcV1))
+	defer server.Close()
+
+	status, _ := postJsonRpc(t, server.URL, `{"jsonrpc": "2.0", "method": "health"}`)
+	if status != http.StatusNoContent {
+		t.Fatalf("Expected notifications to get no response, got status %d", status)
+	}
+}
+
+func TestJsonRpcV1Batch(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := httptest.NewServer(http.HandlerFunc(agg.handleJsonRpcV1))
+	defer server.Close()
+
+	status, body := postJsonRpc(t, server.URL, `[
+		{"jsonrpc": "2.0", "method": "health", "id": 1},
+		{"jsonrpc": "2.0", "method": "unknown", "id": 2},
+		{"jsonrpc": "2.0", "method": "health"}
+	]`)
+	if status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	var responses []jsonRpcTestResponse
+	if err := json.Unmarshal(body, &responses); err != nil {
+		t.Fatalf("Could not decode response %s: %v", body, err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected a response per request with an id, got %s", body)
+	}
+	if responses[0].Error != nil || string(responses[0].Id) != "1" {
+		t.Errorf("Expected health to succeed, got %s", body)
+	}
+	if responses[1].Error == nil || responses[1].Error.Code != types.JsonRpcMethodNotFound || string(responses[1].Id) != "2" {
+		t.Errorf("Expected the unknown method to fail, got %s", body)
+	}
+}
+
+func TestJsonRpcV1MethodNotAllowed(t *testing.T) {
+	agg := newTestAggregator(t)
+	server := httptest.NewServer(http.HandlerFunc(agg.handleJsonRpcV1))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + types.AggregatorApiV1Path)
+	if err != nil {
+		t.Fatalf("Could not send request: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status 405, got %d", res.StatusCode)
+	}
+}
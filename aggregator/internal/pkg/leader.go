@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const LeaderLeaseRenewInterval = 2 * time.Second
+
+// Variables so tests don't wait
+var (
+	// How long a standby keeps a quorum-reached task waiting for the leader to respond it
+	StandbyResponseTimeout      = 5 * time.Minute
+	StandbyResponsePollInterval = 2 * time.Second
+)
+
+// LeaderLock is the lock backing the leader lease. Only the instance holding it submits
+// aggregated responses. Implementations can use a local file or a shared store.
+type LeaderLock interface {
+	// TryAcquire acquires the lock, or renews it if it is already held. It must not block.
+	TryAcquire() (bool, error)
+	Release() error
+}
+
+// alwaysLeaderLock is used when HA is disabled, the single instance is always the leader
+type alwaysLeaderLock struct{}
+
+func (alwaysLeaderLock) TryAcquire() (bool, error) { return true, nil }
+func (alwaysLeaderLock) Release() error            { return nil }
+
+// FileLeaderLock is a LeaderLock backed by an exclusive flock on a local file.
+// The OS releases the lock when the holder process dies, so a standby takes over on its next renewal.
+// It only coordinates instances on the same host, flock is not reliable on network filesystems.
+// Instances on different hosts must use EtcdLeaderLock.
+type FileLeaderLock struct {
+	path  string
+	file  *os.File
+	mutex sync.Mutex
+}
+
+func NewFileLeaderLock(path string) *FileLeaderLock {
+	return &FileLeaderLock{path: path}
+}
+
+func (l *FileLeaderLock) TryAcquire() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file != nil {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return false, err
+	}
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		file.Close()
+		return false, nil
+	}
+	if err != nil {
+		file.Close()
+		return false, err
+	}
+
+	// Informative only, the flock is what holds the lease
+	_ = file.Truncate(0)
+	_, _ = file.WriteAt([]byte(fmt.Sprintf("%d\n", os.Getpid())), 0)
+
+	l.file = file
+	return true, nil
+}
+
+func (l *FileLeaderLock) Release() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+	l.file = nil
+	return err
+}
+
+// LeaderLease keeps trying to acquire or renew the leader lock, tracking whether this instance is the leader
+type LeaderLease struct {
+	lock     LeaderLock
+	isLeader atomic.Bool
+	logger   logging.Logger
+	onChange func(isLeader bool)
+}
+
+func NewLeaderLease(lock LeaderLock, logger logging.Logger, onChange func(isLeader bool)) *LeaderLease {
+	return &LeaderLease{lock: lock, logger: logger, onChange: onChange}
+}
+
+func (l *LeaderLease) IsLeader() bool {
+	return l.isLeader.Load()
+}
+
+// Renew tries to acquire or renew the lock once
+func (l *LeaderLease) Renew() {
+	acquired, err := l.lock.TryAcquire()
+	if err != nil {
+		l.logger.Error("Could not acquire leader lock", "err", err)
+		acquired = false
+	}
+
+	if l.isLeader.Swap(acquired) != acquired {
+		if acquired {
+			l.logger.Info("This aggregator instance is now the leader")
+		} else {
+			l.logger.Warn("This aggregator instance lost the leadership, running as standby")
+		}
+		if l.onChange != nil {
+			l.onChange(acquired)
+		}
+	}
+}
+
+// Run is a long-lived goroutine that renews the lease every LeaderLeaseRenewInterval
+func (l *LeaderLease) Run() {
+	ticker := time.NewTicker(LeaderLeaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		l.Renew()
+		<-ticker.C
+	}
+}
+
+// waitForLeadership blocks while this instance is a standby. It returns true when it becomes the
+// leader and the batch still needs a response. It returns false if another instance responded it, leaving
+// the task confirmed, or if it times out, leaving the task handed to the leader.
+func (agg *Aggregator) waitForLeadership(taskIndex uint32, batchIdentifierHash [32]byte) bool {
+	deadline := time.Now().Add(StandbyResponseTimeout)
+	for !agg.leaderLease.IsLeader() {
+		if time.Now().After(deadline) {
+			agg.logger.Warn("Standby gave up waiting for the leader to respond the batch", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+			agg.setTaskState(taskIndex, TaskStateHandedToLeader)
+			return false
+		}
+
+		responded, err := agg.avsReader.IsBatchResponded(batchIdentifierHash)
+		if err != nil {
+			agg.logger.Warn("Could not check if batch was responded", "err", err)
+		} else if responded {
+			agg.logger.Info("Batch responded by the leader", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+			agg.setTaskConfirmed(taskIndex, nil)
+			agg.forgetTask(batchIdentifierHash)
+			return false
+		}
+		time.Sleep(StandbyResponsePollInterval)
+	}
+	return true
+}
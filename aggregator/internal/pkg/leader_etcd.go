@@ -0,0 +1,193 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultEtcdLeaderLockKey = "/aligned/aggregator/leader"
+	DefaultEtcdLeaderLockTtl = 10 * time.Second
+	etcdRequestTimeout       = 2 * time.Second
+)
+
+// EtcdLeaderLock is a LeaderLock backed by a key in etcd, so instances on different hosts share it.
+// It talks to the etcd v3 JSON gateway. The key is bound to a lease with the given TTL that is kept
+// alive on every renewal, so if the holder dies etcd deletes the key and a standby takes over.
+type EtcdLeaderLock struct {
+	url        string
+	key        string
+	value      string
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mutex   sync.Mutex
+	leaseId string
+	held    bool
+}
+
+// NewEtcdLeaderLock creates a lock on key in the etcd cluster at url. value identifies this instance
+// in the key, for operators inspecting who is the leader.
+func NewEtcdLeaderLock(url string, key string, value string, ttl time.Duration) *EtcdLeaderLock {
+	if key == "" {
+		key = DefaultEtcdLeaderLockKey
+	}
+	if ttl <= LeaderLeaseRenewInterval {
+		ttl = DefaultEtcdLeaderLockTtl
+	}
+	return &EtcdLeaderLock{
+		url:        url,
+		key:        key,
+		value:      value,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: etcdRequestTimeout},
+	}
+}
+
+func (l *EtcdLeaderLock) TryAcquire() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Keep the lease alive, the key is deleted with it if it expires
+	if l.leaseId != "" {
+		alive, err := l.keepAlive()
+		if err != nil {
+			return false, err
+		}
+		if !alive {
+			l.leaseId = ""
+			l.held = false
+		}
+	}
+	if l.held {
+		return true, nil
+	}
+
+	if l.leaseId == "" {
+		leaseId, err := l.grantLease()
+		if err != nil {
+			return false, err
+		}
+		l.leaseId = leaseId
+	}
+
+	held, err := l.putIfAbsent()
+	if err != nil {
+		return false, err
+	}
+	l.held = held
+	return held, nil
+}
+
+func (l *EtcdLeaderLock) Release() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.leaseId == "" {
+		return nil
+	}
+	// Revoking the lease deletes the key, so a standby takes over on its next renewal
+	err := l.post("/v3/lease/revoke", map[string]string{"ID": l.leaseId}, nil)
+	l.leaseId = ""
+	l.held = false
+	return err
+}
+
+func (l *EtcdLeaderLock) grantLease() (string, error) {
+	var response struct {
+		ID  string `json:"ID"`
+		TTL string `json:"TTL"`
+	}
+	request := map[string]string{"TTL": strconv.Itoa(int(l.ttl.Seconds()))}
+	if err := l.post("/v3/lease/grant", request, &response); err != nil {
+		return "", err
+	}
+	if response.ID == "" {
+		return "", fmt.Errorf("etcd did not grant a lease")
+	}
+	return response.ID, nil
+}
+
+// keepAlive renews the lease, returning false if it already expired
+func (l *EtcdLeaderLock) keepAlive() (bool, error) {
+	var response struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := l.post("/v3/lease/keepalive", map[string]string{"ID": l.leaseId}, &response); err != nil {
+		return false, err
+	}
+	ttl, _ := strconv.ParseInt(response.Result.TTL, 10, 64)
+	return ttl > 0, nil
+}
+
+// putIfAbsent creates the key bound to our lease if nobody holds it. If the key exists, it is
+// still ours when it is bound to our lease, as happens when a previous response was lost.
+func (l *EtcdLeaderLock) putIfAbsent() (bool, error) {
+	key := base64.StdEncoding.EncodeToString([]byte(l.key))
+	request := map[string]interface{}{
+		"compare": []map[string]string{
+			{"key": key, "target": "CREATE", "result": "EQUAL", "create_revision": "0"},
+		},
+		"success": []map[string]interface{}{
+			{"request_put": map[string]string{
+				"key":   key,
+				"value": base64.StdEncoding.EncodeToString([]byte(l.value)),
+				"lease": l.leaseId,
+			}},
+		},
+		"failure": []map[string]interface{}{
+			{"request_range": map[string]string{"key": key}},
+		},
+	}
+	var response struct {
+		Succeeded bool `json:"succeeded"`
+		Responses []struct {
+			ResponseRange struct {
+				Kvs []struct {
+					Lease string `json:"lease"`
+				} `json:"kvs"`
+			} `json:"response_range"`
+		} `json:"responses"`
+	}
+	if err := l.post("/v3/kv/txn", request, &response); err != nil {
+		return false, err
+	}
+	if response.Succeeded {
+		return true, nil
+	}
+	for _, txnResponse := range response.Responses {
+		for _, kv := range txnResponse.ResponseRange.Kvs {
+			if kv.Lease == l.leaseId {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (l *EtcdLeaderLock) post(path string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	res, err := l.httpClient.Post(l.url+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd %s returned status %d", path, res.StatusCode)
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(response)
+}
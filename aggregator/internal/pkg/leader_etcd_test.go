@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeEtcd implements the parts of the etcd v3 JSON gateway used by EtcdLeaderLock.
+// Keys are kept base64 encoded, as they are sent.
+type fakeEtcd struct {
+	mutex       sync.Mutex
+	nextLeaseId int
+	leases      map[string]bool
+	keyLease    map[string]string
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{leases: make(map[string]bool), keyLease: make(map[string]string)}
+}
+
+// expireLease drops a lease and the keys bound to it, as etcd does when its TTL runs out
+func (e *fakeEtcd) expireLease(leaseId string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.leases, leaseId)
+	for key, keyLease := range e.keyLease {
+		if keyLease == leaseId {
+			delete(e.keyLease, key)
+		}
+	}
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var request map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var leaseId string
+	_ = json.Unmarshal(request["ID"], &leaseId)
+
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		e.nextLeaseId++
+		leaseId = strconv.Itoa(e.nextLeaseId)
+		e.leases[leaseId] = true
+		_ = json.NewEncoder(w).Encode(map[string]string{"ID": leaseId, "TTL": "10"})
+	case "/v3/lease/keepalive":
+		ttl := "0"
+		if e.leases[leaseId] {
+			ttl = "10"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]string{"ID": leaseId, "TTL": ttl}})
+	case "/v3/lease/revoke":
+		delete(e.leases, leaseId)
+		for key, keyLease := range e.keyLease {
+			if keyLease == leaseId {
+				delete(e.keyLease, key)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{})
+	case "/v3/kv/txn":
+		var txn struct {
+			Success []struct {
+				RequestPut struct {
+					Key   string `json:"key"`
+					Lease string `json:"lease"`
+				} `json:"request_put"`
+			} `json:"success"`
+		}
+		body, _ := json.Marshal(request)
+		_ = json.Unmarshal(body, &txn)
+		put := txn.Success[0].RequestPut
+		if holder, ok := e.keyLease[put.Key]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"succeeded": false,
+				"responses": []interface{}{map[string]interface{}{
+					"response_range": map[string]interface{}{"kvs": []interface{}{map[string]string{"lease": holder}}},
+				}},
+			})
+			return
+		}
+		e.keyLease[put.Key] = put.Lease
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"succeeded": true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEtcdLeaderLockIsExclusive(t *testing.T) {
+	etcd := newFakeEtcd()
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	first := NewEtcdLeaderLock(server.URL, "", "first", 0)
+	second := NewEtcdLeaderLock(server.URL, "", "second", 0)
+
+	if acquired, err := first.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("Expected the first instance to acquire the lock, got %v, %v", acquired, err)
+	}
+	if acquired, err := second.TryAcquire(); err != nil || acquired {
+		t.Fatalf("Expected the second instance to be a standby, got %v, %v", acquired, err)
+	}
+	// Renewals keep the lock
+	if acquired, err := first.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("Expected the first instance to renew the lock, got %v, %v", acquired, err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if acquired, err := second.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("Expected the second instance to take over after a release, got %v, %v", acquired, err)
+	}
+	if acquired, err := first.TryAcquire(); err != nil || acquired {
+		t.Fatalf("Expected the first instance to be a standby, got %v, %v", acquired, err)
+	}
+}
+
+func TestEtcdLeaderLockExpiredLease(t *testing.T) {
+	etcd := newFakeEtcd()
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	leader := NewEtcdLeaderLock(server.URL, "", "leader", 0)
+	standby := NewEtcdLeaderLock(server.URL, "", "standby", 0)
+
+	if acquired, err := leader.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("Expected the leader to acquire the lock, got %v, %v", acquired, err)
+	}
+	// The leader could not renew its lease in time
+	etcd.expireLease(leader.leaseId)
+
+	if acquired, err := standby.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("Expected the standby to take over an expired lease, got %v, %v", acquired, err)
+	}
+	if acquired, err := leader.TryAcquire(); err != nil || acquired {
+		t.Fatalf("Expected the old leader to notice it lost the lock, got %v, %v", acquired, err)
+	}
+}
+
+func TestEtcdLeaderLockUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	lock := NewEtcdLeaderLock(server.URL, "", "leader", 0)
+	if acquired, err := lock.TryAcquire(); err == nil || acquired {
+		t.Fatalf("Expected an error and no lock when etcd is unreachable, got %v, %v", acquired, err)
+	}
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+// neverLeaderLock is held by another instance
+type neverLeaderLock struct{}
+
+func (neverLeaderLock) TryAcquire() (bool, error) { return false, nil }
+func (neverLeaderLock) Release() error            { return nil }
+
+func newTestStandby(t *testing.T) *testAggregator {
+	t.Helper()
+	agg := newTestAggregator(t)
+	agg.leaderLease = NewLeaderLease(neverLeaderLock{}, agg.logger, nil)
+	agg.leaderLease.Renew()
+	return agg
+}
+
+func TestWaitForLeadershipLeader(t *testing.T) {
+	agg := newTestAggregator(t)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+
+	if !agg.waitForLeadership(0, batchIdentifierHash) {
+		t.Fatal("Expected the leader to respond the batch")
+	}
+}
+
+func TestWaitForLeadershipStandbyLeaderResponds(t *testing.T) {
+	agg := newTestStandby(t)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+	agg.reader.SetBatchResponded(batchIdentifierHash, true)
+
+	if agg.waitForLeadership(0, batchIdentifierHash) {
+		t.Fatal("Expected the standby to leave the batch to the leader")
+	}
+	if state, txHash := agg.taskProgress(t, 0); state != TaskStateConfirmed || txHash != nil {
+		t.Fatalf("Expected the task to be confirmed without a transaction, got %s with %v", state, txHash)
+	}
+}
+
+func TestWaitForLeadershipStandbyTimesOut(t *testing.T) {
+	timeout, pollInterval := StandbyResponseTimeout, StandbyResponsePollInterval
+	StandbyResponseTimeout, StandbyResponsePollInterval = 50*time.Millisecond, 10*time.Millisecond
+	t.Cleanup(func() { StandbyResponseTimeout, StandbyResponsePollInterval = timeout, pollInterval })
+
+	agg := newTestStandby(t)
+	batchIdentifierHash, _ := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+
+	if agg.waitForLeadership(0, batchIdentifierHash) {
+		t.Fatal("Expected the standby to give up")
+	}
+	if state, _ := agg.taskProgress(t, 0); state != TaskStateHandedToLeader {
+		t.Fatalf("Expected the task to be handed to the leader, got %s", state)
+	}
+	if responses := agg.writer.Responses(); len(responses) != 0 {
+		t.Fatalf("Expected no response to be sent, got %v", responses)
+	}
+}
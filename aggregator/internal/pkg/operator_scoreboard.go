@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// Number of tasks kept per operator in the scoreboard
+const operatorScoreboardTasks = 500
+
+// taskParticipation is the outcome of a task for an operator that was expected to sign it
+type taskParticipation struct {
+	signed bool
+	// From the aggregator seeing the task to receiving the signature
+	delay              time.Duration
+	invalidSubmissions int
+}
+
+type operatorRecord struct {
+	// Ring buffer with the last operatorScoreboardTasks tasks
+	tasks []taskParticipation
+	next  int
+	// Invalid submissions not yet attributed to a finished task
+	pendingInvalidSubmissions int
+}
+
+// OperatorScore summarizes the last tasks an operator was expected to sign
+type OperatorScore struct {
+	OperatorId         string  `json:"operator_id"`
+	TasksSeen          int     `json:"tasks_seen"`
+	TasksSigned        int     `json:"tasks_signed"`
+	ParticipationRate  float64 `json:"participation_rate"`
+	MedianDelaySeconds float64 `json:"median_delay_seconds"`
+	P99DelaySeconds    float64 `json:"p99_delay_seconds"`
+	InvalidSubmissions int     `json:"invalid_submissions"`
+}
+
+// OperatorScoreboard keeps a rolling record of the participation of each operator, so operators
+// that regularly miss batches or respond late can be told before quorum suffers.
+type OperatorScoreboard struct {
+	mutex     sync.Mutex
+	operators map[eigentypes.OperatorId]*operatorRecord
+}
+
+func NewOperatorScoreboard() *OperatorScoreboard {
+	return &OperatorScoreboard{
+		operators: make(map[eigentypes.OperatorId]*operatorRecord),
+	}
+}
+
+func (s *OperatorScoreboard) record(operatorId eigentypes.OperatorId) *operatorRecord {
+	record, ok := s.operators[operatorId]
+	if !ok {
+		record = &operatorRecord{}
+		s.operators[operatorId] = record
+	}
+	return record
+}
+
+// RecordTask adds a finished task to the record of every operator expected to sign it.
+// signers maps the operators that signed to when their signature was received.
+func (s *OperatorScoreboard) RecordTask(expected []eigentypes.OperatorId, signers map[eigentypes.OperatorId]time.Time, taskSeenAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, operatorId := range expected {
+		record := s.record(operatorId)
+		participation := taskParticipation{invalidSubmissions: record.pendingInvalidSubmissions}
+		record.pendingInvalidSubmissions = 0
+		if signedAt, ok := signers[operatorId]; ok {
+			participation.signed = true
+			participation.delay = signedAt.Sub(taskSeenAt)
+		}
+
+		if len(record.tasks) < operatorScoreboardTasks {
+			record.tasks = append(record.tasks, participation)
+		} else {
+			record.tasks[record.next] = participation
+		}
+		record.next = (record.next + 1) % operatorScoreboardTasks
+	}
+}
+
+// RecordInvalidSubmission counts a response with an invalid signature, it is added to the next task of the operator
+func (s *OperatorScoreboard) RecordInvalidSubmission(operatorId eigentypes.OperatorId) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.record(operatorId).pendingInvalidSubmissions++
+}
+
+// Score returns the score of the operator, and false if it has no record
+func (s *OperatorScoreboard) Score(operatorId eigentypes.OperatorId) (OperatorScore, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	record, ok := s.operators[operatorId]
+	if !ok {
+		return OperatorScore{}, false
+	}
+	return record.score(operatorId), true
+}
+
+// Scores returns the score of every operator, sorted by participation rate so the least reliable come first
+func (s *OperatorScoreboard) Scores() []OperatorScore {
+	s.mutex.Lock()
+	scores := make([]OperatorScore, 0, len(s.operators))
+	for operatorId, record := range s.operators {
+		scores = append(scores, record.score(operatorId))
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].ParticipationRate != scores[j].ParticipationRate {
+			return scores[i].ParticipationRate < scores[j].ParticipationRate
+		}
+		return scores[i].OperatorId < scores[j].OperatorId
+	})
+	return scores
+}
+
+func (r *operatorRecord) score(operatorId eigentypes.OperatorId) OperatorScore {
+	score := OperatorScore{
+		OperatorId:         "0x" + hex.EncodeToString(operatorId[:]),
+		TasksSeen:          len(r.tasks),
+		InvalidSubmissions: r.pendingInvalidSubmissions,
+	}
+
+	delays := make([]time.Duration, 0, len(r.tasks))
+	for _, participation := range r.tasks {
+		score.InvalidSubmissions += participation.invalidSubmissions
+		if participation.signed {
+			score.TasksSigned++
+			delays = append(delays, participation.delay)
+		}
+	}
+	if score.TasksSeen > 0 {
+		score.ParticipationRate = float64(score.TasksSigned) / float64(score.TasksSeen)
+	}
+
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	score.MedianDelaySeconds = percentile(delays, 0.5).Seconds()
+	score.P99DelaySeconds = percentile(delays, 0.99).Seconds()
+	return score
+}
+
+// percentile uses the nearest rank method, sorted must be in ascending order
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recordTaskParticipation adds the task to the scoreboard once it is finished, with every operator
+// registered at the task creation block as expected to sign it
+func (agg *Aggregator) recordTaskParticipation(taskIndex uint32) {
+	operatorsAvsState, err := agg.operatorsAvsState(taskIndex)
+	if err != nil {
+		agg.logger.Warn("Could not get operators state, task not added to the operator scoreboard", "taskIndex", taskIndex, "err", err)
+		return
+	}
+
+	agg.taskMutex.Lock()
+	progress, ok := agg.taskProgressByIdx[taskIndex]
+	if !ok {
+		agg.taskMutex.Unlock()
+		return
+	}
+	taskSeenAt := progress.createdAt
+	signers := make(map[eigentypes.OperatorId]time.Time, len(agg.signersByIdx[taskIndex]))
+	for operatorId, signedAt := range agg.signersByIdx[taskIndex] {
+		signers[operatorId] = signedAt
+	}
+	agg.taskMutex.Unlock()
+
+	expected := make([]eigentypes.OperatorId, 0, len(operatorsAvsState))
+	for operatorId := range operatorsAvsState {
+		expected = append(expected, operatorId)
+	}
+	agg.operatorScoreboard.RecordTask(expected, signers, taskSeenAt)
+
+	for _, operatorId := range expected {
+		agg.exportOperatorScore(operatorId)
+	}
+}
+
+func (agg *Aggregator) recordInvalidSubmission(operatorId eigentypes.OperatorId) {
+	agg.operatorScoreboard.RecordInvalidSubmission(operatorId)
+	agg.exportOperatorScore(operatorId)
+}
+
+func (agg *Aggregator) exportOperatorScore(operatorId eigentypes.OperatorId) {
+	if score, ok := agg.operatorScoreboard.Score(operatorId); ok {
+		agg.metrics.SetAggregatorOperatorScore(score.OperatorId, score.TasksSeen, score.TasksSigned,
+			score.MedianDelaySeconds, score.P99DelaySeconds, score.InvalidSubmissions)
+	}
+}
@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// recordTestTask records a task expected from the operators, signed by the signers after their delay
+func recordTestTask(scoreboard *OperatorScoreboard, expected []eigentypes.OperatorId, delays map[eigentypes.OperatorId]time.Duration) {
+	taskSeenAt := time.Now()
+	signers := make(map[eigentypes.OperatorId]time.Time, len(delays))
+	for operatorId, delay := range delays {
+		signers[operatorId] = taskSeenAt.Add(delay)
+	}
+	scoreboard.RecordTask(expected, signers, taskSeenAt)
+}
+
+func TestOperatorScoreDelays(t *testing.T) {
+	operatorId := eigentypes.OperatorId{1}
+	oneToHundred := make([]time.Duration, 100)
+	for i := range oneToHundred {
+		oneToHundred[i] = time.Duration(i+1) * time.Second
+	}
+
+	tests := []struct {
+		name string
+		// A negative delay is a task the operator didn't sign
+		delays         []time.Duration
+		expectedMedian time.Duration
+		expectedP99    time.Duration
+		expectedRate   float64
+	}{
+		{"no signatures", []time.Duration{-1, -1}, 0, 0, 0},
+		{"single signature", []time.Duration{3 * time.Second}, 3 * time.Second, 3 * time.Second, 1},
+		{"even count", []time.Duration{4 * time.Second, 1 * time.Second, 3 * time.Second, 2 * time.Second}, 2 * time.Second, 4 * time.Second, 1},
+		{"missed tasks are not delays", []time.Duration{2 * time.Second, -1, 1 * time.Second, -1}, 1 * time.Second, 2 * time.Second, 0.5},
+		{"one to hundred seconds", oneToHundred, 50 * time.Second, 99 * time.Second, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scoreboard := NewOperatorScoreboard()
+			for _, delay := range tt.delays {
+				delays := map[eigentypes.OperatorId]time.Duration{}
+				if delay >= 0 {
+					delays[operatorId] = delay
+				}
+				recordTestTask(scoreboard, []eigentypes.OperatorId{operatorId}, delays)
+			}
+
+			score, ok := scoreboard.Score(operatorId)
+			if !ok {
+				t.Fatalf("Expected the operator to have a score")
+			}
+			if score.TasksSeen != len(tt.delays) || score.ParticipationRate != tt.expectedRate {
+				t.Fatalf("Expected %d tasks seen with rate %v, got %d with %v", len(tt.delays), tt.expectedRate, score.TasksSeen, score.ParticipationRate)
+			}
+			if score.MedianDelaySeconds != tt.expectedMedian.Seconds() || score.P99DelaySeconds != tt.expectedP99.Seconds() {
+				t.Fatalf("Expected median %v and p99 %v, got %vs and %vs", tt.expectedMedian, tt.expectedP99, score.MedianDelaySeconds, score.P99DelaySeconds)
+			}
+		})
+	}
+}
+
+func TestOperatorScoreboardKeepsLastTasks(t *testing.T) {
+	scoreboard := NewOperatorScoreboard()
+	operatorId := eigentypes.OperatorId{1}
+	expected := []eigentypes.OperatorId{operatorId}
+
+	// The first tasks are signed, and are pushed out of the record by the missed ones
+	for i := 0; i < 10; i++ {
+		recordTestTask(scoreboard, expected, map[eigentypes.OperatorId]time.Duration{operatorId: time.Second})
+	}
+	for i := 0; i < operatorScoreboardTasks; i++ {
+		recordTestTask(scoreboard, expected, nil)
+	}
+
+	score, _ := scoreboard.Score(operatorId)
+	if score.TasksSeen != operatorScoreboardTasks || score.TasksSigned != 0 {
+		t.Fatalf("Expected the last %d tasks, none signed, got %d seen and %d signed", operatorScoreboardTasks, score.TasksSeen, score.TasksSigned)
+	}
+
+	// The ring buffer keeps overwriting the oldest task
+	recordTestTask(scoreboard, expected, map[eigentypes.OperatorId]time.Duration{operatorId: time.Second})
+	score, _ = scoreboard.Score(operatorId)
+	if score.TasksSeen != operatorScoreboardTasks || score.TasksSigned != 1 {
+		t.Fatalf("Expected %d tasks seen and 1 signed, got %d and %d", operatorScoreboardTasks, score.TasksSeen, score.TasksSigned)
+	}
+}
+
+func TestOperatorScoreboardScoresOrder(t *testing.T) {
+	scoreboard := NewOperatorScoreboard()
+	reliable, lateA, lateB := eigentypes.OperatorId{3}, eigentypes.OperatorId{1}, eigentypes.OperatorId{2}
+	expected := []eigentypes.OperatorId{reliable, lateB, lateA}
+
+	recordTestTask(scoreboard, expected, map[eigentypes.OperatorId]time.Duration{reliable: time.Second, lateA: time.Second, lateB: time.Second})
+	recordTestTask(scoreboard, expected, map[eigentypes.OperatorId]time.Duration{reliable: time.Second})
+
+	scores := scoreboard.Scores()
+	// Least reliable first, ties by operator id
+	expectedOrder := []string{"0x01", "0x02", "0x03"}
+	if len(scores) != len(expectedOrder) {
+		t.Fatalf("Expected %d scores, got %d", len(expectedOrder), len(scores))
+	}
+	for i, score := range scores {
+		if score.OperatorId[:4] != expectedOrder[i] {
+			t.Fatalf("Expected operator %s at position %d, got %s", expectedOrder[i], i, score.OperatorId)
+		}
+	}
+	if scores[0].ParticipationRate != 0.5 || scores[2].ParticipationRate != 1 {
+		t.Fatalf("Expected rates 0.5 and 1, got %v and %v", scores[0].ParticipationRate, scores[2].ParticipationRate)
+	}
+}
+
+func TestOperatorScoreboardInvalidSubmissions(t *testing.T) {
+	scoreboard := NewOperatorScoreboard()
+	operatorId := eigentypes.OperatorId{1}
+	expected := []eigentypes.OperatorId{operatorId}
+
+	// Counted before the operator has any task
+	scoreboard.RecordInvalidSubmission(operatorId)
+	scoreboard.RecordInvalidSubmission(operatorId)
+	if score, ok := scoreboard.Score(operatorId); !ok || score.InvalidSubmissions != 2 || score.TasksSeen != 0 {
+		t.Fatalf("Expected 2 pending invalid submissions, got %+v", score)
+	}
+
+	// Attributed to the next task, while new ones stay pending
+	recordTestTask(scoreboard, expected, nil)
+	scoreboard.RecordInvalidSubmission(operatorId)
+	if score, _ := scoreboard.Score(operatorId); score.InvalidSubmissions != 3 {
+		t.Fatalf("Expected 3 invalid submissions, got %d", score.InvalidSubmissions)
+	}
+	recordTestTask(scoreboard, expected, nil)
+
+	// They leave the record with their task
+	for i := 0; i < operatorScoreboardTasks-1; i++ {
+		recordTestTask(scoreboard, expected, nil)
+	}
+	if score, _ := scoreboard.Score(operatorId); score.InvalidSubmissions != 1 {
+		t.Fatalf("Expected the invalid submissions of the first task to be dropped with it, got %d", score.InvalidSubmissions)
+	}
+}
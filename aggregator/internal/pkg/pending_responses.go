@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+type pendingResponse struct {
+	signedTaskResponse types.SignedTaskResponse
+	receivedAt         time.Time
+}
+
+// PendingResponses holds the signed task responses that arrive before the aggregator sees their task,
+// keyed by batch identifier hash. Their signature can't be checked until then, so every response is kept
+// and checked when it is replayed. Responses older than the TTL are dropped.
+type PendingResponses struct {
+	mutex        sync.Mutex
+	ttl          time.Duration
+	maxResponses int
+	responses    map[[32]byte][]pendingResponse
+	count        int
+	lastSweep    time.Time
+}
+
+func NewPendingResponses(ttl time.Duration, maxResponses int) *PendingResponses {
+	return &PendingResponses{
+		ttl:          ttl,
+		maxResponses: maxResponses,
+		responses:    make(map[[32]byte][]pendingResponse),
+		lastSweep:    time.Now(),
+	}
+}
+
+// Add buffers the response, and returns false if the buffer is full
+func (p *PendingResponses) Add(signedTaskResponse *types.SignedTaskResponse, now time.Time) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if now.Sub(p.lastSweep) > p.ttl || p.count >= p.maxResponses {
+		p.sweep(now)
+	}
+	if p.count >= p.maxResponses {
+		return false
+	}
+
+	batchIdentifierHash := signedTaskResponse.BatchIdentifierHash
+	p.responses[batchIdentifierHash] = append(p.responses[batchIdentifierHash], pendingResponse{
+		signedTaskResponse: *signedTaskResponse,
+		receivedAt:         now,
+	})
+	p.count++
+	return true
+}
+
+// Take removes the responses of the batch, and returns the ones within the TTL
+func (p *PendingResponses) Take(batchIdentifierHash [32]byte, now time.Time) []types.SignedTaskResponse {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pending, ok := p.responses[batchIdentifierHash]
+	if !ok {
+		return nil
+	}
+	delete(p.responses, batchIdentifierHash)
+	p.count -= len(pending)
+
+	responses := make([]types.SignedTaskResponse, 0, len(pending))
+	for _, response := range pending {
+		if now.Sub(response.receivedAt) <= p.ttl {
+			responses = append(responses, response.signedTaskResponse)
+		}
+	}
+	return responses
+}
+
+// Len returns the number of buffered responses
+func (p *PendingResponses) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.count
+}
+
+// sweep drops the expired responses, and must be called while holding the mutex
+func (p *PendingResponses) sweep(now time.Time) {
+	for batchIdentifierHash, pending := range p.responses {
+		kept := pending[:0]
+		for _, response := range pending {
+			if now.Sub(response.receivedAt) <= p.ttl {
+				kept = append(kept, response)
+			}
+		}
+		p.count -= len(pending) - len(kept)
+		if len(kept) == 0 {
+			delete(p.responses, batchIdentifierHash)
+		} else {
+			p.responses[batchIdentifierHash] = kept
+		}
+	}
+	p.lastSweep = now
+}
+
+// taskIndexOrBuffer returns the index of the task of the response. If the aggregator has not seen the
+// task yet, the response is buffered instead, and buffered is false if the buffer is full.
+// Both happen under the task mutex, so AddNewTask can't miss a response buffered concurrently.
+func (agg *Aggregator) taskIndexOrBuffer(signedTaskResponse *types.SignedTaskResponse) (taskIndex uint32, found bool, buffered bool) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if taskIndex, ok := agg.batchesIdxByIdentifierHash[signedTaskResponse.BatchIdentifierHash]; ok {
+		return taskIndex, true, false
+	}
+	return 0, false, agg.pendingResponses.Add(signedTaskResponse, time.Now())
+}
+
+// replayPendingResponses processes the responses buffered before the task was added.
+// The operators were already answered, so the outcome is only logged.
+func (agg *Aggregator) replayPendingResponses(signedTaskResponses []types.SignedTaskResponse) {
+	for i := range signedTaskResponses {
+		go func(signedTaskResponse *types.SignedTaskResponse) {
+			reply := agg.processOperatorSignedTaskResponse(signedTaskResponse)
+			agg.logger.Info("Pending task response replayed",
+				"batchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
+				"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]),
+				"reply", types.SignedTaskResponseReplyString(reply))
+		}(&signedTaskResponses[i])
+	}
+}
@@ -0,0 +1,298 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Signatures queued for a task in its collection window. When full, the operator is told the task
+// is unknown and retries, which is fine as the window is short
+const postQuorumSignaturesBuffer = 64
+
+// postQuorumSignature is a signature received after the BLS aggregation service reached quorum
+type postQuorumSignature struct {
+	operatorId eigentypes.OperatorId
+	signature  bls.Signature
+}
+
+// postQuorumCollection adds signatures to an aggregated response. Each new signer is one less
+// non-signer in the response, which makes the transaction smaller and cheaper to verify.
+type postQuorumCollection struct {
+	operatorsAvsState map[eigentypes.OperatorId]eigentypes.OperatorAvsState
+	quorumNums        eigentypes.QuorumNums
+	// What the operators sign, the batch identifier hash
+	taskResponseDigest eigentypes.TaskResponseDigest
+	nonSigners         map[eigentypes.OperatorId]struct{}
+	signersAggSigG1    *bls.Signature
+	signersApkG2       *bls.G2Point
+	totalStake         map[eigentypes.QuorumNum]*big.Int
+	signedStake        map[eigentypes.QuorumNum]*big.Int
+	// Number of signatures added after reaching quorum
+	added int
+}
+
+// newPostQuorumCollection matches the non-signers of the response against the operators state at the task creation block
+func newPostQuorumCollection(response blsagg.BlsAggregationServiceResponse, operatorsAvsState map[eigentypes.OperatorId]eigentypes.OperatorAvsState, quorumNums eigentypes.QuorumNums) (*postQuorumCollection, error) {
+	collection := &postQuorumCollection{
+		operatorsAvsState:  operatorsAvsState,
+		quorumNums:         quorumNums,
+		taskResponseDigest: response.TaskResponseDigest,
+		nonSigners:         make(map[eigentypes.OperatorId]struct{}),
+		// Copies, the response keeps the original aggregate in case the collection is discarded
+		signersAggSigG1: bls.NewZeroSignature().Add(response.SignersAggSigG1),
+		signersApkG2:    bls.NewZeroG2Point().Add(response.SignersApkG2),
+		totalStake:      make(map[eigentypes.QuorumNum]*big.Int),
+		signedStake:     make(map[eigentypes.QuorumNum]*big.Int),
+	}
+
+	for _, nonSignerPubkey := range response.NonSignersPubkeysG1 {
+		found := false
+		for operatorId, operatorAvsState := range operatorsAvsState {
+			g1Pubkey := operatorAvsState.OperatorInfo.Pubkeys.G1Pubkey
+			if g1Pubkey != nil && g1Pubkey.G1Affine.Equal(nonSignerPubkey.G1Affine) {
+				collection.nonSigners[operatorId] = struct{}{}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("non-signer not found in the operators state")
+		}
+	}
+
+	for _, quorumNum := range quorumNums {
+		collection.totalStake[quorumNum] = new(big.Int)
+		collection.signedStake[quorumNum] = new(big.Int)
+	}
+	for operatorId, operatorAvsState := range operatorsAvsState {
+		_, nonSigner := collection.nonSigners[operatorId]
+		for quorumNum, stake := range operatorAvsState.StakePerQuorum {
+			if _, ok := collection.totalStake[quorumNum]; !ok {
+				continue
+			}
+			collection.totalStake[quorumNum].Add(collection.totalStake[quorumNum], stake)
+			if !nonSigner {
+				collection.signedStake[quorumNum].Add(collection.signedStake[quorumNum], stake)
+			}
+		}
+	}
+
+	return collection, nil
+}
+
+// add aggregates the signature if it comes from a non-signer and it verifies
+func (c *postQuorumCollection) add(signature postQuorumSignature) bool {
+	if _, ok := c.nonSigners[signature.operatorId]; !ok {
+		return false
+	}
+	operatorAvsState := c.operatorsAvsState[signature.operatorId]
+	g2Pubkey := operatorAvsState.OperatorInfo.Pubkeys.G2Pubkey
+	if g2Pubkey == nil || signature.signature.G1Point == nil {
+		return false
+	}
+	// Checked again, the response checks skip it when the operators state can't be fetched
+	verified, err := signature.signature.Verify(g2Pubkey, c.taskResponseDigest)
+	if err != nil || !verified {
+		return false
+	}
+
+	c.signersAggSigG1.Add(&signature.signature)
+	c.signersApkG2.Add(g2Pubkey)
+	delete(c.nonSigners, signature.operatorId)
+	for quorumNum, stake := range operatorAvsState.StakePerQuorum {
+		if signedStake, ok := c.signedStake[quorumNum]; ok {
+			signedStake.Add(signedStake, stake)
+		}
+	}
+	c.added++
+	return true
+}
+
+// signingStakePercentage returns the signed percentage of the stake of each quorum
+func (c *postQuorumCollection) signingStakePercentage() map[eigentypes.QuorumNum]float64 {
+	percentages := make(map[eigentypes.QuorumNum]float64, len(c.quorumNums))
+	for _, quorumNum := range c.quorumNums {
+		if c.totalStake[quorumNum].Sign() == 0 {
+			continue
+		}
+		percentage, _ := new(big.Rat).SetFrac(
+			new(big.Int).Mul(c.signedStake[quorumNum], big.NewInt(100)),
+			c.totalStake[quorumNum],
+		).Float64()
+		percentages[quorumNum] = percentage
+	}
+	return percentages
+}
+
+// done returns true once every operator signed, or every quorum reached the target stake percentage.
+// A zero target collects for the whole window.
+func (c *postQuorumCollection) done(targetStakePercentage uint8) bool {
+	if len(c.nonSigners) == 0 {
+		return true
+	}
+	if targetStakePercentage == 0 {
+		return false
+	}
+	for _, percentage := range c.signingStakePercentage() {
+		if percentage < float64(targetStakePercentage) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedNonSigners returns the non-signers sorted as the contract requires
+func (c *postQuorumCollection) sortedNonSigners() []eigentypes.OperatorId {
+	nonSigners := make([]eigentypes.OperatorId, 0, len(c.nonSigners))
+	for operatorId := range c.nonSigners {
+		nonSigners = append(nonSigners, operatorId)
+	}
+	sort.Slice(nonSigners, func(i, j int) bool {
+		return new(big.Int).SetBytes(nonSigners[i][:]).Cmp(new(big.Int).SetBytes(nonSigners[j][:])) < 0
+	})
+	return nonSigners
+}
+
+// offerPostQuorumSignature queues the signature if the task is in its post-quorum collection window
+func (agg *Aggregator) offerPostQuorumSignature(taskIndex uint32, signedTaskResponse *types.SignedTaskResponse) bool {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	signatures, ok := agg.postQuorumSignaturesByIdx[taskIndex]
+	if !ok {
+		return false
+	}
+	select {
+	case signatures <- postQuorumSignature{operatorId: signedTaskResponse.OperatorId, signature: signedTaskResponse.BlsSignature}:
+		return true
+	default:
+		return false
+	}
+}
+
+// collectPostQuorumSignatures keeps collecting signatures for up to `post_quorum_collection_window` after the
+// BLS aggregation service reaches quorum, or until every quorum reaches `post_quorum_target_stake_percentage`.
+// The new signers are added to the response. Any failure keeps the response as it was at quorum.
+func (agg *Aggregator) collectPostQuorumSignatures(response blsagg.BlsAggregationServiceResponse, taskCreatedBlock uint64) blsagg.BlsAggregationServiceResponse {
+	window := agg.AggregatorConfig.Aggregator.PostQuorumCollectionWindow
+	taskIndex := response.TaskIndex
+
+	// Registered first, the BLS aggregation service stops taking signatures as soon as it reaches quorum
+	var signatures chan postQuorumSignature
+	if window > 0 {
+		signatures = make(chan postQuorumSignature, postQuorumSignaturesBuffer)
+		agg.taskMutex.Lock()
+		agg.postQuorumSignaturesByIdx[taskIndex] = signatures
+		agg.taskMutex.Unlock()
+		defer func() {
+			agg.taskMutex.Lock()
+			delete(agg.postQuorumSignaturesByIdx, taskIndex)
+			agg.taskMutex.Unlock()
+		}()
+	}
+
+	operatorsAvsState, err := agg.operatorsAvsState(taskIndex)
+	if err != nil {
+		agg.logger.Warn("Could not get operators state, sending the response as it was at quorum", "taskIndex", taskIndex, "err", err)
+		return response
+	}
+	collection, err := newPostQuorumCollection(response, operatorsAvsState, agg.quorumNums)
+	if err != nil {
+		agg.logger.Warn("Could not match the non-signers, sending the response as it was at quorum", "taskIndex", taskIndex, "err", err)
+		return response
+	}
+
+	if window > 0 {
+		targetStakePercentage := agg.AggregatorConfig.Aggregator.PostQuorumTargetStakePercentage
+		timer := time.NewTimer(window)
+	collect:
+		for !collection.done(targetStakePercentage) {
+			select {
+			case <-timer.C:
+				break collect
+			case signature := <-signatures:
+				collection.add(signature)
+			}
+		}
+		timer.Stop()
+
+		// Signatures queued before the window closed, their operators were already told they were accepted
+		agg.taskMutex.Lock()
+		delete(agg.postQuorumSignaturesByIdx, taskIndex)
+		agg.taskMutex.Unlock()
+	drain:
+		for {
+			select {
+			case signature := <-signatures:
+				collection.add(signature)
+			default:
+				break drain
+			}
+		}
+	}
+
+	for quorumNum, percentage := range collection.signingStakePercentage() {
+		agg.metrics.SetAggregatorSigningStakePercentage(uint8(quorumNum), percentage)
+	}
+	if collection.added == 0 {
+		return response
+	}
+
+	nonSigners := collection.sortedNonSigners()
+	indices, err := agg.avsRegistryService.GetCheckSignaturesIndices(&bind.CallOpts{}, eigentypes.BlockNum(taskCreatedBlock), agg.quorumNums, nonSigners)
+	if err != nil {
+		agg.logger.Warn("Could not get check signatures indices, sending the response as it was at quorum", "taskIndex", taskIndex, "err", err)
+		return response
+	}
+
+	nonSignersPubkeysG1 := make([]*bls.G1Point, 0, len(nonSigners))
+	for _, operatorId := range nonSigners {
+		nonSignersPubkeysG1 = append(nonSignersPubkeysG1, operatorsAvsState[operatorId].OperatorInfo.Pubkeys.G1Pubkey)
+	}
+
+	extended := response
+	extended.NonSignersPubkeysG1 = nonSignersPubkeysG1
+	extended.SignersApkG2 = collection.signersApkG2
+	extended.SignersAggSigG1 = collection.signersAggSigG1
+	extended.NonSignerQuorumBitmapIndices = indices.NonSignerQuorumBitmapIndices
+	extended.QuorumApkIndices = indices.QuorumApkIndices
+	extended.TotalStakeIndices = indices.TotalStakeIndices
+	extended.NonSignerStakeIndices = indices.NonSignerStakeIndices
+
+	agg.logger.Info("Signatures added after reaching quorum", "taskIndex", taskIndex,
+		"signatures", collection.added, "nonSigners", len(nonSigners))
+	agg.metrics.AddAggregatorPostQuorumSignatures(collection.added)
+	return extended
+}
+
+// reportPostQuorumGasSaved simulates the response with and without the signatures added after reaching quorum
+func (agg *Aggregator) reportPostQuorumGasSaved(batchIdentifierHash [32]byte, batchData BatchData, atQuorum blsagg.BlsAggregationServiceResponse, extended blsagg.BlsAggregationServiceResponse) {
+	gasAtQuorum, err := agg.avsWriter.EstimateAggregatedResponseGas(batchData.BatchMerkleRoot, batchData.SenderAddress, toNonSignerStakesAndSignature(atQuorum))
+	if err != nil {
+		agg.logger.Debug("Could not estimate the gas of the response at quorum", "err", err)
+		return
+	}
+	gasExtended, err := agg.avsWriter.EstimateAggregatedResponseGas(batchData.BatchMerkleRoot, batchData.SenderAddress, toNonSignerStakesAndSignature(extended))
+	if err != nil {
+		agg.logger.Debug("Could not estimate the gas of the extended response", "err", err)
+		return
+	}
+	if gasExtended >= gasAtQuorum {
+		return
+	}
+
+	gasSaved := gasAtQuorum - gasExtended
+	agg.logger.Info("Gas saved by the signatures collected after reaching quorum",
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+		"gasSaved", gasSaved, "gasAtQuorum", gasAtQuorum)
+	agg.metrics.AddAggregatorPostQuorumGasSaved(gasSaved)
+}
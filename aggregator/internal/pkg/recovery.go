@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const (
+	// Number of blocks scanned for unresponded tasks when there is no stored task to start from
+	PendingTasksRecoveryBlocks = 100
+	ReplaySignatureTimeout     = 5 * time.Second
+)
+
+// RecoverPendingTasks rebuilds the tasks that were pending when the aggregator stopped.
+// Tasks are taken from the task store and from the unresponded NewBatchV3 events, initialized
+// again in the BLS aggregation service, and the stored signatures are replayed on them.
+// Must be called while Start is running, as replayed signatures may reach quorum.
+func (agg *Aggregator) RecoverPendingTasks() {
+	if agg.taskStore == nil {
+		return
+	}
+
+	storedTasks, err := agg.taskStore.Tasks()
+	if err != nil {
+		agg.logger.Error("Could not read stored tasks, skipping recovery", "err", err)
+		return
+	}
+
+	fromBlock := uint64(0)
+	for _, task := range storedTasks {
+		if fromBlock == 0 || uint64(task.TaskCreatedBlock) < fromBlock {
+			fromBlock = uint64(task.TaskCreatedBlock)
+		}
+
+		responded, err := agg.avsReader.IsBatchResponded(task.BatchIdentifierHash)
+		if err != nil {
+			agg.logger.Warn("Could not check if stored task was responded, recovering it anyway", "err", err)
+		} else if responded {
+			agg.forgetTask(task.BatchIdentifierHash)
+			continue
+		}
+
+		agg.AddNewTask(task.BatchMerkleRoot, task.SenderAddress, task.TaskCreatedBlock)
+	}
+
+	if fromBlock == 0 {
+		latestBlock, err := agg.AggregatorConfig.BaseConfig.EthRpcClient.BlockNumber(context.Background())
+		if err != nil {
+			agg.logger.Error("Could not get latest block, skipping recovery of unresponded tasks", "err", err)
+		} else if latestBlock > PendingTasksRecoveryBlocks {
+			fromBlock = latestBlock - PendingTasksRecoveryBlocks
+		}
+	}
+
+	if fromBlock != 0 {
+		unrespondedTasks, err := agg.avsReader.GetNotRespondedTasksFrom(fromBlock)
+		if err != nil {
+			agg.logger.Error("Could not get unresponded tasks", "fromBlock", fromBlock, "err", err)
+		}
+		for _, task := range unrespondedTasks {
+			agg.AddNewTask(task.BatchMerkleRoot, task.SenderAddress, task.TaskCreatedBlock)
+		}
+		agg.logger.Info("Unresponded tasks recovered from chain", "fromBlock", fromBlock, "tasks", len(unrespondedTasks))
+	}
+
+	for _, task := range storedTasks {
+		agg.replayStoredSignatures(task.BatchIdentifierHash)
+	}
+}
+
+func (agg *Aggregator) replayStoredSignatures(batchIdentifierHash [32]byte) {
+	agg.taskMutex.Lock()
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	agg.taskMutex.Unlock()
+	if !ok {
+		// The task was responded and not recovered
+		return
+	}
+
+	signatures, err := agg.taskStore.Signatures(batchIdentifierHash)
+	if err != nil {
+		agg.logger.Error("Could not read stored signatures", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+		return
+	}
+
+	for _, signedTaskResponse := range signatures {
+		ctx, cancel := context.WithTimeout(context.Background(), ReplaySignatureTimeout)
+		err := agg.blsAggregationService.ProcessNewSignature(
+			ctx, taskIndex, signedTaskResponse.BatchIdentifierHash,
+			&signedTaskResponse.BlsSignature, signedTaskResponse.OperatorId,
+		)
+		cancel()
+		if err != nil {
+			agg.logger.Warn("Could not replay stored signature", "operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]), "err", err)
+			continue
+		}
+		agg.addSigner(taskIndex, signedTaskResponse.OperatorId)
+	}
+
+	agg.logger.Info("Stored signatures replayed", "taskIndex", taskIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "signatures", len(signatures))
+}
+
+// persistTask stores the task metadata, if the task store is enabled
+func (agg *Aggregator) persistTask(task StoredTask) {
+	if agg.taskStore == nil {
+		return
+	}
+	if err := agg.taskStore.PutTask(task); err != nil {
+		agg.logger.Error("Could not persist task", "batchIdentifierHash", "0x"+hex.EncodeToString(task.BatchIdentifierHash[:]), "err", err)
+	}
+}
+
+// forgetTask removes the task and its signatures from the task store, if it is enabled
+func (agg *Aggregator) forgetTask(batchIdentifierHash [32]byte) {
+	if agg.taskStore == nil {
+		return
+	}
+	if err := agg.taskStore.DeleteTask(batchIdentifierHash); err != nil {
+		agg.logger.Error("Could not remove stored task", "batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "err", err)
+	}
+}
+
+// persistSignature stores an operator signature accepted by the BLS aggregation service, if the task store is enabled
+func (agg *Aggregator) persistSignature(signedTaskResponse types.SignedTaskResponse) {
+	if agg.taskStore == nil {
+		return
+	}
+	if err := agg.taskStore.PutSignature(signedTaskResponse); err != nil {
+		agg.logger.Error("Could not persist signature", "batchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]), "err", err)
+	}
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestRecoverPendingTasks(t *testing.T) {
+	storePath := t.TempDir()
+	pendingRoot, respondedRoot, unstoredRoot := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	senderAddress := [20]byte{1}
+
+	// The first aggregator stores two tasks and a signature for each, and stops
+	agg := newTestAggregator(t)
+	taskStore, err := NewTaskStore(storePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	agg.taskStore = taskStore
+	operator := agg.newTestOperator(t)
+	for _, batchMerkleRoot := range [][32]byte{pendingRoot, respondedRoot} {
+		agg.AddNewTask(batchMerkleRoot, senderAddress, 10)
+		authenticated, err := types.SignSignedTaskResponse(operator.sign(batchMerkleRoot, senderAddress), operator.ecdsaKey, time.Now())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if reply := agg.submitSignedTaskResponse("127.0.0.1", &authenticated.SignedTaskResponse, authenticated); reply != types.SignedTaskResponseAccepted {
+			t.Fatalf("Expected the signature to be accepted, got %q", types.SignedTaskResponseReplyString(reply))
+		}
+	}
+	if err := taskStore.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// While it was down, one of the batches was responded and a new one was created
+	restarted := newTestAggregator(t)
+	if restarted.taskStore, err = NewTaskStore(storePath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = restarted.taskStore.Close() })
+	respondedHash := fakes.BatchIdentifierHash(respondedRoot, senderAddress)
+	restarted.reader.SetBatchResponded(respondedHash, true)
+	restarted.reader.AddTask(servicemanager.ContractAlignedLayerServiceManagerNewBatchV3{
+		BatchMerkleRoot: unstoredRoot,
+		SenderAddress:   senderAddress,
+		Raw:             ethtypes.Log{BlockNumber: 12},
+	})
+
+	restarted.RecoverPendingTasks()
+
+	restarted.taskMutex.Lock()
+	pendingIdx, pendingTracked := restarted.batchesIdxByIdentifierHash[fakes.BatchIdentifierHash(pendingRoot, senderAddress)]
+	_, respondedTracked := restarted.batchesIdxByIdentifierHash[respondedHash]
+	_, unstoredTracked := restarted.batchesIdxByIdentifierHash[fakes.BatchIdentifierHash(unstoredRoot, senderAddress)]
+	restarted.taskMutex.Unlock()
+	if !pendingTracked || !unstoredTracked {
+		t.Fatalf("Expected the stored and the unresponded tasks to be recovered")
+	}
+	if respondedTracked {
+		t.Fatalf("Expected the responded task not to be recovered")
+	}
+
+	signatures := restarted.bls.Signatures(pendingIdx)
+	if len(signatures) != 1 || signatures[0] != operator.id {
+		t.Fatalf("Expected the stored signature to be replayed, got %v", signatures)
+	}
+	restarted.taskMutex.Lock()
+	_, signed := restarted.signersByIdx[pendingIdx][operator.id]
+	restarted.taskMutex.Unlock()
+	if !signed {
+		t.Fatalf("Expected the operator to be a signer of the recovered task")
+	}
+
+	storedTasks, err := restarted.taskStore.Tasks()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, task := range storedTasks {
+		if task.BatchIdentifierHash == respondedHash {
+			t.Fatalf("Expected the responded task to be removed from the store")
+		}
+	}
+}
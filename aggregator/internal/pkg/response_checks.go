@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const operatorsAvsStateTimeout = 10 * time.Second
+
+// checkSignedTaskResponse runs the checks that don't need the BLS aggregation service: the response is not
+// a duplicate, the operator is registered at the task creation block, and the signature verifies against its
+// G2 pubkey. If the operators state can't be fetched, the last two are left to the BLS aggregation service.
+func (agg *Aggregator) checkSignedTaskResponse(taskIndex uint32, signedTaskResponse *types.SignedTaskResponse) uint8 {
+	agg.taskMutex.Lock()
+	_, duplicate := agg.signersByIdx[taskIndex][signedTaskResponse.OperatorId]
+	agg.taskMutex.Unlock()
+	if duplicate {
+		return types.SignedTaskResponseDuplicate
+	}
+
+	operatorsAvsState, err := agg.operatorsAvsState(taskIndex)
+	if err != nil {
+		agg.logger.Warn("Could not get operators state, leaving the checks to the BLS aggregation service",
+			"taskIndex", taskIndex, "err", err)
+		return types.SignedTaskResponseAccepted
+	}
+
+	operatorAvsState, ok := operatorsAvsState[signedTaskResponse.OperatorId]
+	if !ok || operatorAvsState.OperatorInfo.Pubkeys.G2Pubkey == nil {
+		return types.SignedTaskResponseUnknownOperator
+	}
+
+	if signedTaskResponse.BlsSignature.G1Point == nil {
+		return types.SignedTaskResponseInvalidSignature
+	}
+	verified, err := signedTaskResponse.BlsSignature.Verify(operatorAvsState.OperatorInfo.Pubkeys.G2Pubkey, signedTaskResponse.BatchIdentifierHash)
+	if err != nil || !verified {
+		return types.SignedTaskResponseInvalidSignature
+	}
+
+	return types.SignedTaskResponseAccepted
+}
+
+// operatorsAvsState returns the operators state at the task creation block, fetching it on the first call for the task
+func (agg *Aggregator) operatorsAvsState(taskIndex uint32) (map[eigentypes.OperatorId]eigentypes.OperatorAvsState, error) {
+	agg.taskMutex.Lock()
+	operatorsAvsState, ok := agg.operatorsAvsStateByIdx[taskIndex]
+	taskCreatedBlock := agg.batchCreatedBlockByIdx[taskIndex]
+	agg.taskMutex.Unlock()
+	if ok {
+		return operatorsAvsState, nil
+	}
+
+	// Fetched without holding the mutex, concurrent first responses may fetch it more than once
+	ctx, cancel := context.WithTimeout(context.Background(), operatorsAvsStateTimeout)
+	defer cancel()
+	operatorsAvsState, err := agg.avsRegistryService.GetOperatorsAvsStateAtBlock(ctx, agg.quorumNums, eigentypes.BlockNum(taskCreatedBlock))
+	if err != nil {
+		return nil, err
+	}
+
+	agg.taskMutex.Lock()
+	// The task may have been cleaned up in the meantime
+	if _, ok := agg.batchesIdentifierHashByIdx[taskIndex]; ok {
+		agg.operatorsAvsStateByIdx[taskIndex] = operatorsAvsState
+	}
+	agg.taskMutex.Unlock()
+
+	return operatorsAvsState, nil
+}
+
+// addSigner marks the operator as a signer of the task, keeping when it responded. Returns false if it already was.
+func (agg *Aggregator) addSigner(taskIndex uint32, operatorId eigentypes.OperatorId) bool {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	signers, ok := agg.signersByIdx[taskIndex]
+	if !ok {
+		signers = make(map[eigentypes.OperatorId]time.Time)
+		agg.signersByIdx[taskIndex] = signers
+	}
+	if _, ok := signers[operatorId]; ok {
+		return false
+	}
+	signers[operatorId] = time.Now()
+	return true
+}
+
+// removeSigner undoes addSigner when the BLS aggregation service rejects the signature
+func (agg *Aggregator) removeSigner(taskIndex uint32, operatorId eigentypes.OperatorId) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if signers, ok := agg.signersByIdx[taskIndex]; ok {
+		delete(signers, operatorId)
+	}
+}
+
+// blsErrorToReply maps the errors returned by ProcessNewSignature to a reply. Operators outside the task
+// quorums are rejected by checkSignedTaskResponse before reaching the BLS aggregation service. If it could
+// not check them, the service error is an internal error, and the operator retry is checked again.
+func blsErrorToReply(err error) uint8 {
+	switch {
+	case errors.Is(err, blsagg.IncorrectSignatureError):
+		return types.SignedTaskResponseInvalidSignature
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.SignedTaskResponseTimeout
+	case errors.Is(err, ErrBlsTaskNotFound):
+		return types.SignedTaskResponseUnknownTask
+	case errors.Is(err, ErrBlsDuplicateSignature):
+		return types.SignedTaskResponseDuplicate
+	default:
+		return types.SignedTaskResponseInternalError
+	}
+}
@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestBlsErrorToReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		reply uint8
+	}{
+		{"incorrect signature", blsagg.IncorrectSignatureError, types.SignedTaskResponseInvalidSignature},
+		{"wrapped incorrect signature", fmt.Errorf("could not verify: %w", blsagg.IncorrectSignatureError), types.SignedTaskResponseInvalidSignature},
+		{"deadline exceeded", context.DeadlineExceeded, types.SignedTaskResponseTimeout},
+		{"wrapped deadline exceeded", fmt.Errorf("could not process: %w", context.DeadlineExceeded), types.SignedTaskResponseTimeout},
+		{"task not found", ErrBlsTaskNotFound, types.SignedTaskResponseUnknownTask},
+		{"wrapped task not found", fmt.Errorf("task 3: %w", ErrBlsTaskNotFound), types.SignedTaskResponseUnknownTask},
+		{"duplicate signature", ErrBlsDuplicateSignature, types.SignedTaskResponseDuplicate},
+		{"wrapped duplicate signature", fmt.Errorf("task 3: %w", ErrBlsDuplicateSignature), types.SignedTaskResponseDuplicate},
+		// Only the wrapper sentinels are matched, the messages of the service errors are not
+		{"service task not found", blsagg.TaskNotFoundErrorFn(3), types.SignedTaskResponseInternalError},
+		{"other error", errors.New("could not get operator state"), types.SignedTaskResponseInternalError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if reply := blsErrorToReply(test.err); reply != test.reply {
+				t.Errorf("Expected reply %q, got %q", types.SignedTaskResponseReplyString(test.reply), types.SignedTaskResponseReplyString(reply))
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package pkg
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// Buckets unused for this long are dropped, so the limiter doesn't grow with every address it sees
+const rateLimiterIdleTimeout = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// rateLimiter is a token bucket per key, refilled at rate tokens per second up to burst.
+// A nil rateLimiter allows everything.
+type rateLimiter struct {
+	rate        float64
+	burst       float64
+	mutex       sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+// newRateLimiter returns nil if ratePerSecond is not positive, which disables the limit
+func newRateLimiter(ratePerSecond float64, burst int) *rateLimiter {
+	if ratePerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:        ratePerSecond,
+		burst:       float64(burst),
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of key, and returns false if it is empty
+func (l *rateLimiter) Allow(key string) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastCleanup) > rateLimiterIdleTimeout {
+		for bucketKey, bucket := range l.buckets {
+			if now.Sub(bucket.lastSeen) > rateLimiterIdleTimeout {
+				delete(l.buckets, bucketKey)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.lastSeen).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// submissionPool processes the signed task responses with a fixed number of workers, so a burst
+// of requests can't start an unbounded number of BLS verifications
+type submissionPool struct {
+	jobs chan func()
+}
+
+func newSubmissionPool(workers int, queueSize int) *submissionPool {
+	pool := &submissionPool{jobs: make(chan func(), queueSize)}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range pool.jobs {
+				job()
+			}
+		}()
+	}
+	return pool
+}
+
+// Submit queues the job, and returns false without running it if the queue is full
+func (p *submissionPool) Submit(job func()) bool {
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+var errRpcRequestTooLarge = errors.New("rpc request too large")
+
+// limitedReader fails once more than remaining bytes are read, the codec resets it on every request
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, errRpcRequestTooLarge
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// limitedGobServerCodec is the net/rpc gob codec with a size limit per request.
+// A request over the limit closes the connection.
+type limitedGobServerCodec struct {
+	rwc            io.ReadWriteCloser
+	reader         *limitedReader
+	maxRequestSize int64
+	dec            *gob.Decoder
+	enc            *gob.Encoder
+	encBuf         *bufio.Writer
+	closed         bool
+}
+
+func newLimitedGobServerCodec(conn io.ReadWriteCloser, maxRequestSize int64) *limitedGobServerCodec {
+	reader := &limitedReader{reader: conn, remaining: maxRequestSize}
+	encBuf := bufio.NewWriter(conn)
+	return &limitedGobServerCodec{
+		rwc:            conn,
+		reader:         reader,
+		maxRequestSize: maxRequestSize,
+		dec:            gob.NewDecoder(reader),
+		enc:            gob.NewEncoder(encBuf),
+		encBuf:         encBuf,
+	}
+}
+
+func (c *limitedGobServerCodec) ReadRequestHeader(r *rpc.Request) error {
+	c.reader.remaining = c.maxRequestSize
+	return c.dec.Decode(r)
+}
+
+func (c *limitedGobServerCodec) ReadRequestBody(body interface{}) error {
+	return c.dec.Decode(body)
+}
+
+func (c *limitedGobServerCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	if err := c.enc.Encode(r); err != nil {
+		if c.encBuf.Flush() == nil {
+			_ = c.Close()
+		}
+		return err
+	}
+	if err := c.enc.Encode(body); err != nil {
+		if c.encBuf.Flush() == nil {
+			_ = c.Close()
+		}
+		return err
+	}
+	return c.encBuf.Flush()
+}
+
+func (c *limitedGobServerCodec) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.rwc.Close()
+}
@@ -3,82 +3,283 @@ package pkg
 import (
 	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/rpc"
 	"time"
 
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/yetanotherco/aligned_layer/core/types"
 )
 
-const waitForEventRetries = 50
-const waitForEventSleepSeconds = 4 * time.Second
+const blsSignatureTimeout = 5 * time.Second
 
-func (agg *Aggregator) ServeOperators() error {
-	// Registers a new RPC server
-	err := rpc.Register(agg)
-	if err != nil {
-		return err
-	}
+// Signed requests older or newer than this are rejected, so a captured request can't be replayed later
+const maxSignedRequestClockSkew = 5 * time.Minute
 
-	// Registers an HTTP handler for RPC messages
-	rpc.HandleHTTP()
+// The operator address could not be read. The request may be authentic, so the operator is asked to retry
+var errOperatorAddressLookup = errors.New("could not get operator address")
 
+func (agg *Aggregator) ServeOperators() error {
 	// Start listening for requests on aggregator address
-	// ServeOperators accepts incoming HTTP connections on the listener, creating
-	// a new service goroutine for each. The service goroutines read requests
-	// and then call handler to reply to them
+	// ServeOperators accepts incoming HTTP connections on the listener, and each connection
+	// gets its own RPC server, so requests can be limited by the address they come from
+	server := http.Server{
+		Addr:              agg.AggregatorConfig.Aggregator.ServerIpPortAddress,
+		Handler:           http.HandlerFunc(agg.serveRpcConnection),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         agg.serverTlsConfig,
+	}
+
 	agg.logger.Info("Starting RPC server on address", "address",
-		agg.AggregatorConfig.Aggregator.ServerIpPortAddress)
+		agg.AggregatorConfig.Aggregator.ServerIpPortAddress, "tls", agg.serverTlsConfig != nil)
 
-	err = http.ListenAndServe(agg.AggregatorConfig.Aggregator.ServerIpPortAddress, nil)
+	if agg.serverTlsConfig != nil {
+		// The certificates are already in the TLS config
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
+}
 
-	return err
+// serveRpcConnection does the net/rpc HTTP CONNECT handshake, then serves the connection
+// with a size limit per request
+func (agg *Aggregator) serveRpcConnection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect || r.URL.Path != rpc.DefaultRPCPath {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = io.WriteString(w, "405 must CONNECT\n")
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		agg.logger.Warn("Could not hijack RPC connection", "remoteAddr", r.RemoteAddr, "err", err)
+		return
+	}
+	_, _ = io.WriteString(conn, "HTTP/1.0 200 Connected to Go RPC\n\n")
+
+	server := rpc.NewServer()
+	err = server.RegisterName("Aggregator", &OperatorRpcConnection{agg: agg, remoteIp: remoteIp(r.RemoteAddr)})
+	if err != nil {
+		agg.logger.Error("Could not register RPC methods", "err", err)
+		_ = conn.Close()
+		return
+	}
+	server.ServeCodec(newLimitedGobServerCodec(conn, agg.AggregatorConfig.Aggregator.RpcMaxRequestSize))
+}
+
+// OperatorRpcConnection holds the methods the Aggregator exposes to the Operator over net/rpc.
+// There is one per connection, so requests know the address they come from.
+type OperatorRpcConnection struct {
+	agg      *Aggregator
+	remoteIp string
 }
 
 // Aggregator Methods
 // This is the list of methods that the Aggregator exposes to the Operator
 // The Operator can call these methods to interact with the Aggregator
-// This methods are automatically registered by the RPC server
 // This takes a response an adds it to the internal. If reaching the quorum, it sends the aggregated signatures to ethereum
-// Returns:
+// Returns one of the types.SignedTaskResponse* replies:
 //   - 0: Success
-//   - 1: Error
-func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+//   - 1: Internal error
+//   - 2: Unknown task
+//   - 3: Invalid signature
+//   - 4: Duplicate
+//   - 5: Timeout
+//   - 6: Unknown operator
+//   - 7: Unauthorized
+//   - 8: Rate limited
+//   - 9: Accepted, pending until the aggregator sees the task
+//
+// Unsigned requests are rejected as unauthorized if `require_signed_requests` is set, use V3 instead
+func (c *OperatorRpcConnection) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	*reply = c.agg.submitSignedTaskResponse(c.remoteIp, signedTaskResponse, nil)
+	return nil
+}
+
+// ProcessOperatorSignedTaskResponseV3 is ProcessOperatorSignedTaskResponseV2 with the request signed by the
+// operator ECDSA key. The signer must be the address the operator registered with.
+func (c *OperatorRpcConnection) ProcessOperatorSignedTaskResponseV3(authenticatedSignedTaskResponse *types.AuthenticatedSignedTaskResponse, reply *uint8) error {
+	*reply = c.agg.submitSignedTaskResponse(c.remoteIp, &authenticatedSignedTaskResponse.SignedTaskResponse, authenticatedSignedTaskResponse)
+	return nil
+}
+
+// Dummy method to check if the server is running
+// TODO: Remove this method in prod
+func (c *OperatorRpcConnection) ServerRunning(_ *struct{}, reply *int64) error {
+	*reply = 1
+	return nil
+}
+
+// submitSignedTaskResponse applies the rate limits and the request authentication, then processes
+// the response in the worker pool. authenticated is nil for unsigned requests.
+func (agg *Aggregator) submitSignedTaskResponse(remoteIp string, signedTaskResponse *types.SignedTaskResponse, authenticated *types.AuthenticatedSignedTaskResponse) uint8 {
+	if !agg.ipRateLimiter.Allow(remoteIp) {
+		agg.logger.Warn("Task response rate limited", "remoteIp", remoteIp)
+		return types.SignedTaskResponseRateLimited
+	}
+
+	if authenticated != nil {
+		if err := agg.authenticateSignedTaskResponse(authenticated); errors.Is(err, errOperatorAddressLookup) {
+			agg.logger.Error("Could not authenticate task response", "remoteIp", remoteIp,
+				"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]), "err", err)
+			agg.auditSignedTaskResponse(signedTaskResponse, types.SignedTaskResponseInternalError)
+			return types.SignedTaskResponseInternalError
+		} else if err != nil {
+			agg.logger.Warn("Task response rejected, invalid request signature", "remoteIp", remoteIp,
+				"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]), "err", err)
+			agg.auditSignedTaskResponse(signedTaskResponse, types.SignedTaskResponseUnauthorized)
+			return types.SignedTaskResponseUnauthorized
+		}
+	} else if agg.AggregatorConfig.Aggregator.RequireSignedRequests {
+		agg.logger.Warn("Task response rejected, request is not signed", "remoteIp", remoteIp,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
+		agg.auditSignedTaskResponse(signedTaskResponse, types.SignedTaskResponseUnauthorized)
+		return types.SignedTaskResponseUnauthorized
+	}
+
+	if !agg.operatorRateLimiter.Allow(string(signedTaskResponse.OperatorId[:])) {
+		agg.logger.Warn("Task response rate limited", "remoteIp", remoteIp,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
+		return types.SignedTaskResponseRateLimited
+	}
+
+	// Buffered, so the worker finishes even if nobody reads the reply
+	done := make(chan uint8, 1)
+	if !agg.submissionPool.Submit(func() { done <- agg.processOperatorSignedTaskResponse(signedTaskResponse) }) {
+		agg.logger.Warn("Task response rejected, the worker queue is full", "remoteIp", remoteIp)
+		return types.SignedTaskResponseRateLimited
+	}
+	return <-done
+}
+
+// authenticateSignedTaskResponse checks the request is recent and signed by the ECDSA key the operator registered with
+func (agg *Aggregator) authenticateSignedTaskResponse(authenticated *types.AuthenticatedSignedTaskResponse) error {
+	skew := time.Since(time.Unix(authenticated.Timestamp, 0))
+	if skew > maxSignedRequestClockSkew || skew < -maxSignedRequestClockSkew {
+		return fmt.Errorf("request timestamp is %s away from the aggregator clock", skew.Round(time.Second))
+	}
+
+	signer, err := authenticated.Signer()
+	if err != nil {
+		return err
+	}
+	operatorAddress, err := agg.operatorAddress(authenticated.SignedTaskResponse.OperatorId)
+	if err != nil {
+		return err
+	}
+	if signer != operatorAddress {
+		return fmt.Errorf("request signed by %s, operator is %s", signer.Hex(), operatorAddress.Hex())
+	}
+	return nil
+}
+
+// operatorAddress returns the address the operator registered with. Operators can't change it, so it is cached.
+// Errors reading it wrap errOperatorAddressLookup, and are not cached.
+func (agg *Aggregator) operatorAddress(operatorId eigentypes.OperatorId) (common.Address, error) {
+	agg.operatorAddressMutex.Lock()
+	operatorAddress, ok := agg.operatorAddressById[operatorId]
+	agg.operatorAddressMutex.Unlock()
+	if ok {
+		return operatorAddress, nil
+	}
+
+	operatorAddress, err := agg.avsReader.GetOperatorFromId(&bind.CallOpts{}, operatorId)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", errOperatorAddressLookup, err)
+	}
+	if operatorAddress == (common.Address{}) {
+		return common.Address{}, errors.New("operator is not registered")
+	}
+
+	agg.operatorAddressMutex.Lock()
+	agg.operatorAddressById[operatorId] = operatorAddress
+	agg.operatorAddressMutex.Unlock()
+	return operatorAddress, nil
+}
+
+// remoteIp strips the port from a request remote address
+func remoteIp(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// processOperatorSignedTaskResponse is shared by the net/rpc and JSON-RPC endpoints, and records the reply in the audit log
+func (agg *Aggregator) processOperatorSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse) uint8 {
+	reply := agg.aggregateSignedTaskResponse(signedTaskResponse)
+	agg.auditSignedTaskResponse(signedTaskResponse, reply)
+	return reply
+}
+
+// aggregateSignedTaskResponse checks the response and sends its signature to the BLS aggregation service.
+// Cheap checks run first, so invalid responses never reach the BLS aggregation service.
+// The task mutex is only held to read and update the task maps.
+func (agg *Aggregator) aggregateSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse) uint8 {
 	agg.AggregatorConfig.BaseConfig.Logger.Info("New task response",
 		"BatchMerkleRoot", "0x"+hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
 		"SenderAddress", "0x"+hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
 		"BatchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
 		"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
-	taskIndex := uint32(0)
-	ok := false
-
-	for i := 0; i < waitForEventRetries; i++ {
-		agg.taskMutex.Lock()
-		agg.AggregatorConfig.BaseConfig.Logger.Info("- Locked Resources: Starting processing of Response")
-		taskIndex, ok = agg.batchesIdxByIdentifierHash[signedTaskResponse.BatchIdentifierHash]
-		if !ok {
-			agg.taskMutex.Unlock()
-			agg.logger.Info("- Unlocked Resources: Task not found in the internal map")
-			time.Sleep(waitForEventSleepSeconds)
-		} else {
-			break
+
+	// Operators may see a new batch before the aggregator does. Their responses are buffered,
+	// and processed once AddNewTask registers the batch
+	taskIndex, found, buffered := agg.taskIndexOrBuffer(signedTaskResponse)
+	if !found {
+		if !buffered {
+			agg.logger.Warn("Task not found and the pending response buffer is full, the operator may retry later",
+				"BatchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]))
+			return types.SignedTaskResponseUnknownTask
 		}
+		agg.logger.Info("Task not found yet, response buffered until the task is added",
+			"BatchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]))
+		return types.SignedTaskResponseAcceptedPending
 	}
 
-	if !ok {
-		agg.logger.Warn("Task not found in the internal map, operator signature will be lost. Batch may not reach quorum")
-		*reply = 1
-		return nil
+	reply := agg.checkSignedTaskResponse(taskIndex, signedTaskResponse)
+	if reply == types.SignedTaskResponseInvalidSignature {
+		agg.recordInvalidSubmission(signedTaskResponse.OperatorId)
+	}
+	if reply != types.SignedTaskResponseAccepted {
+		agg.logger.Warn("Task response rejected",
+			"reason", types.SignedTaskResponseReplyString(reply),
+			"taskIndex", taskIndex,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
+		return reply
+	}
+
+	// Reserve the operator slot, so concurrent duplicates are rejected while this one is processed
+	if !agg.addSigner(taskIndex, signedTaskResponse.OperatorId) {
+		agg.logger.Info("Duplicate task response", "taskIndex", taskIndex,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
+		return types.SignedTaskResponseDuplicate
+	}
+	agg.telemetry.LogOperatorResponse(signedTaskResponse.BatchMerkleRoot, signedTaskResponse.OperatorId, signedTaskResponse.TraceContext)
+
+	// Quorum was already reached, the signature is added to the response before it is sent
+	if agg.offerPostQuorumSignature(taskIndex, signedTaskResponse) {
+		agg.logger.Info("Signature queued in the post-quorum collection window", "taskIndex", taskIndex,
+			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
+		return types.SignedTaskResponseAccepted
 	}
-	agg.telemetry.LogOperatorResponse(signedTaskResponse.BatchMerkleRoot, signedTaskResponse.OperatorId)
 
 	// Don't wait infinitely if it can't answer
-	// Create a context with a timeout of 5 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel() // Ensure the cancel function is called to release resources
+	ctx, cancel := context.WithTimeout(context.Background(), blsSignatureTimeout)
+	defer cancel()
 
-	// Create a channel to signal when the task is done
-	done := make(chan struct{})
+	// Buffered, so the goroutine finishes even if the response timed out
+	done := make(chan uint8, 1)
 
 	agg.logger.Info("Starting bls signature process")
 	go func() {
@@ -88,36 +289,34 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 		)
 
 		if err != nil {
+			reply := blsErrorToReply(err)
+			// The task may have reached quorum while this signature was on its way. If the service finished
+			// it before its response was read, the error is not ErrBlsTaskNotFound but an internal error
+			if (reply == types.SignedTaskResponseUnknownTask || reply == types.SignedTaskResponseInternalError) &&
+				agg.offerPostQuorumSignature(taskIndex, signedTaskResponse) {
+				done <- types.SignedTaskResponseAccepted
+				return
+			}
 			agg.logger.Warnf("BLS aggregation service error: %s", err)
-			// todo shouldn't we here close the channel with a reply = 1?
-		} else {
-			agg.logger.Info("BLS process succeeded")
+			agg.removeSigner(taskIndex, signedTaskResponse.OperatorId)
+			if reply == types.SignedTaskResponseInvalidSignature {
+				agg.recordInvalidSubmission(signedTaskResponse.OperatorId)
+			}
+			done <- reply
+			return
 		}
 
-		close(done)
+		agg.logger.Info("BLS process succeeded")
+		agg.persistSignature(*signedTaskResponse)
+		done <- types.SignedTaskResponseAccepted
 	}()
 
-	*reply = 1
-	// Wait for either the context to be done or the task to complete
 	select {
 	case <-ctx.Done():
-		// The context's deadline was exceeded or it was canceled
-		agg.logger.Info("Bls process timed out, operator signature will be lost. Batch may not reach quorum")
-	case <-done:
-		// The task completed successfully
-		agg.logger.Info("Bls context finished correctly")
-		*reply = 0
+		agg.logger.Info("Bls process timed out, the operator may retry. Batch may not reach quorum")
+		return types.SignedTaskResponseTimeout
+	case reply = <-done:
+		agg.logger.Info("Bls context finished", "reply", types.SignedTaskResponseReplyString(reply))
+		return reply
 	}
-
-	agg.AggregatorConfig.BaseConfig.Logger.Info("- Unlocked Resources: Task response processing finished")
-	agg.taskMutex.Unlock()
-
-	return nil
-}
-
-// Dummy method to check if the server is running
-// TODO: Remove this method in prod
-func (agg *Aggregator) ServerRunning(_ *struct{}, reply *int64) error {
-	*reply = 1
-	return nil
 }
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestSubmitSignedTaskResponseTransientLookupError(t *testing.T) {
+	agg := newTestAggregator(t)
+	operator := agg.newTestOperator(t)
+	batchMerkleRoot := [32]byte{1}
+	senderAddress := [20]byte{2}
+	agg.AddNewTask(batchMerkleRoot, senderAddress, 10)
+
+	authenticated, err := types.SignSignedTaskResponse(operator.sign(batchMerkleRoot, senderAddress), operator.ecdsaKey, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The node is unreachable, the request may be authentic
+	agg.reader.SetError(errors.New("connection refused"))
+	reply := agg.submitSignedTaskResponse("127.0.0.1", &authenticated.SignedTaskResponse, authenticated)
+	if reply != types.SignedTaskResponseInternalError || !types.SignedTaskResponseReplyIsRetryable(reply) {
+		t.Fatalf("Expected a retryable internal error, got %q", types.SignedTaskResponseReplyString(reply))
+	}
+
+	// The retry is accepted once the node is back
+	agg.reader.SetError(nil)
+	reply = agg.submitSignedTaskResponse("127.0.0.1", &authenticated.SignedTaskResponse, authenticated)
+	if reply != types.SignedTaskResponseAccepted {
+		t.Fatalf("Expected the retry to be accepted, got %q", types.SignedTaskResponseReplyString(reply))
+	}
+}
+
+func TestSubmitSignedTaskResponseWrongSigner(t *testing.T) {
+	agg := newTestAggregator(t)
+	operator := agg.newTestOperator(t)
+	batchMerkleRoot := [32]byte{1}
+	senderAddress := [20]byte{2}
+	agg.AddNewTask(batchMerkleRoot, senderAddress, 10)
+
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Could not generate ECDSA key: %v", err)
+	}
+	authenticated, err := types.SignSignedTaskResponse(operator.sign(batchMerkleRoot, senderAddress), otherKey, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reply := agg.submitSignedTaskResponse("127.0.0.1", &authenticated.SignedTaskResponse, authenticated)
+	if reply != types.SignedTaskResponseUnauthorized {
+		t.Fatalf("Expected the request to be unauthorized, got %q", types.SignedTaskResponseReplyString(reply))
+	}
+}
@@ -0,0 +1,57 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"errors"
+
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+// Results of a shadow simulation besides the respondToTaskFailureReason ones
+const (
+	shadowResultOk               = "ok"
+	shadowResultAlreadyResponded = "already_responded"
+)
+
+// simulateTaskResponse is respondTask in shadow mode. It simulates the response it would have sent,
+// and reports its cost and result instead of sending it.
+func (agg *Aggregator) simulateTaskResponse(taskIndex uint32, batchIdentifierHash [32]byte, batchData BatchData, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) {
+	// The simulation reverts once the real aggregator responds the batch
+	responded, err := agg.avsReader.IsBatchResponded(batchIdentifierHash)
+	if err == nil && responded {
+		agg.logger.Info("Shadow mode: batch already responded by another aggregator, not simulating",
+			"taskIndex", taskIndex,
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+		agg.metrics.IncAggregatorShadowSimulations(shadowResultAlreadyResponded)
+		agg.setTaskSimulated(taskIndex, errors.New("batch already responded"))
+		return
+	}
+
+	simulation, err := agg.avsWriter.SimulateAggregatedResponse(batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
+	if simulation != nil {
+		agg.metrics.ObserveAggregatorRespondToTask(simulation.GasLimit, simulation.Cost)
+	}
+	if err != nil {
+		agg.logger.Warn("Shadow mode: aggregated response would not be sent",
+			"taskIndex", taskIndex,
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+			"reason", respondToTaskFailureReason(err),
+			"err", err)
+		agg.metrics.IncAggregatorShadowSimulations(respondToTaskFailureReason(err))
+		agg.setTaskSimulated(taskIndex, err)
+		return
+	}
+
+	agg.logger.Info("Shadow mode: aggregated response simulated",
+		"taskIndex", taskIndex,
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+		"merkleRoot", "0x"+hex.EncodeToString(batchData.BatchMerkleRoot[:]),
+		"senderAddress", "0x"+hex.EncodeToString(batchData.SenderAddress[:]),
+		"nonSigners", len(nonSignerStakesAndSignature.NonSignerPubkeys),
+		"gas", simulation.GasLimit,
+		"cost", simulation.Cost,
+		"respondToTaskFeeLimit", simulation.RespondToTaskFeeLimit,
+		"txData", "0x"+hex.EncodeToString(simulation.Transaction.Data()))
+	agg.metrics.IncAggregatorShadowSimulations(shadowResultOk)
+	agg.setTaskSimulated(taskIndex, nil)
+}
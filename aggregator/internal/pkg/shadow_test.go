@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+func newTestShadowAggregator(t *testing.T) *testAggregator {
+	t.Helper()
+	agg := newTestAggregator(t)
+	agg.AggregatorConfig.Aggregator.ShadowMode = true
+	agg.writer.SimulationOnly = true
+	return agg
+}
+
+func TestShadowModeOnlySimulatesResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(agg *testAggregator, batchIdentifierHash [32]byte)
+		expectedError string
+	}{
+		{
+			name:  "simulation succeeds",
+			setup: func(agg *testAggregator, batchIdentifierHash [32]byte) {},
+		},
+		{
+			name: "simulation reverts",
+			setup: func(agg *testAggregator, batchIdentifierHash [32]byte) {
+				agg.writer.SetSimulationError(errors.New("execution reverted"))
+			},
+			expectedError: chainio.ErrSimulationFailed.Error() + ": execution reverted",
+		},
+		{
+			name: "responded by the real aggregator",
+			setup: func(agg *testAggregator, batchIdentifierHash [32]byte) {
+				agg.reader.SetBatchResponded(batchIdentifierHash, true)
+			},
+			expectedError: "batch already responded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agg := newTestShadowAggregator(t)
+			batchIdentifierHash, batchData := agg.addTestTask([32]byte{1}, [20]byte{1}, 10)
+			tt.setup(agg, batchIdentifierHash)
+
+			agg.respondTask(0, batchIdentifierHash, batchData, 10, servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature{})
+
+			if responses := agg.writer.Responses(); len(responses) != 0 {
+				t.Fatalf("Expected no response to be sent, got %v", responses)
+			}
+			if state, txHash := agg.taskProgress(t, 0); state != TaskStateSimulated || txHash != nil {
+				t.Fatalf("Expected the task to be simulated without a transaction, got %s with %v", state, txHash)
+			}
+			agg.taskMutex.Lock()
+			taskError := agg.taskProgressByIdx[0].err
+			agg.taskMutex.Unlock()
+			if taskError != tt.expectedError {
+				t.Fatalf("Expected error %q, got %q", tt.expectedError, taskError)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Reasons a task is evicted, as reported on the `aligned_aggregator_evicted_tasks` metric
+const (
+	taskEvictionRetention = "retention"
+	taskEvictionMemoryCap = "memory_cap"
+)
+
+// Long-lived goroutine that periodically checks and removes old Tasks from stored Maps
+// It runs every GarbageCollectorPeriod and removes the finished tasks created more than
+// GarbageCollectorTaskRetention ago. If more than GarbageCollectorMaxTasks tasks are still left, the oldest
+// ones are removed too, finished tasks first.
+// This was added because each task occupies memory in the maps, and we need to free it to avoid a memory leak
+func (agg *Aggregator) ClearTasksFromMaps() {
+	defer func() {
+		err := recover() //stops panics
+		if err != nil {
+			agg.logger.Error("Recovered from panic", "err", err)
+		}
+	}()
+
+	agg.AggregatorConfig.BaseConfig.Logger.Info(fmt.Sprintf("- Removing finalized Task Infos from Maps every %v", agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod))
+
+	for {
+		time.Sleep(agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod)
+
+		agg.AggregatorConfig.BaseConfig.Logger.Info("Cleaning finalized tasks from maps")
+		evictedByRetention, evictedByMemoryCap, trackedTasks := agg.evictTasks(time.Now())
+		agg.metrics.AddAggregatorEvictedTasks(taskEvictionRetention, evictedByRetention)
+		agg.metrics.AddAggregatorEvictedTasks(taskEvictionMemoryCap, evictedByMemoryCap)
+		agg.metrics.SetAggregatorTrackedTasks(trackedTasks)
+		agg.AggregatorConfig.BaseConfig.Logger.Info("Done cleaning finalized tasks from maps",
+			"evictedByRetention", evictedByRetention, "evictedByMemoryCap", evictedByMemoryCap, "trackedTasks", trackedTasks)
+	}
+}
+
+type gcTask struct {
+	taskIndex uint32
+	createdAt time.Time
+	finished  bool
+}
+
+// evictTasks removes the tasks past their retention, then the oldest ones while over the memory cap.
+// Returns how many tasks were evicted for each reason, and how many are left.
+func (agg *Aggregator) evictTasks(now time.Time) (int, int, int) {
+	retention := agg.AggregatorConfig.Aggregator.GarbageCollectorTaskRetention
+	maxTasks := agg.AggregatorConfig.Aggregator.GarbageCollectorMaxTasks
+
+	agg.taskMutex.Lock()
+	tasks := make([]gcTask, 0, len(agg.batchesIdentifierHashByIdx))
+	for taskIndex := range agg.batchesIdentifierHashByIdx {
+		task := gcTask{taskIndex: taskIndex, finished: true}
+		// Tasks without progress can't be followed by anything, so they are treated as finished
+		if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+			task.createdAt = progress.createdAt
+			task.finished = progress.state.isFinal()
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].createdAt.Equal(tasks[j].createdAt) {
+			return tasks[i].createdAt.Before(tasks[j].createdAt)
+		}
+		return tasks[i].taskIndex < tasks[j].taskIndex
+	})
+
+	var evicted [][32]byte
+	kept := tasks[:0]
+	for _, task := range tasks {
+		if task.finished && now.Sub(task.createdAt) > retention {
+			evicted = append(evicted, agg.removeTaskLocked(task.taskIndex))
+		} else {
+			kept = append(kept, task)
+		}
+	}
+	evictedByRetention := len(evicted)
+
+	if excess := len(kept) - maxTasks; excess > 0 {
+		// Finished tasks go first, each group oldest first
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].finished && !kept[j].finished
+		})
+		for _, task := range kept[:excess] {
+			if !task.finished {
+				agg.logger.Warn("Evicting unfinished task, the aggregator is tracking more than garbage_collector_max_tasks",
+					"taskIndex", task.taskIndex, "maxTasks", maxTasks)
+			}
+			evicted = append(evicted, agg.removeTaskLocked(task.taskIndex))
+		}
+		kept = kept[excess:]
+	}
+	agg.taskMutex.Unlock()
+
+	for _, batchIdentifierHash := range evicted {
+		agg.forgetTask(batchIdentifierHash)
+	}
+	return evictedByRetention, len(evicted) - evictedByRetention, len(kept)
+}
+
+// removeTaskLocked drops the task from every map and from the BLS aggregation service, and must be called
+// while holding taskMutex
+func (agg *Aggregator) removeTaskLocked(taskIndex uint32) [32]byte {
+	batchIdentifierHash := agg.batchesIdentifierHashByIdx[taskIndex]
+	agg.logger.Info("Cleaning up task", "taskIndex", taskIndex)
+	agg.blsAggregationService.Evict(taskIndex)
+
+	delete(agg.batchesIdxByIdentifierHash, batchIdentifierHash)
+	delete(agg.batchCreatedBlockByIdx, taskIndex)
+	delete(agg.batchesIdentifierHashByIdx, taskIndex)
+	delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
+	delete(agg.signersByIdx, taskIndex)
+	delete(agg.operatorsAvsStateByIdx, taskIndex)
+	delete(agg.taskProgressByIdx, taskIndex)
+	return batchIdentifierHash
+}
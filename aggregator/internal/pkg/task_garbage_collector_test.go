@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+)
+
+func TestEvictTasksHandedToLeader(t *testing.T) {
+	agg := newTestAggregator(t)
+	agg.AggregatorConfig.Aggregator.GarbageCollectorTaskRetention = time.Minute
+	agg.AggregatorConfig.Aggregator.GarbageCollectorMaxTasks = 100
+	operator := agg.newTestOperator(t)
+
+	agg.AddNewTask([32]byte{1}, [20]byte{1}, 10)
+	agg.AddNewTask([32]byte{2}, [20]byte{2}, 11)
+	// The standby never took over task 0, task 1 is still collecting signatures
+	agg.setTaskState(0, TaskStateHandedToLeader)
+
+	evictedByRetention, evictedByMemoryCap, trackedTasks := agg.evictTasks(time.Now().Add(2 * time.Minute))
+	if evictedByRetention != 1 || evictedByMemoryCap != 0 || trackedTasks != 1 {
+		t.Fatalf("Expected only the task handed to the leader to be evicted, got %d by retention, %d by memory cap and %d left",
+			evictedByRetention, evictedByMemoryCap, trackedTasks)
+	}
+	agg.taskMutex.Lock()
+	_, tracked := agg.taskProgressByIdx[0]
+	agg.taskMutex.Unlock()
+	if tracked {
+		t.Fatal("Expected task 0 to be evicted")
+	}
+
+	// The BLS aggregation service drops the evicted task
+	signedTaskResponse := operator.sign([32]byte{1}, [20]byte{1})
+	err := agg.blsAggregationService.ProcessNewSignature(context.Background(), 0, signedTaskResponse.BatchIdentifierHash, &signedTaskResponse.BlsSignature, operator.id)
+	if !errors.Is(err, ErrBlsTaskNotFound) {
+		t.Fatalf("Expected ErrBlsTaskNotFound for an evicted task, got %v", err)
+	}
+	agg.bls.responses <- blsagg.BlsAggregationServiceResponse{TaskIndex: 0}
+	agg.bls.responses <- blsagg.BlsAggregationServiceResponse{TaskIndex: 1}
+	select {
+	case response := <-agg.blsAggregationService.GetResponseChannel():
+		if response.TaskIndex != 1 {
+			t.Fatalf("Expected the response of the evicted task to be dropped, got task %d", response.TaskIndex)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The response of task 1 was not forwarded")
+	}
+}
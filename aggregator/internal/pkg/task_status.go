@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+)
+
+// TaskState is the progress of a task in the aggregator
+type TaskState string
+
+const (
+	TaskStateCollecting    TaskState = "collecting"
+	TaskStateQuorumReached TaskState = "quorum_reached"
+	TaskStateTxSent        TaskState = "tx_sent"
+	TaskStateConfirmed     TaskState = "confirmed"
+	TaskStateFailed        TaskState = "failed"
+	// Only in shadow mode, the response was simulated instead of sent
+	TaskStateSimulated TaskState = "simulated"
+	// Only in standbys, the task reached quorum but the instance never took over to respond it
+	TaskStateHandedToLeader TaskState = "handed_to_leader"
+)
+
+// isFinal returns true if nothing else happens to a task in this state
+func (state TaskState) isFinal() bool {
+	switch state {
+	case TaskStateConfirmed, TaskStateFailed, TaskStateSimulated, TaskStateHandedToLeader:
+		return true
+	default:
+		return false
+	}
+}
+
+// taskProgress is what the aggregator knows about a task besides its batch data
+type taskProgress struct {
+	state TaskState
+	// Last error that made the task fail
+	err string
+	// Hash of the transaction that responded the task. Not set if another instance responded it
+	txHash *common.Hash
+	// Set once the quorum is reached, to resubmit the response if sending it fails
+	nonSignerStakesAndSignature *servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature
+	createdAt                   time.Time
+	quorumReachedAt             time.Time
+}
+
+// setTaskState updates the state of a task, if it is still tracked
+func (agg *Aggregator) setTaskState(taskIndex uint32, state TaskState) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.state = state
+	}
+}
+
+func (agg *Aggregator) setTaskFailed(taskIndex uint32, err error) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.state = TaskStateFailed
+		if err != nil {
+			progress.err = err.Error()
+		}
+	}
+}
+
+func (agg *Aggregator) setTaskQuorumReached(taskIndex uint32) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.state = TaskStateQuorumReached
+		progress.quorumReachedAt = time.Now()
+		agg.metrics.ObserveAggregatorTaskToQuorumLatency(progress.quorumReachedAt.Sub(progress.createdAt))
+	}
+}
+
+// setTaskAggregatedResponse keeps the response to send once the post-quorum collection window ends
+func (agg *Aggregator) setTaskAggregatedResponse(taskIndex uint32, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.nonSignerStakesAndSignature = &nonSignerStakesAndSignature
+	}
+}
+
+// setTaskConfirmed marks the task as responded. txHash is nil if it was responded by another instance
+func (agg *Aggregator) setTaskConfirmed(taskIndex uint32, txHash *common.Hash) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.state = TaskStateConfirmed
+		progress.err = ""
+		progress.txHash = txHash
+		if txHash != nil && !progress.quorumReachedAt.IsZero() {
+			agg.metrics.ObserveAggregatorQuorumToConfirmationLatency(time.Since(progress.quorumReachedAt))
+		}
+	}
+}
+
+// setTaskSimulated ends a task in shadow mode. err is why the response would not have been sent, if any
+func (agg *Aggregator) setTaskSimulated(taskIndex uint32, err error) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	if progress, ok := agg.taskProgressByIdx[taskIndex]; ok {
+		progress.state = TaskStateSimulated
+		progress.err = ""
+		if err != nil {
+			progress.err = err.Error()
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const (
+	taskStoreCacheMb = 16
+	taskStoreHandles = 16
+)
+
+var (
+	taskKeyPrefix      = []byte("task-")
+	signatureKeyPrefix = []byte("sig-")
+)
+
+// StoredTask is the task metadata kept in the task store
+type StoredTask struct {
+	BatchIdentifierHash [32]byte `json:"batch_identifier_hash"`
+	BatchMerkleRoot     [32]byte `json:"batch_merkle_root"`
+	SenderAddress       [20]byte `json:"sender_address"`
+	TaskCreatedBlock    uint32   `json:"task_created_block"`
+}
+
+// TaskStore persists the pending tasks and the operator signatures received for them in an
+// embedded leveldb database, so they can be recovered after an aggregator restart.
+// Tasks are stored under "task-<batchIdentifierHash>" and signatures under
+// "sig-<batchIdentifierHash><operatorId>", so all the signatures of a task share a prefix.
+type TaskStore struct {
+	db *leveldb.Database
+}
+
+func NewTaskStore(path string) (*TaskStore, error) {
+	db, err := leveldb.New(path, taskStoreCacheMb, taskStoreHandles, "", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open task store: %w", err)
+	}
+	return &TaskStore{db: db}, nil
+}
+
+func (s *TaskStore) PutTask(task StoredTask) error {
+	value, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(taskKey(task.BatchIdentifierHash), value)
+}
+
+func (s *TaskStore) PutSignature(signedTaskResponse types.SignedTaskResponse) error {
+	value, err := json.Marshal(signedTaskResponse)
+	if err != nil {
+		return err
+	}
+	key := append(signatureKey(signedTaskResponse.BatchIdentifierHash), signedTaskResponse.OperatorId[:]...)
+	return s.db.Put(key, value)
+}
+
+// Tasks returns all the stored tasks
+func (s *TaskStore) Tasks() ([]StoredTask, error) {
+	iterator := s.db.NewIterator(taskKeyPrefix, nil)
+	defer iterator.Release()
+
+	var tasks []StoredTask
+	for iterator.Next() {
+		var task StoredTask
+		if err := json.Unmarshal(iterator.Value(), &task); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, iterator.Error()
+}
+
+// Signatures returns the operator signatures stored for a task
+func (s *TaskStore) Signatures(batchIdentifierHash [32]byte) ([]types.SignedTaskResponse, error) {
+	iterator := s.db.NewIterator(signatureKey(batchIdentifierHash), nil)
+	defer iterator.Release()
+
+	var signatures []types.SignedTaskResponse
+	for iterator.Next() {
+		var signedTaskResponse types.SignedTaskResponse
+		if err := json.Unmarshal(iterator.Value(), &signedTaskResponse); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stored signature: %w", err)
+		}
+		signatures = append(signatures, signedTaskResponse)
+	}
+	return signatures, iterator.Error()
+}
+
+// DeleteTask removes a task and all its signatures
+func (s *TaskStore) DeleteTask(batchIdentifierHash [32]byte) error {
+	batch := s.db.NewBatch()
+
+	iterator := s.db.NewIterator(signatureKey(batchIdentifierHash), nil)
+	for iterator.Next() {
+		if err := batch.Delete(iterator.Key()); err != nil {
+			iterator.Release()
+			return err
+		}
+	}
+	iterator.Release()
+	if err := iterator.Error(); err != nil {
+		return err
+	}
+
+	if err := batch.Delete(taskKey(batchIdentifierHash)); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (s *TaskStore) Close() error {
+	return s.db.Close()
+}
+
+func taskKey(batchIdentifierHash [32]byte) []byte {
+	return append(append([]byte{}, taskKeyPrefix...), batchIdentifierHash[:]...)
+}
+
+func signatureKey(batchIdentifierHash [32]byte) []byte {
+	return append(append([]byte{}, signatureKeyPrefix...), batchIdentifierHash[:]...)
+}
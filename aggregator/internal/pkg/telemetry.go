@@ -11,6 +11,20 @@ import (
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// In order to wait for all operator responses, even if the quorum is reached, traces are finished with a delay
+const telemetryFinishTraceDelay = 10 * time.Second
+
+// Backoff before the first retry of a failed export, doubled on every retry
+var telemetryRetryBackoff = 500 * time.Millisecond
+
+// Reasons an event is dropped, as reported on the `aligned_aggregator_telemetry_dropped_events` metric
+const (
+	telemetryDropQueueFull  = "queue_full"
+	telemetryDropSendFailed = "send_failed"
 )
 
 type TraceMessage struct {
@@ -30,105 +44,280 @@ type TaskErrorMessage struct {
 	TaskError  string `json:"error"`
 }
 
-type Telemetry struct {
-	client  http.Client
-	baseURL url.URL
-	logger  logging.Logger
+type telemetryEventKind int
+
+const (
+	telemetryInitTrace telemetryEventKind = iota
+	telemetryOperatorResponse
+	telemetryQuorumReached
+	telemetryTaskError
+	telemetryFinishTrace
+	telemetryTaskResponded
+)
+
+type telemetryEvent struct {
+	kind            telemetryEventKind
+	batchMerkleRoot [32]byte
+	operatorId      [32]byte
+	taskError       string
+	// Operator spans of an operator response, nil if the operator sent none
+	traceContext *types.TaskTraceContext
+	txHash       string
+	timestamp    time.Time
 }
 
-func NewTelemetry(serverAddress string, logger logging.Logger) *Telemetry {
-	client := http.Client{}
+// telemetryExporter sends a batch of events, and returns how many of them were sent before failing.
+// The events that were not sent are retried.
+type telemetryExporter interface {
+	Name() string
+	Export(events []telemetryEvent) (int, error)
+}
 
-	baseURL := url.URL{
-		Scheme: "http",
-		Host:   serverAddress,
-	}
-	logger.Info("[Telemetry] Starting Telemetry client.", "server_address",
-		serverAddress)
+type TelemetryConfig struct {
+	// Address of the telemetry API, no events are sent to it if empty
+	ApiAddress string
+	// OTLP/HTTP traces endpoint, like http://localhost:4318/v1/traces, disabled if empty
+	OtlpTracesEndpoint string
+	QueueSize          int
+	BatchSize          int
+	FlushInterval      time.Duration
+	RequestTimeout     time.Duration
+	MaxRetries         int
+}
+
+// Telemetry queues the task events, and a background worker exports them in batches, so telemetry
+// never blocks the aggregator. Events are dropped if the queue is full, or if they can't be sent
+// after MaxRetries retries.
+type Telemetry struct {
+	queue         chan telemetryEvent
+	exporters     []telemetryExporter
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	metrics       *metrics.Metrics
+	logger        logging.Logger
+}
 
-	return &Telemetry{
-		client:  client,
-		baseURL: baseURL,
-		logger:  logger,
+func NewTelemetry(config TelemetryConfig, metrics *metrics.Metrics, logger logging.Logger) *Telemetry {
+	var exporters []telemetryExporter
+	if config.ApiAddress != "" {
+		logger.Info("[Telemetry] Starting Telemetry client.", "server_address", config.ApiAddress)
+		exporters = append(exporters, newTelemetryApiExporter(config.ApiAddress, config.RequestTimeout, logger))
+	}
+	if config.OtlpTracesEndpoint != "" {
+		logger.Info("[Telemetry] Starting OTLP exporter.", "endpoint", config.OtlpTracesEndpoint)
+		exporters = append(exporters, newOtlpExporter(config.OtlpTracesEndpoint, config.RequestTimeout))
+	}
+	if len(exporters) == 0 {
+		logger.Info("[Telemetry] No telemetry server configured, telemetry is disabled")
 	}
+	return newTelemetry(config, exporters, metrics, logger)
 }
 
-func (t *Telemetry) InitNewTrace(batchMerkleRoot [32]byte) {
-	body := TraceMessage{
-		MerkleRoot: fmt.Sprintf("0x%s", hex.EncodeToString(batchMerkleRoot[:])),
+// newTelemetry starts the worker exporting the events, unless there are no exporters
+func newTelemetry(config TelemetryConfig, exporters []telemetryExporter, metrics *metrics.Metrics, logger logging.Logger) *Telemetry {
+	t := &Telemetry{
+		queue:         make(chan telemetryEvent, config.QueueSize),
+		exporters:     exporters,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		maxRetries:    config.MaxRetries,
+		metrics:       metrics,
+		logger:        logger,
 	}
-	if err := t.sendTelemetryMessage("/api/initTaskTrace", body); err != nil {
-		t.logger.Error("[Telemetry] Error in InitNewTrace", "error", err)
+	if len(exporters) == 0 {
+		return t
 	}
+	go t.run()
+	return t
+}
+
+func (t *Telemetry) InitNewTrace(batchMerkleRoot [32]byte) {
+	t.enqueue(telemetryEvent{kind: telemetryInitTrace, batchMerkleRoot: batchMerkleRoot, timestamp: time.Now()})
 }
 
-func (t *Telemetry) LogOperatorResponse(batchMerkleRoot [32]byte, operatorId [32]byte) {
-	body := OperatorResponseMessage{
-		MerkleRoot: fmt.Sprintf("0x%s", hex.EncodeToString(batchMerkleRoot[:])),
-		OperatorId: fmt.Sprintf("0x%s", hex.EncodeToString(operatorId[:])),
+// LogOperatorResponse records the response, and links the operator spans into the batch trace.
+// Trace contexts of another batch are ignored, and only the first MaxTaskTraceSpans spans are kept.
+func (t *Telemetry) LogOperatorResponse(batchMerkleRoot [32]byte, operatorId [32]byte, traceContext *types.TaskTraceContext) {
+	if traceContext != nil && traceContext.TraceId != types.TaskTraceId(batchMerkleRoot) {
+		t.logger.Warn("[Telemetry] Operator trace context is not from the batch trace, ignoring it",
+			"merkle_root", "0x"+hex.EncodeToString(batchMerkleRoot[:]),
+			"operator_id", "0x"+hex.EncodeToString(operatorId[:]))
+		traceContext = nil
 	}
-	if err := t.sendTelemetryMessage("/api/operatorResponse", body); err != nil {
-		t.logger.Error("[Telemetry] Error in LogOperatorResponse", "error", err)
+	if traceContext != nil && len(traceContext.Spans) > types.MaxTaskTraceSpans {
+		truncated := *traceContext
+		truncated.Spans = traceContext.Spans[:types.MaxTaskTraceSpans]
+		traceContext = &truncated
 	}
+	t.enqueue(telemetryEvent{kind: telemetryOperatorResponse, batchMerkleRoot: batchMerkleRoot, operatorId: operatorId, traceContext: traceContext, timestamp: time.Now()})
 }
 
 func (t *Telemetry) LogQuorumReached(batchMerkleRoot [32]byte) {
-	body := QuorumReachedMessage{
-		MerkleRoot: fmt.Sprintf("0x%s", hex.EncodeToString(batchMerkleRoot[:])),
+	t.enqueue(telemetryEvent{kind: telemetryQuorumReached, batchMerkleRoot: batchMerkleRoot, timestamp: time.Now()})
+}
+
+func (t *Telemetry) LogTaskError(batchMerkleRoot [32]byte, taskError error) {
+	t.enqueue(telemetryEvent{kind: telemetryTaskError, batchMerkleRoot: batchMerkleRoot, taskError: taskError.Error(), timestamp: time.Now()})
+}
+
+// LogTaskResponded records the aggregated response being included on chain
+func (t *Telemetry) LogTaskResponded(batchMerkleRoot [32]byte, txHash [32]byte) {
+	t.enqueue(telemetryEvent{kind: telemetryTaskResponded, batchMerkleRoot: batchMerkleRoot, txHash: "0x" + hex.EncodeToString(txHash[:]), timestamp: time.Now()})
+}
+
+func (t *Telemetry) FinishTrace(batchMerkleRoot [32]byte) {
+	time.AfterFunc(telemetryFinishTraceDelay, func() {
+		t.enqueue(telemetryEvent{kind: telemetryFinishTrace, batchMerkleRoot: batchMerkleRoot, timestamp: time.Now()})
+	})
+}
+
+func (t *Telemetry) enqueue(event telemetryEvent) {
+	if len(t.exporters) == 0 {
+		return
 	}
-	if err := t.sendTelemetryMessage("/api/quorumReached", body); err != nil {
-		t.logger.Error("[Telemetry] Error in LogQuorumReached", "error", err)
+	select {
+	case t.queue <- event:
+	default:
+		t.logger.Warn("[Telemetry] Queue full, dropping event",
+			"merkle_root", "0x"+hex.EncodeToString(event.batchMerkleRoot[:]))
+		t.metrics.AddAggregatorTelemetryDroppedEvents(telemetryDropQueueFull, 1)
 	}
 }
 
-func (t *Telemetry) LogTaskError(batchMerkleRoot [32]byte, taskError error) {
-	body := TaskErrorMessage{
-		MerkleRoot: fmt.Sprintf("0x%s", hex.EncodeToString(batchMerkleRoot[:])),
-		TaskError:  taskError.Error(),
+// run exports the queued events once BatchSize of them are waiting, or every FlushInterval
+func (t *Telemetry) run() {
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]telemetryEvent, 0, t.batchSize)
+	for {
+		select {
+		case event := <-t.queue:
+			batch = append(batch, event)
+			if len(batch) < t.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			t.metrics.SetAggregatorTelemetryQueueLength(len(t.queue))
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		for _, exporter := range t.exporters {
+			t.export(exporter, batch)
+		}
+		batch = batch[:0]
 	}
-	if err := t.sendTelemetryMessage("/api/taskError", body); err != nil {
-		t.logger.Error("[Telemetry] Error in LogTaskError", "error", err)
+}
+
+// export sends the batch with the exporter, retrying the unsent events with exponential backoff
+func (t *Telemetry) export(exporter telemetryExporter, batch []telemetryEvent) {
+	pending := batch
+	for retry := 0; ; retry++ {
+		sent, err := exporter.Export(pending)
+		t.metrics.AddAggregatorTelemetryExportedEvents(exporter.Name(), sent)
+		pending = pending[sent:]
+		if err == nil {
+			return
+		}
+		if retry >= t.maxRetries {
+			t.logger.Error("[Telemetry] Error exporting events, dropping them",
+				"exporter", exporter.Name(), "events", len(pending), "error", err)
+			t.metrics.AddAggregatorTelemetryDroppedEvents(telemetryDropSendFailed, len(pending))
+			return
+		}
+		t.logger.Warn("[Telemetry] Error exporting events, retrying",
+			"exporter", exporter.Name(), "events", len(pending), "retry", retry+1, "error", err)
+		time.Sleep(telemetryRetryBackoff << retry)
 	}
 }
 
-func (t *Telemetry) FinishTrace(batchMerkleRoot [32]byte) {
-	// In order to wait for all operator responses, even if the quorum is reached, this function has a delayed execution
-	go func() {
-		time.Sleep(10 * time.Second)
-		body := TraceMessage{
-			MerkleRoot: fmt.Sprintf("0x%s", hex.EncodeToString(batchMerkleRoot[:])),
+// telemetryApiExporter sends each event to the telemetry API endpoint of its kind
+type telemetryApiExporter struct {
+	client  http.Client
+	baseURL url.URL
+	logger  logging.Logger
+}
+
+func newTelemetryApiExporter(serverAddress string, requestTimeout time.Duration, logger logging.Logger) *telemetryApiExporter {
+	return &telemetryApiExporter{
+		client: http.Client{Timeout: requestTimeout},
+		baseURL: url.URL{
+			Scheme: "http",
+			Host:   serverAddress,
+		},
+		logger: logger,
+	}
+}
+
+func (e *telemetryApiExporter) Name() string {
+	return "api"
+}
+
+func (e *telemetryApiExporter) Export(events []telemetryEvent) (int, error) {
+	for i, event := range events {
+		// The telemetry API has no endpoint for these
+		if event.kind == telemetryTaskResponded {
+			continue
 		}
-		if err := t.sendTelemetryMessage("/api/finishTaskTrace", body); err != nil {
-			t.logger.Error("[Telemetry] Error in FinishTrace", "error", err)
+		endpoint, message := telemetryApiMessage(event)
+		if err := e.sendTelemetryMessage(endpoint, message); err != nil {
+			return i, err
 		}
-	}()
+	}
+	return len(events), nil
 }
 
-func (t *Telemetry) sendTelemetryMessage(endpoint string, message interface{}) error {
+func telemetryApiMessage(event telemetryEvent) (string, interface{}) {
+	merkleRoot := fmt.Sprintf("0x%s", hex.EncodeToString(event.batchMerkleRoot[:]))
+	switch event.kind {
+	case telemetryInitTrace:
+		return "/api/initTaskTrace", TraceMessage{MerkleRoot: merkleRoot}
+	case telemetryOperatorResponse:
+		return "/api/operatorResponse", OperatorResponseMessage{
+			MerkleRoot: merkleRoot,
+			OperatorId: fmt.Sprintf("0x%s", hex.EncodeToString(event.operatorId[:])),
+		}
+	case telemetryQuorumReached:
+		return "/api/quorumReached", QuorumReachedMessage{MerkleRoot: merkleRoot}
+	case telemetryTaskError:
+		return "/api/taskError", TaskErrorMessage{MerkleRoot: merkleRoot, TaskError: event.taskError}
+	default:
+		return "/api/finishTaskTrace", TraceMessage{MerkleRoot: merkleRoot}
+	}
+}
+
+func (e *telemetryApiExporter) sendTelemetryMessage(endpoint string, message interface{}) error {
 	encodedBody, err := json.Marshal(message)
 	if err != nil {
-		t.logger.Error("[Telemetry] Error marshalling JSON", "error", err)
 		return fmt.Errorf("error marshalling JSON: %w", err)
 	}
 
-	t.logger.Info("[Telemetry] Sending message.", "endpoint", endpoint, "message", message)
+	e.logger.Debug("[Telemetry] Sending message.", "endpoint", endpoint, "message", message)
 
-	fullURL := t.baseURL.ResolveReference(&url.URL{Path: endpoint})
+	fullURL := e.baseURL.ResolveReference(&url.URL{Path: endpoint})
 
-	resp, err := t.client.Post(fullURL.String(), "application/json", bytes.NewBuffer(encodedBody))
+	resp, err := e.client.Post(fullURL.String(), "application/json", bytes.NewBuffer(encodedBody))
 	if err != nil {
-		t.logger.Error("[Telemetry] Error sending POST request", "error", err)
 		return fmt.Errorf("error making POST request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		t.logger.Error("[Telemetry] Error reading response body", "error", err)
 		return fmt.Errorf("error reading response body: %w", err)
 	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("telemetry API returned %s: %s", resp.Status, string(respBody))
+	}
+	// A rejected message would be rejected again, so it is not retried
+	if resp.StatusCode >= http.StatusBadRequest {
+		e.logger.Warn("[Telemetry] Message rejected", "endpoint", endpoint, "status", resp.Status, "response_body", string(respBody))
+		return nil
+	}
 
-	t.logger.Info("[Telemetry] Response received", "status", resp.Status, "response_body", string(respBody))
+	e.logger.Debug("[Telemetry] Response received", "status", resp.Status, "response_body", string(respBody))
 
 	return nil
 }
@@ -0,0 +1,263 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+const otlpServiceName = "aligned-aggregator"
+
+// Traces that are never finished are dropped after this long, so they don't grow the exporter memory
+const otlpOpenTraceTimeout = 1 * time.Hour
+
+// OTLP span status codes
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+// otlpExporter sends the task events to an OpenTelemetry collector as spans, with the OTLP/HTTP JSON encoding.
+// Each batch has a trace derived from its merkle root, with a root span from the new task to the
+// finished trace, and a child span from the new task to each operator response, quorum and error.
+// The spans operators send along with their responses are linked under the root span, and the on-chain
+// response is a span from the quorum to the transaction being included.
+type otlpExporter struct {
+	client     http.Client
+	endpoint   string
+	openTraces map[[32]byte]*otlpOpenTrace
+}
+
+type otlpOpenTrace struct {
+	start     time.Time
+	quorumAt  time.Time
+	taskError string
+}
+
+func newOtlpExporter(endpoint string, requestTimeout time.Duration) *otlpExporter {
+	return &otlpExporter{
+		client:     http.Client{Timeout: requestTimeout},
+		endpoint:   endpoint,
+		openTraces: make(map[[32]byte]*otlpOpenTrace),
+	}
+}
+
+func (e *otlpExporter) Name() string {
+	return "otlp"
+}
+
+// Export sends every span of the batch in a single request. Open traces are only closed once it succeeds,
+// so a retried batch produces the same traces.
+func (e *otlpExporter) Export(events []telemetryEvent) (int, error) {
+	now := time.Now()
+	for batchMerkleRoot, trace := range e.openTraces {
+		if now.Sub(trace.start) > otlpOpenTraceTimeout {
+			delete(e.openTraces, batchMerkleRoot)
+		}
+	}
+
+	spans := make([]otlpSpan, 0, len(events))
+	var finished [][32]byte
+	for _, event := range events {
+		trace, ok := e.openTraces[event.batchMerkleRoot]
+		if !ok {
+			// Events of a task started before the aggregator restarted start its trace
+			trace = &otlpOpenTrace{start: event.timestamp}
+			e.openTraces[event.batchMerkleRoot] = trace
+		}
+
+		switch event.kind {
+		case telemetryInitTrace:
+			continue
+		case telemetryOperatorResponse:
+			operatorId := otlpStringAttribute("operator_id", "0x"+hex.EncodeToString(event.operatorId[:]))
+			span := newOtlpChildSpan(event, trace.start, "operator_response")
+			span.Attributes = append(span.Attributes, operatorId)
+			spans = append(spans, span)
+			if event.traceContext != nil {
+				spans = append(spans, newOtlpOperatorSpans(event.batchMerkleRoot, event.traceContext, operatorId)...)
+				// Operators may see the batch before the aggregator, the root span covers their spans too
+				if operatorStart := time.Unix(0, event.traceContext.StartTime); operatorStart.Before(trace.start) {
+					trace.start = operatorStart
+				}
+			}
+		case telemetryQuorumReached:
+			trace.quorumAt = event.timestamp
+			spans = append(spans, newOtlpChildSpan(event, trace.start, "quorum_reached"))
+		case telemetryTaskResponded:
+			start := trace.quorumAt
+			if start.IsZero() {
+				start = trace.start
+			}
+			span := newOtlpChildSpan(event, start, "respond_to_task")
+			span.Attributes = append(span.Attributes, otlpStringAttribute("tx_hash", event.txHash))
+			spans = append(spans, span)
+		case telemetryTaskError:
+			trace.taskError = event.taskError
+			span := newOtlpChildSpan(event, event.timestamp, "task_error")
+			span.Status = otlpStatus{Code: otlpStatusError, Message: event.taskError}
+			spans = append(spans, span)
+		case telemetryFinishTrace:
+			span := newOtlpSpan(event.batchMerkleRoot, otlpRootSpanId(event.batchMerkleRoot), "", "aggregator_task", trace.start, event.timestamp)
+			if trace.taskError != "" {
+				span.Status = otlpStatus{Code: otlpStatusError, Message: trace.taskError}
+			}
+			spans = append(spans, span)
+			finished = append(finished, event.batchMerkleRoot)
+		}
+	}
+
+	if len(spans) > 0 {
+		if err := e.send(spans); err != nil {
+			return 0, err
+		}
+	}
+	for _, batchMerkleRoot := range finished {
+		delete(e.openTraces, batchMerkleRoot)
+	}
+	return len(events), nil
+}
+
+func (e *otlpExporter) send(spans []otlpSpan) error {
+	request := otlpExportTraceRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{otlpStringAttribute("service.name", otlpServiceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/yetanotherco/aligned_layer/aggregator"},
+				Spans: spans,
+			}},
+		}},
+	}
+	encodedBody, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("error marshalling OTLP request: %w", err)
+	}
+
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewBuffer(encodedBody))
+	if err != nil {
+		return fmt.Errorf("error making OTLP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OTLP collector returned %s: %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// The root span id is derived from the merkle root, so child spans exported before it can reference it
+func otlpRootSpanId(batchMerkleRoot [32]byte) string {
+	return hex.EncodeToString(crypto.Keccak256(batchMerkleRoot[:])[:8])
+}
+
+func newOtlpChildSpan(event telemetryEvent, start time.Time, name string) otlpSpan {
+	var spanId [8]byte
+	_, _ = rand.Read(spanId[:])
+	return newOtlpSpan(event.batchMerkleRoot, hex.EncodeToString(spanId[:]), otlpRootSpanId(event.batchMerkleRoot), name, start, event.timestamp)
+}
+
+// newOtlpOperatorSpans returns the operator span, child of the root span, and its stage spans
+func newOtlpOperatorSpans(batchMerkleRoot [32]byte, traceContext *types.TaskTraceContext, operatorId otlpAttribute) []otlpSpan {
+	operatorSpanId := hex.EncodeToString(traceContext.SpanId[:])
+	operatorSpan := newOtlpSpan(batchMerkleRoot, operatorSpanId, otlpRootSpanId(batchMerkleRoot), "operator_process_batch",
+		time.Unix(0, traceContext.StartTime), time.Unix(0, traceContext.EndTime))
+	operatorSpan.Attributes = append(operatorSpan.Attributes, operatorId)
+
+	spans := []otlpSpan{operatorSpan}
+	for _, stage := range traceContext.Spans {
+		var spanId [8]byte
+		_, _ = rand.Read(spanId[:])
+		name := stage.Name
+		if len(name) > types.MaxTaskSpanNameLength {
+			name = name[:types.MaxTaskSpanNameLength]
+		}
+		span := newOtlpSpan(batchMerkleRoot, hex.EncodeToString(spanId[:]), operatorSpanId, "operator_"+name,
+			time.Unix(0, stage.StartTime), time.Unix(0, stage.EndTime))
+		span.Attributes = append(span.Attributes, operatorId)
+		if stage.Error != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: stage.Error}
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func newOtlpSpan(batchMerkleRoot [32]byte, spanId string, parentSpanId string, name string, start time.Time, end time.Time) otlpSpan {
+	traceId := types.TaskTraceId(batchMerkleRoot)
+	return otlpSpan{
+		TraceId:           hex.EncodeToString(traceId[:]),
+		SpanId:            spanId,
+		ParentSpanId:      parentSpanId,
+		Name:              name,
+		Kind:              1, // SPAN_KIND_INTERNAL
+		StartTimeUnixNano: strconv.FormatInt(start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes:        []otlpAttribute{otlpStringAttribute("merkle_root", "0x"+hex.EncodeToString(batchMerkleRoot[:]))},
+		Status:            otlpStatus{Code: otlpStatusOk},
+	}
+}
+
+func otlpStringAttribute(key string, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// OTLP/HTTP JSON encoding of ExportTraceServiceRequest, ids are hex encoded and timestamps are strings
+type otlpExportTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
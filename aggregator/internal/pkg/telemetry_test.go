@@ -0,0 +1,274 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// stubTelemetryExporter records the batches it is given. Each call takes the next result, and once
+// there are none left every event is sent
+type stubTelemetryExporter struct {
+	mutex   sync.Mutex
+	batches [][]telemetryEvent
+	results []stubExportResult
+	// When set, Export waits on it, after signaling exporting
+	gate      chan struct{}
+	exporting chan struct{}
+}
+
+type stubExportResult struct {
+	sent int
+	err  error
+}
+
+func (e *stubTelemetryExporter) Name() string {
+	return "stub"
+}
+
+func (e *stubTelemetryExporter) Export(events []telemetryEvent) (int, error) {
+	if e.gate != nil {
+		e.exporting <- struct{}{}
+		<-e.gate
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.batches = append(e.batches, append([]telemetryEvent(nil), events...))
+	if len(e.results) == 0 {
+		return len(events), nil
+	}
+	result := e.results[0]
+	e.results = e.results[1:]
+	return result.sent, result.err
+}
+
+func (e *stubTelemetryExporter) exportedBatches() [][]telemetryEvent {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([][]telemetryEvent(nil), e.batches...)
+}
+
+// waitForBatches waits until the exporter was called n times
+func (e *stubTelemetryExporter) waitForBatches(t *testing.T, n int) [][]telemetryEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if batches := e.exportedBatches(); len(batches) >= n {
+			return batches
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d exported batches, got %d", n, len(e.exportedBatches()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newTestTelemetry(t *testing.T, config TelemetryConfig, exporter telemetryExporter) (*Telemetry, *prometheus.Registry) {
+	t.Helper()
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	registry := prometheus.NewRegistry()
+	return newTelemetry(config, []telemetryExporter{exporter}, metrics.NewMetrics("", registry, logger), logger), registry
+}
+
+// counterValue returns the value of the counter with the label value, 0 if it was never set
+func counterValue(t *testing.T, registry *prometheus.Registry, name string, labelValue string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetValue() == labelValue {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// waitForCounter waits until the counter with the label value reaches the value
+func waitForCounter(t *testing.T, registry *prometheus.Registry, name string, labelValue string, value float64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current := counterValue(t, registry, name, labelValue)
+		if current == value {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %s{%s} to be %v, got %v", name, labelValue, value, current)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTelemetryExportsFullBatches(t *testing.T) {
+	exporter := &stubTelemetryExporter{}
+	telemetry, _ := newTestTelemetry(t, TelemetryConfig{QueueSize: 10, BatchSize: 3, FlushInterval: time.Hour}, exporter)
+
+	for i := byte(0); i < 5; i++ {
+		telemetry.InitNewTrace([32]byte{i})
+	}
+
+	batches := exporter.waitForBatches(t, 1)
+	if len(batches[0]) != 3 {
+		t.Fatalf("Expected a batch of 3 events, got %d", len(batches[0]))
+	}
+	// The rest waits for the batch to fill or for the flush interval
+	time.Sleep(50 * time.Millisecond)
+	if batches = exporter.exportedBatches(); len(batches) != 1 {
+		t.Fatalf("Expected a single batch, got %d", len(batches))
+	}
+}
+
+func TestTelemetryExportsOnFlushInterval(t *testing.T) {
+	exporter := &stubTelemetryExporter{}
+	telemetry, _ := newTestTelemetry(t, TelemetryConfig{QueueSize: 10, BatchSize: 100, FlushInterval: 20 * time.Millisecond}, exporter)
+
+	telemetry.InitNewTrace([32]byte{1})
+	telemetry.LogQuorumReached([32]byte{1})
+
+	batches := exporter.waitForBatches(t, 1)
+	if len(batches[0]) != 2 || batches[0][0].kind != telemetryInitTrace || batches[0][1].kind != telemetryQuorumReached {
+		t.Fatalf("Expected the queued events in order, got %+v", batches[0])
+	}
+}
+
+func TestTelemetryDropsEventsWhenQueueIsFull(t *testing.T) {
+	exporter := &stubTelemetryExporter{gate: make(chan struct{}), exporting: make(chan struct{}, 1)}
+	telemetry, registry := newTestTelemetry(t, TelemetryConfig{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour}, exporter)
+
+	// The first event is being exported, the second waits in the queue and the third doesn't fit
+	telemetry.InitNewTrace([32]byte{1})
+	<-exporter.exporting
+	telemetry.InitNewTrace([32]byte{2})
+	telemetry.InitNewTrace([32]byte{3})
+
+	if dropped := counterValue(t, registry, "aligned_aggregator_telemetry_dropped_events", telemetryDropQueueFull); dropped != 1 {
+		t.Fatalf("Expected 1 event dropped, got %v", dropped)
+	}
+	close(exporter.gate)
+	batches := exporter.waitForBatches(t, 2)
+	if batches[1][0].batchMerkleRoot != ([32]byte{2}) {
+		t.Fatalf("Expected the queued event to be exported, got %+v", batches[1])
+	}
+}
+
+func TestTelemetryDropsEventsAfterMaxRetries(t *testing.T) {
+	retryBackoff := telemetryRetryBackoff
+	telemetryRetryBackoff = 0
+	t.Cleanup(func() { telemetryRetryBackoff = retryBackoff })
+
+	exportErr := errors.New("connection refused")
+	exporter := &stubTelemetryExporter{results: []stubExportResult{{0, exportErr}, {0, exportErr}, {0, exportErr}}}
+	telemetry, registry := newTestTelemetry(t, TelemetryConfig{QueueSize: 10, BatchSize: 2, FlushInterval: time.Hour, MaxRetries: 2}, exporter)
+
+	telemetry.InitNewTrace([32]byte{1})
+	telemetry.LogQuorumReached([32]byte{1})
+
+	waitForCounter(t, registry, "aligned_aggregator_telemetry_dropped_events", telemetryDropSendFailed, 2)
+	if batches := exporter.exportedBatches(); len(batches) != 3 {
+		t.Fatalf("Expected the batch to be tried 3 times, got %d", len(batches))
+	}
+}
+
+func TestTelemetryRetriesUnsentEvents(t *testing.T) {
+	retryBackoff := telemetryRetryBackoff
+	telemetryRetryBackoff = 0
+	t.Cleanup(func() { telemetryRetryBackoff = retryBackoff })
+
+	exporter := &stubTelemetryExporter{results: []stubExportResult{{2, errors.New("connection reset")}}}
+	telemetry, registry := newTestTelemetry(t, TelemetryConfig{QueueSize: 10, BatchSize: 4, FlushInterval: time.Hour, MaxRetries: 1}, exporter)
+
+	for i := byte(0); i < 4; i++ {
+		telemetry.InitNewTrace([32]byte{i})
+	}
+
+	batches := exporter.waitForBatches(t, 2)
+	if len(batches[1]) != 2 || batches[1][0].batchMerkleRoot != ([32]byte{2}) || batches[1][1].batchMerkleRoot != ([32]byte{3}) {
+		t.Fatalf("Expected the retry to start from the first unsent event, got %+v", batches[1])
+	}
+	waitForCounter(t, registry, "aligned_aggregator_telemetry_exported_events", "stub", 4)
+}
+
+func TestOtlpExporterSendsBatchTrace(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []otlpExportTraceRequest
+	failures := 1
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if failures > 0 {
+			failures--
+			http.Error(w, "collector starting", http.StatusServiceUnavailable)
+			return
+		}
+		var request otlpExportTraceRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, request)
+	}))
+	defer receiver.Close()
+
+	exporter := newOtlpExporter(receiver.URL, time.Second)
+	batchMerkleRoot := [32]byte{1}
+	start := time.Now()
+	events := []telemetryEvent{
+		{kind: telemetryInitTrace, batchMerkleRoot: batchMerkleRoot, timestamp: start},
+		{kind: telemetryQuorumReached, batchMerkleRoot: batchMerkleRoot, timestamp: start.Add(time.Second)},
+		{kind: telemetryFinishTrace, batchMerkleRoot: batchMerkleRoot, timestamp: start.Add(2 * time.Second)},
+	}
+
+	// The trace stays open while the collector fails, so the retry sends the same spans
+	if sent, err := exporter.Export(events); err == nil || sent != 0 {
+		t.Fatalf("Expected the export to fail, got %d sent and %v", sent, err)
+	}
+	if sent, err := exporter.Export(events); err != nil || sent != len(events) {
+		t.Fatalf("Expected the export to succeed, got %d sent and %v", sent, err)
+	}
+	if len(exporter.openTraces) != 0 {
+		t.Fatalf("Expected the finished trace to be closed")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request received, got %d", len(requests))
+	}
+	spans := requests[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("Expected the quorum and the root spans, got %+v", spans)
+	}
+	traceId := types.TaskTraceId(batchMerkleRoot)
+	quorum, root := spans[0], spans[1]
+	if quorum.Name != "quorum_reached" || root.Name != "aggregator_task" {
+		t.Fatalf("Expected the quorum and the root spans, got %s and %s", quorum.Name, root.Name)
+	}
+	if quorum.TraceId != hex.EncodeToString(traceId[:]) || root.TraceId != quorum.TraceId {
+		t.Fatalf("Expected the spans in the batch trace, got %s and %s", quorum.TraceId, root.TraceId)
+	}
+	if root.ParentSpanId != "" || quorum.ParentSpanId != root.SpanId {
+		t.Fatalf("Expected the quorum span to be a child of the root span")
+	}
+}
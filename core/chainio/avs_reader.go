@@ -15,8 +15,23 @@ import (
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 )
 
+// AvsReaderer is what the operator and the aggregator read from the contracts.
+// AvsReader implements it against Ethereum, and the fakes package in memory.
+type AvsReaderer interface {
+	IsOperatorRegistered(address ethcommon.Address) (bool, error)
+	GetOperatorFromId(opts *bind.CallOpts, operatorId eigentypes.OperatorId) (ethcommon.Address, error)
+	CheckQuorumsExist(quorumNumbers []uint8) error
+	DisabledVerifiers() (*big.Int, error)
+	GetRestakeableStrategies() ([]ethcommon.Address, error)
+	IsBatchResponded(batchIdentifierHash [32]byte) (bool, error)
+	GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error)
+	GetNotRespondedTasksBetween(fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
 type AvsReader struct {
 	*sdkavsregistry.ChainReader
 	AvsContractBindings            *AvsServiceBindings
@@ -71,13 +86,77 @@ func (r *AvsReader) IsOperatorRegistered(address ethcommon.Address) (bool, error
 	return r.ChainReader.IsOperatorRegistered(&bind.CallOpts{}, address)
 }
 
+// CheckQuorumsExist returns an error if any of the quorum numbers is not created in the registry coordinator
+func (r *AvsReader) CheckQuorumsExist(quorumNumbers []uint8) error {
+	quorumCount, err := r.ChainReader.GetQuorumCount(&bind.CallOpts{})
+	if err != nil {
+		return fmt.Errorf("could not get quorum count from registry coordinator: %w", err)
+	}
+	for _, quorumNumber := range quorumNumbers {
+		if quorumNumber >= quorumCount {
+			return fmt.Errorf("quorum %d does not exist, the registry coordinator has %d quorums", quorumNumber, quorumCount)
+		}
+	}
+	return nil
+}
+
 func (r *AvsReader) DisabledVerifiers() (*big.Int, error) {
-	return r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.DisabledVerifiers(&bind.CallOpts{})
+	disabledVerifiers, err := r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.DisabledVerifiers(&bind.CallOpts{})
+	if err != nil {
+		// Retry with fallback client
+		return r.AvsContractBindings.ServiceManagerFallback.ContractAlignedLayerServiceManagerCaller.DisabledVerifiers(&bind.CallOpts{})
+	}
+	return disabledVerifiers, nil
+}
+
+// Returns the strategies that can be restaked in the Aligned AVS
+func (r *AvsReader) GetRestakeableStrategies() ([]ethcommon.Address, error) {
+	strategies, err := r.AvsContractBindings.ServiceManager.GetRestakeableStrategies(&bind.CallOpts{})
+	if err != nil {
+		// Retry with fallback client
+		strategies, err = r.AvsContractBindings.ServiceManagerFallback.GetRestakeableStrategies(&bind.CallOpts{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return strategies, nil
+}
+
+// Returns true if the batch has already been responded on-chain
+func (r *AvsReader) IsBatchResponded(batchIdentifierHash [32]byte) (bool, error) {
+	state, err := r.AvsContractBindings.ServiceManager.BatchesState(&bind.CallOpts{}, batchIdentifierHash)
+	if err != nil {
+		// Retry with fallback client
+		state, err = r.AvsContractBindings.ServiceManagerFallback.BatchesState(&bind.CallOpts{}, batchIdentifierHash)
+		if err != nil {
+			return false, err
+		}
+	}
+	return state.Responded, nil
 }
 
 // Returns all the "NewBatchV3" logs that have not been responded starting from the given block number
 func (r *AvsReader) GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
-	logs, err := r.AvsContractBindings.ServiceManager.FilterNewBatchV3(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil)
+	return r.getNotRespondedTasks(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()})
+}
+
+// Returns the "NewBatchV3" logs that have not been responded between the given block numbers, both included
+func (r *AvsReader) GetNotRespondedTasksBetween(fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	return r.getNotRespondedTasks(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: context.Background()})
+}
+
+// Returns the latest block number
+func (r *AvsReader) BlockNumber(ctx context.Context) (uint64, error) {
+	blockNumber, err := r.AvsContractBindings.ethClient.BlockNumber(ctx)
+	if err != nil {
+		// Retry with fallback client
+		blockNumber, err = r.AvsContractBindings.ethClientFallback.BlockNumber(ctx)
+	}
+	return blockNumber, err
+}
+
+func (r *AvsReader) getNotRespondedTasks(filterOpts *bind.FilterOpts) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	logs, err := r.AvsContractBindings.ServiceManager.FilterNewBatchV3(filterOpts, nil)
 
 	if err != nil {
 		return nil, err
@@ -106,47 +185,9 @@ func (r *AvsReader) GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager
 		}
 	}
 
-	return tasks, nil
-}
-
-// This function is a helper to get a task hash of aproximately nBlocksOld blocks ago
-func (r *AvsReader) GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byte, error) {
-	latestBlock, err := r.AvsContractBindings.ethClient.BlockNumber(context.Background())
-	if err != nil {
-		latestBlock, err = r.AvsContractBindings.ethClientFallback.BlockNumber(context.Background())
-		if err != nil {
-			return nil, fmt.Errorf("failed to get latest block number: %w", err)
-		}
-	}
-
-	if latestBlock < nBlocksOld {
-		return nil, fmt.Errorf("latest block is less than nBlocksOld")
-	}
-
-	// Define block number limits to query the rpc
-	var fromBlock uint64
-
-	toBlock := latestBlock - nBlocksOld
-	fromBlock = toBlock - interval
-
-	logs, err := r.AvsContractBindings.ServiceManager.FilterNewBatchV3(&bind.FilterOpts{Start: fromBlock, End: &toBlock, Context: context.Background()}, nil)
-	if err != nil {
-		return nil, err
-	}
+	// Iteration stops on errors too, so a partial result is not mistaken for the full range
 	if err := logs.Error(); err != nil {
 		return nil, err
 	}
-	if !logs.Next() {
-		return nil, nil //not an error, but no tasks found
-	}
-
-	// Any log from the list is good enough.
-	task, err := r.AvsContractBindings.ServiceManager.ParseNewBatchV3(logs.Event.Raw)
-	if err != nil {
-		return nil, err
-	}
-
-	batchIdentifier := append(task.BatchMerkleRoot[:], task.SenderAddress[:]...)
-	batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-	return &batchIdentifierHash, nil
+	return tasks, nil
 }
@@ -27,12 +27,20 @@ const (
 	RemoveBatchFromSetInterval        = 5 * time.Minute
 )
 
-// NOTE(marian): Leaving this commented code here as it may be useful in the short term.
-// type AvsSubscriberer interface {
-// 	SubscribeToNewTasks(newTaskCreatedChan chan *cstaskmanager.ContractAlignedLayerTaskManagerNewTaskCreated) event.Subscription
-// 	SubscribeToTaskResponses(taskResponseLogs chan *cstaskmanager.ContractAlignedLayerTaskManagerTaskResponded) event.Subscription
-// 	ParseTaskResponded(rawLog types.Log) (*cstaskmanager.ContractAlignedLayerTaskManagerTaskResponded, error)
-// }
+// AvsSubscriberer is what the operator and the aggregator follow on the contracts.
+// Subscriptions forward the events to the given channels, and report errors on the returned one.
+// AvsSubscriber implements it against Ethereum, and the fakes package in memory.
+type AvsSubscriberer interface {
+	SubscribeToNewTasksV2(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error)
+	SubscribeToNewTasksV3(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (chan error, error)
+	SubscribeToBatchVerified(batchVerifiedChan chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified) (chan error, error)
+	SubscribeToVerifierStatusChanges(
+		verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+		verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+	) (chan error, error)
+	// WaitForOneBlock returns once the chain is past the given block
+	WaitForOneBlock(startBlock uint64) error
+}
 
 // Subscribers use a ws connection instead of http connection like Readers
 // kind of stupid that the geth client doesn't have a unified interface for both...
@@ -202,6 +210,171 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 	return errorChannel, nil
 }
 
+// SubscribeToBatchVerified forwards the BatchVerified events, emitted when a batch is responded,
+// to the provided channel. Events may be received twice, once from each client.
+func (s *AvsSubscriber) SubscribeToBatchVerified(batchVerifiedChan chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified) (chan error, error) {
+	sub, err := subscribeToBatchVerified(s.AvsContractBindings.ServiceManager, batchVerifiedChan, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to BatchVerified events", "err", err)
+		return nil, err
+	}
+
+	subFallback, err := subscribeToBatchVerified(s.AvsContractBindings.ServiceManagerFallback, batchVerifiedChan, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to BatchVerified events", "err", err)
+		return nil, err
+	}
+
+	// create a new channel to foward errors
+	errorChannel := make(chan error)
+
+	// Handle errors and resubscribe
+	go func() {
+		for {
+			select {
+			case err := <-sub.Err():
+				s.logger.Warn("Error in BatchVerified subscription", "err", err)
+				sub.Unsubscribe()
+				sub, err = subscribeToBatchVerified(s.AvsContractBindings.ServiceManager, batchVerifiedChan, s.logger)
+				if err != nil {
+					errorChannel <- err
+				}
+			case err := <-subFallback.Err():
+				s.logger.Warn("Error in fallback BatchVerified subscription", "err", err)
+				subFallback.Unsubscribe()
+				subFallback, err = subscribeToBatchVerified(s.AvsContractBindings.ServiceManagerFallback, batchVerifiedChan, s.logger)
+				if err != nil {
+					errorChannel <- err
+				}
+			}
+		}
+	}()
+
+	return errorChannel, nil
+}
+
+// SubscribeToVerifierStatusChanges forwards the VerifierDisabled and VerifierEnabled events to the given channels
+func (s *AvsSubscriber) SubscribeToVerifierStatusChanges(
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+	verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+) (chan error, error) {
+	subDisabled, subEnabled, err := subscribeToVerifierStatusChanges(s.AvsContractBindings.ServiceManager, verifierDisabledChan, verifierEnabledChan, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to verifier status changes", "err", err)
+		return nil, err
+	}
+
+	subDisabledFallback, subEnabledFallback, err := subscribeToVerifierStatusChanges(s.AvsContractBindings.ServiceManagerFallback, verifierDisabledChan, verifierEnabledChan, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to verifier status changes", "err", err)
+		return nil, err
+	}
+
+	// create a new channel to foward errors
+	errorChannel := make(chan error)
+
+	// Handle errors and resubscribe. Both events are resubscribed together, as they share the connection
+	go func() {
+		for {
+			select {
+			case err := <-subDisabled.Err():
+				s.logger.Warn("Error in verifier status subscription", "err", err)
+			case err := <-subEnabled.Err():
+				s.logger.Warn("Error in verifier status subscription", "err", err)
+			case err := <-subDisabledFallback.Err():
+				s.logger.Warn("Error in fallback verifier status subscription", "err", err)
+				subDisabledFallback, subEnabledFallback, err = resubscribeToVerifierStatusChanges(subDisabledFallback, subEnabledFallback, s.AvsContractBindings.ServiceManagerFallback, verifierDisabledChan, verifierEnabledChan, s.logger)
+				if err != nil {
+					errorChannel <- err
+				}
+				continue
+			case err := <-subEnabledFallback.Err():
+				s.logger.Warn("Error in fallback verifier status subscription", "err", err)
+				subDisabledFallback, subEnabledFallback, err = resubscribeToVerifierStatusChanges(subDisabledFallback, subEnabledFallback, s.AvsContractBindings.ServiceManagerFallback, verifierDisabledChan, verifierEnabledChan, s.logger)
+				if err != nil {
+					errorChannel <- err
+				}
+				continue
+			}
+
+			subDisabled, subEnabled, err = resubscribeToVerifierStatusChanges(subDisabled, subEnabled, s.AvsContractBindings.ServiceManager, verifierDisabledChan, verifierEnabledChan, s.logger)
+			if err != nil {
+				errorChannel <- err
+			}
+		}
+	}()
+
+	return errorChannel, nil
+}
+
+func resubscribeToVerifierStatusChanges(
+	subDisabled event.Subscription,
+	subEnabled event.Subscription,
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+	verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+	logger sdklogging.Logger,
+) (event.Subscription, event.Subscription, error) {
+	subDisabled.Unsubscribe()
+	subEnabled.Unsubscribe()
+	return subscribeToVerifierStatusChanges(serviceManager, verifierDisabledChan, verifierEnabledChan, logger)
+}
+
+func subscribeToVerifierStatusChanges(
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+	verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+	logger sdklogging.Logger,
+) (event.Subscription, event.Subscription, error) {
+	for i := 0; i < MaxRetries; i++ {
+		subDisabled, err := serviceManager.WatchVerifierDisabled(
+			&bind.WatchOpts{}, verifierDisabledChan, nil,
+		)
+		if err != nil {
+			logger.Warn("Failed to subscribe to VerifierDisabled events", "err", err)
+			time.Sleep(RetryInterval)
+			continue
+		}
+
+		subEnabled, err := serviceManager.WatchVerifierEnabled(
+			&bind.WatchOpts{}, verifierEnabledChan, nil,
+		)
+		if err != nil {
+			subDisabled.Unsubscribe()
+			logger.Warn("Failed to subscribe to VerifierEnabled events", "err", err)
+			time.Sleep(RetryInterval)
+			continue
+		}
+
+		logger.Info("Subscribed to verifier status changes")
+		return subDisabled, subEnabled, nil
+	}
+
+	return nil, nil, fmt.Errorf("failed to subscribe to verifier status changes after %d retries", MaxRetries)
+}
+
+func subscribeToBatchVerified(
+	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
+	batchVerifiedChan chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified,
+	logger sdklogging.Logger,
+) (event.Subscription, error) {
+	for i := 0; i < MaxRetries; i++ {
+		sub, err := serviceManager.WatchBatchVerified(
+			&bind.WatchOpts{}, batchVerifiedChan, nil,
+		)
+		if err != nil {
+			logger.Warn("Failed to subscribe to BatchVerified events", "err", err)
+			time.Sleep(RetryInterval)
+			continue
+		}
+
+		logger.Info("Subscribed to BatchVerified events")
+		return sub, nil
+	}
+
+	return nil, fmt.Errorf("failed to subscribe to BatchVerified events after %d retries", MaxRetries)
+}
+
 func subscribeToNewTasksV2(
 	serviceManager *servicemanager.ContractAlignedLayerServiceManager,
 	newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2,
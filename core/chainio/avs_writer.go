@@ -2,6 +2,7 @@ package chainio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -18,6 +19,38 @@ import (
 	"github.com/yetanotherco/aligned_layer/core/config"
 )
 
+// Reasons SendAggregatedResponse refuses to send a response, matched with errors.Is
+var (
+	ErrSimulationFailed     = errors.New("transaction simulation failed")
+	ErrFeeLimitExceeded     = errors.New("cost of transaction is higher than Batch.RespondToTaskFeeLimit")
+	ErrAggregatorBalanceLow = errors.New("cost is higher than Aggregator balance")
+	ErrBatcherBalanceLow    = errors.New("cost is higher than Batcher balance")
+	ErrSimulationOnly       = errors.New("writer is simulation only, transaction not sent")
+)
+
+// AggregatedResponseSimulation is the outcome of simulating a respondToTask transaction
+type AggregatedResponseSimulation struct {
+	// Signed but never sent
+	Transaction *types.Transaction
+	GasLimit    uint64
+	// Gas limit times gas price
+	Cost *big.Int
+	// nil if it could not be read
+	RespondToTaskFeeLimit *big.Int
+}
+
+// AvsWriterer is how the aggregator sends the aggregated responses.
+// AvsWriter implements it against Ethereum, and the fakes package in memory.
+type AvsWriterer interface {
+	// SendAggregatedResponse simulates the response, then sends it
+	SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*types.Receipt, error)
+	// SendAggregatedResponseSimulation sends a simulated response, calling onSent with every transaction sent for it
+	SendAggregatedResponseSimulation(ctx context.Context, simulation *AggregatedResponseSimulation, onSent func(tx *types.Transaction)) (*types.Receipt, error)
+	SimulateAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*AggregatedResponseSimulation, error)
+	EstimateAggregatedResponseGas(batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (uint64, error)
+	AggregatorBalance(ctx context.Context) (*big.Int, error)
+}
+
 type AvsWriter struct {
 	*avsregistry.ChainWriter
 	AvsContractBindings *AvsServiceBindings
@@ -25,6 +58,13 @@ type AvsWriter struct {
 	Signer              signer.Signer
 	Client              eth.InstrumentedClient
 	ClientFallback      eth.InstrumentedClient
+	// Sends the aggregated responses, tracking the nonces of the signer account
+	TxManager *TxManager
+	// Optional, called with the simulated cost of each response and the batch RespondToTaskFeeLimit,
+	// which is nil if it could not be read
+	OnSimulatedCost func(simulatedCost *big.Int, respondToTaskFeeLimit *big.Int)
+	// If set, SendAggregatedResponse only simulates the transaction and returns ErrSimulationOnly
+	SimulationOnly bool
 }
 
 func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig) (*AvsWriter, error) {
@@ -60,6 +100,8 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 
 	chainWriter := clients.AvsRegistryChainWriter
 
+	txManager := NewTxManager(&baseConfig.EthRpcClient, &baseConfig.EthRpcClientFallback, privateKeySigner.GetTxOpts(), baseConfig.ChainId, baseConfig.Logger)
+
 	return &AvsWriter{
 		ChainWriter:         chainWriter,
 		AvsContractBindings: avsServiceBindings,
@@ -67,44 +109,95 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 		Signer:              privateKeySigner,
 		Client:              baseConfig.EthRpcClient,
 		ClientFallback:      baseConfig.EthRpcClientFallback,
+		TxManager:           txManager,
 	}, nil
 }
 
-func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*common.Hash, error) {
-	txOpts := *w.Signer.GetTxOpts()
-	txOpts.NoSend = true // simulate the transaction
-	tx, err := w.AvsContractBindings.ServiceManager.RespondToTaskV2(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+// SendAggregatedResponse simulates the respondToTask transaction, checks its cost can be paid, and sends
+// it through the TxManager. It returns once the transaction is included.
+func (w *AvsWriter) SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*types.Receipt, error) {
+	simulation, err := w.SimulateAggregatedResponse(batchIdentifierHash, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
 	if err != nil {
-		// Retry with fallback
-		tx, err = w.AvsContractBindings.ServiceManagerFallback.RespondToTaskV2(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
-		if err != nil {
-			return nil, fmt.Errorf("transaction simulation failed: %v", err)
-		}
+		return nil, err
+	}
+	return w.SendAggregatedResponseSimulation(ctx, simulation, nil)
+}
+
+// SendAggregatedResponseSimulation sends the transaction of a simulation that passed the cost checks.
+// If onSent is not nil, it is called with every transaction sent, including the replacements.
+func (w *AvsWriter) SendAggregatedResponseSimulation(ctx context.Context, simulation *AggregatedResponseSimulation, onSent func(tx *types.Transaction)) (*types.Receipt, error) {
+	if w.SimulationOnly {
+		w.logger.Info("Simulation only, not sending aggregated response", "gas", simulation.GasLimit, "cost", simulation.Cost)
+		return nil, ErrSimulationOnly
+	}
+
+	tx := simulation.Transaction
+	respondToTaskFeeLimit := simulation.RespondToTaskFeeLimit
+	gasLimit := tx.Gas() * 110 / 100 // Add 10% to the gas limit
+
+	// The contract reverts if the cost goes over the fee limit, so bumps are capped below it.
+	// The contract adds 70k gas to the used gas when computing the cost
+	var maxFeePerGas *big.Int
+	if respondToTaskFeeLimit != nil {
+		maxFeePerGas = new(big.Int).Div(respondToTaskFeeLimit, new(big.Int).SetUint64(gasLimit+70_000))
 	}
 
-	err = w.checkRespondToTaskFeeLimit(tx, txOpts, batchIdentifierHash, senderAddress)
+	return w.TxManager.Send(ctx, *tx.To(), tx.Data(), gasLimit, maxFeePerGas, onSent)
+}
+
+// SimulateAggregatedResponse runs the simulation and cost checks of SendAggregatedResponse without sending
+// the transaction. If a cost check fails, the simulation is returned along with the error.
+func (w *AvsWriter) SimulateAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*AggregatedResponseSimulation, error) {
+	tx, txOpts, err := w.simulateAggregatedResponse(batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
 	if err != nil {
 		return nil, err
 	}
 
-	// Send the transaction
-	txOpts.NoSend = false
-	txOpts.GasLimit = tx.Gas() * 110 / 100 // Add 10% to the gas limit
-	tx, err = w.AvsContractBindings.ServiceManager.RespondToTaskV2(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+	simulation := &AggregatedResponseSimulation{
+		Transaction: tx,
+		GasLimit:    tx.Gas(),
+		Cost:        new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()),
+	}
+	simulation.RespondToTaskFeeLimit, err = w.checkRespondToTaskFeeLimit(tx, txOpts, batchIdentifierHash, senderAddress)
+	return simulation, err
+}
+
+// EstimateAggregatedResponseGas simulates the respondToTask transaction and returns the gas it would use
+func (w *AvsWriter) EstimateAggregatedResponseGas(batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (uint64, error) {
+	tx, _, err := w.simulateAggregatedResponse(batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+	if err != nil {
+		return 0, err
+	}
+	return tx.Gas(), nil
+}
+
+func (w *AvsWriter) simulateAggregatedResponse(batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*types.Transaction, bind.TransactOpts, error) {
+	txOpts := *w.Signer.GetTxOpts()
+	txOpts.NoSend = true // simulate the transaction
+	tx, err := w.AvsContractBindings.ServiceManager.RespondToTaskV2(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
 	if err != nil {
 		// Retry with fallback
 		tx, err = w.AvsContractBindings.ServiceManagerFallback.RespondToTaskV2(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
 		if err != nil {
-			return nil, err
+			return nil, txOpts, fmt.Errorf("%w: %v", ErrSimulationFailed, err)
 		}
 	}
+	return tx, txOpts, nil
+}
 
-	txHash := tx.Hash()
-
-	return &txHash, nil
+// AggregatorBalance returns the balance of the account that sends the aggregated responses
+func (w *AvsWriter) AggregatorBalance(ctx context.Context) (*big.Int, error) {
+	aggregatorAddress := w.Signer.GetTxOpts().From
+	balance, err := w.Client.BalanceAt(ctx, aggregatorAddress, nil)
+	if err != nil {
+		balance, err = w.ClientFallback.BalanceAt(ctx, aggregatorAddress, nil)
+	}
+	return balance, err
 }
 
-func (w *AvsWriter) checkRespondToTaskFeeLimit(tx *types.Transaction, txOpts bind.TransactOpts, batchIdentifierHash [32]byte, senderAddress [20]byte) error {
+// checkRespondToTaskFeeLimit returns the batch RespondToTaskFeeLimit, or nil if it could not be read.
+// The limit is also returned if the cost goes over it
+func (w *AvsWriter) checkRespondToTaskFeeLimit(tx *types.Transaction, txOpts bind.TransactOpts, batchIdentifierHash [32]byte, senderAddress [20]byte) (*big.Int, error) {
 	aggregatorAddress := txOpts.From
 	simulatedCost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
 	w.logger.Info("Simulated cost", "cost", simulatedCost)
@@ -119,19 +212,25 @@ func (w *AvsWriter) checkRespondToTaskFeeLimit(tx *types.Transaction, txOpts bin
 			// Proceed to check values against simulated costs
 			w.logger.Error("Failed to get batch state", "error", err)
 			w.logger.Info("Proceeding with simulated cost checks")
-			return w.compareBalances(simulatedCost, aggregatorAddress, senderAddress)
+			if w.OnSimulatedCost != nil {
+				w.OnSimulatedCost(simulatedCost, nil)
+			}
+			return nil, w.compareBalances(simulatedCost, aggregatorAddress, senderAddress)
 		}
 	}
 	// At this point, batchState was successfully retrieved
 	// Proceed to check values against RespondToTaskFeeLimit
 	respondToTaskFeeLimit := batchState.RespondToTaskFeeLimit
 	w.logger.Info("Batch RespondToTaskFeeLimit", "RespondToTaskFeeLimit", respondToTaskFeeLimit)
+	if w.OnSimulatedCost != nil {
+		w.OnSimulatedCost(simulatedCost, respondToTaskFeeLimit)
+	}
 
 	if respondToTaskFeeLimit.Cmp(simulatedCost) < 0 {
-		return fmt.Errorf("cost of transaction is higher than Batch.RespondToTaskFeeLimit")
+		return respondToTaskFeeLimit, ErrFeeLimitExceeded
 	}
 
-	return w.compareBalances(respondToTaskFeeLimit, aggregatorAddress, senderAddress)
+	return respondToTaskFeeLimit, w.compareBalances(respondToTaskFeeLimit, aggregatorAddress, senderAddress)
 }
 
 func (w *AvsWriter) compareBalances(amount *big.Int, aggregatorAddress common.Address, senderAddress [20]byte) error {
@@ -159,7 +258,7 @@ func (w *AvsWriter) compareAggregatorBalance(amount *big.Int, aggregatorAddress
 	}
 	w.logger.Info("Aggregator balance", "balance", aggregatorBalance)
 	if aggregatorBalance.Cmp(amount) < 0 {
-		return fmt.Errorf("cost is higher than Aggregator balance")
+		return ErrAggregatorBalanceLow
 	}
 	return nil
 }
@@ -177,7 +276,7 @@ func (w *AvsWriter) compareBatcherBalance(amount *big.Int, senderAddress [20]byt
 	}
 	w.logger.Info("Batcher balance", "balance", batcherBalance)
 	if batcherBalance.Cmp(amount) < 0 {
-		return fmt.Errorf("cost is higher than Batcher balance")
+		return ErrBatcherBalanceLow
 	}
 	return nil
 }
@@ -0,0 +1,237 @@
+// Package fakes has in-memory implementations of the chainio interfaces, so the operator and the
+// aggregator can be tested without a chain. Tests set the contract state and emit the events.
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+var _ chainio.AvsReaderer = (*FakeAvsReader)(nil)
+
+// FakeAvsReader is the contract state as set by the test. Batches are responded by
+// SetBatchResponded, or by a FakeAvsWriter sending their response.
+type FakeAvsReader struct {
+	mutex       sync.Mutex
+	blockNumber uint64
+	// Closed and replaced every time the block number moves forward
+	newBlock              chan struct{}
+	quorumCount           uint8
+	disabledVerifiers     *big.Int
+	restakeableStrategies []ethcommon.Address
+	registeredOperators   map[ethcommon.Address]bool
+	operatorAddresses     map[eigentypes.OperatorId]ethcommon.Address
+	respondedBatches      map[[32]byte]bool
+	tasks                 []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	// Returned by every call if set
+	err error
+}
+
+func NewFakeAvsReader() *FakeAvsReader {
+	return &FakeAvsReader{
+		newBlock:            make(chan struct{}),
+		quorumCount:         1,
+		disabledVerifiers:   new(big.Int),
+		registeredOperators: make(map[ethcommon.Address]bool),
+		operatorAddresses:   make(map[eigentypes.OperatorId]ethcommon.Address),
+		respondedBatches:    make(map[[32]byte]bool),
+	}
+}
+
+// BatchIdentifierHash is the hash the service manager identifies the batch of a task with
+func BatchIdentifierHash(batchMerkleRoot [32]byte, senderAddress [20]byte) [32]byte {
+	batchIdentifier := append(batchMerkleRoot[:], senderAddress[:]...)
+	return *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+}
+
+// SetError makes every call fail with err, until it is set to nil
+func (r *FakeAvsReader) SetError(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.err = err
+}
+
+// SetBlockNumber sets the latest block. It can't move backwards.
+func (r *FakeAvsReader) SetBlockNumber(blockNumber uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.setBlockNumber(blockNumber)
+}
+
+// MineBlock moves the latest block forward by one
+func (r *FakeAvsReader) MineBlock() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.setBlockNumber(r.blockNumber + 1)
+}
+
+// setBlockNumber must be called with the mutex held
+func (r *FakeAvsReader) setBlockNumber(blockNumber uint64) {
+	if blockNumber <= r.blockNumber {
+		return
+	}
+	r.blockNumber = blockNumber
+	close(r.newBlock)
+	r.newBlock = make(chan struct{})
+}
+
+// waitForBlockAfter returns once the latest block is past startBlock
+func (r *FakeAvsReader) waitForBlockAfter(startBlock uint64) {
+	for {
+		r.mutex.Lock()
+		if r.blockNumber > startBlock {
+			r.mutex.Unlock()
+			return
+		}
+		newBlock := r.newBlock
+		r.mutex.Unlock()
+		<-newBlock
+	}
+}
+
+func (r *FakeAvsReader) SetQuorumCount(quorumCount uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.quorumCount = quorumCount
+}
+
+func (r *FakeAvsReader) SetDisabledVerifiers(bitmap *big.Int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.disabledVerifiers = new(big.Int).Set(bitmap)
+}
+
+func (r *FakeAvsReader) SetRestakeableStrategies(strategies []ethcommon.Address) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.restakeableStrategies = append([]ethcommon.Address(nil), strategies...)
+}
+
+// RegisterOperator registers the operator, with its id
+func (r *FakeAvsReader) RegisterOperator(operatorId eigentypes.OperatorId, address ethcommon.Address) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.registeredOperators[address] = true
+	r.operatorAddresses[operatorId] = address
+}
+
+func (r *FakeAvsReader) SetBatchResponded(batchIdentifierHash [32]byte, responded bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.respondedBatches[batchIdentifierHash] = responded
+}
+
+// AddTask creates a task at its Raw.BlockNumber, moving the block number forward if it is behind
+func (r *FakeAvsReader) AddTask(task servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.tasks = append(r.tasks, task)
+	r.setBlockNumber(task.Raw.BlockNumber)
+}
+
+func (r *FakeAvsReader) IsOperatorRegistered(address ethcommon.Address) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return false, r.err
+	}
+	return r.registeredOperators[address], nil
+}
+
+func (r *FakeAvsReader) GetOperatorFromId(opts *bind.CallOpts, operatorId eigentypes.OperatorId) (ethcommon.Address, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return ethcommon.Address{}, r.err
+	}
+	// The registry coordinator returns the zero address for unknown operators
+	return r.operatorAddresses[operatorId], nil
+}
+
+func (r *FakeAvsReader) CheckQuorumsExist(quorumNumbers []uint8) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	for _, quorumNumber := range quorumNumbers {
+		if quorumNumber >= r.quorumCount {
+			return fmt.Errorf("quorum %d does not exist, the registry coordinator has %d quorums", quorumNumber, r.quorumCount)
+		}
+	}
+	return nil
+}
+
+func (r *FakeAvsReader) DisabledVerifiers() (*big.Int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return new(big.Int).Set(r.disabledVerifiers), nil
+}
+
+func (r *FakeAvsReader) GetRestakeableStrategies() ([]ethcommon.Address, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return append([]ethcommon.Address(nil), r.restakeableStrategies...), nil
+}
+
+func (r *FakeAvsReader) IsBatchResponded(batchIdentifierHash [32]byte) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return false, r.err
+	}
+	return r.respondedBatches[batchIdentifierHash], nil
+}
+
+func (r *FakeAvsReader) GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.getNotRespondedTasks(fromBlock, r.blockNumber)
+}
+
+func (r *FakeAvsReader) GetNotRespondedTasksBetween(fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.getNotRespondedTasks(fromBlock, toBlock)
+}
+
+func (r *FakeAvsReader) BlockNumber(ctx context.Context) (uint64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.blockNumber, nil
+}
+
+// getNotRespondedTasks must be called with the mutex held
+func (r *FakeAvsReader) getNotRespondedTasks(fromBlock uint64, toBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var tasks []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	for _, task := range r.tasks {
+		if task.Raw.BlockNumber < fromBlock || task.Raw.BlockNumber > toBlock {
+			continue
+		}
+		if !r.respondedBatches[BatchIdentifierHash(task.BatchMerkleRoot, task.SenderAddress)] {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
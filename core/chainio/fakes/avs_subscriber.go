@@ -0,0 +1,158 @@
+package fakes
+
+import (
+	"errors"
+	"sync"
+
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+var _ chainio.AvsSubscriberer = (*FakeAvsSubscriber)(nil)
+
+// ErrNotSubscribed is returned when emitting an event nobody subscribed to
+var ErrNotSubscribed = errors.New("no subscription to the event")
+
+// FakeAvsSubscriber forwards the events emitted by the test to the channels of the last subscription
+// to each of them. Emitting blocks until the event is received, like the AvsSubscriber.
+type FakeAvsSubscriber struct {
+	mutex            sync.Mutex
+	reader           *FakeAvsReader
+	newTasksV2       chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
+	newTasksV3       chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	batchVerified    chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified
+	verifierDisabled chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled
+	verifierEnabled  chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled
+	errorChannels    []chan error
+	subscribeErr     error
+}
+
+// NewFakeAvsSubscriber follows the blocks of the reader. If reader is nil, WaitForOneBlock returns at once.
+func NewFakeAvsSubscriber(reader *FakeAvsReader) *FakeAvsSubscriber {
+	return &FakeAvsSubscriber{reader: reader}
+}
+
+// SetSubscribeError makes the subscriptions fail with err, until it is set to nil
+func (s *FakeAvsSubscriber) SetSubscribeError(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscribeErr = err
+}
+
+func (s *FakeAvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
+	return s.subscribe(func() { s.newTasksV2 = newTaskCreatedChan })
+}
+
+func (s *FakeAvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (chan error, error) {
+	return s.subscribe(func() { s.newTasksV3 = newTaskCreatedChan })
+}
+
+func (s *FakeAvsSubscriber) SubscribeToBatchVerified(batchVerifiedChan chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified) (chan error, error) {
+	return s.subscribe(func() { s.batchVerified = batchVerifiedChan })
+}
+
+func (s *FakeAvsSubscriber) SubscribeToVerifierStatusChanges(
+	verifierDisabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled,
+	verifierEnabledChan chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled,
+) (chan error, error) {
+	return s.subscribe(func() {
+		s.verifierDisabled = verifierDisabledChan
+		s.verifierEnabled = verifierEnabledChan
+	})
+}
+
+func (s *FakeAvsSubscriber) WaitForOneBlock(startBlock uint64) error {
+	if s.reader != nil {
+		s.reader.waitForBlockAfter(startBlock)
+	}
+	return nil
+}
+
+// EmitNewBatchV2 sends the batch to the V2 subscription
+func (s *FakeAvsSubscriber) EmitNewBatchV2(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
+	s.mutex.Lock()
+	newTasks := s.newTasksV2
+	s.mutex.Unlock()
+	if newTasks == nil {
+		return ErrNotSubscribed
+	}
+	newTasks <- batch
+	return nil
+}
+
+// EmitNewBatchV3 sends the batch to the V3 subscription. The reader, if any, also gets it as a task,
+// even without a subscription, so it is returned by the queries for not responded tasks.
+func (s *FakeAvsSubscriber) EmitNewBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
+	if s.reader != nil {
+		s.reader.AddTask(*batch)
+	}
+	s.mutex.Lock()
+	newTasks := s.newTasksV3
+	s.mutex.Unlock()
+	if newTasks == nil {
+		return ErrNotSubscribed
+	}
+	newTasks <- batch
+	return nil
+}
+
+// EmitBatchVerified sends the event to the BatchVerified subscription
+func (s *FakeAvsSubscriber) EmitBatchVerified(batchVerified *servicemanager.ContractAlignedLayerServiceManagerBatchVerified) error {
+	s.mutex.Lock()
+	batchVerifiedChan := s.batchVerified
+	s.mutex.Unlock()
+	if batchVerifiedChan == nil {
+		return ErrNotSubscribed
+	}
+	batchVerifiedChan <- batchVerified
+	return nil
+}
+
+// EmitVerifierDisabled sends the event to the verifier status subscription
+func (s *FakeAvsSubscriber) EmitVerifierDisabled(verifierDisabled *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled) error {
+	s.mutex.Lock()
+	verifierDisabledChan := s.verifierDisabled
+	s.mutex.Unlock()
+	if verifierDisabledChan == nil {
+		return ErrNotSubscribed
+	}
+	verifierDisabledChan <- verifierDisabled
+	return nil
+}
+
+// EmitVerifierEnabled sends the event to the verifier status subscription
+func (s *FakeAvsSubscriber) EmitVerifierEnabled(verifierEnabled *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled) error {
+	s.mutex.Lock()
+	verifierEnabledChan := s.verifierEnabled
+	s.mutex.Unlock()
+	if verifierEnabledChan == nil {
+		return ErrNotSubscribed
+	}
+	verifierEnabledChan <- verifierEnabled
+	return nil
+}
+
+// FailSubscriptions sends err on the error channel of every subscription, as if the connection was lost.
+// Subscribers are expected to subscribe again.
+func (s *FakeAvsSubscriber) FailSubscriptions(err error) {
+	s.mutex.Lock()
+	errorChannels := s.errorChannels
+	s.errorChannels = nil
+	s.mutex.Unlock()
+	for _, errorChannel := range errorChannels {
+		errorChannel <- err
+	}
+}
+
+func (s *FakeAvsSubscriber) subscribe(setChannels func()) (chan error, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.subscribeErr != nil {
+		return nil, s.subscribeErr
+	}
+	setChannels()
+	// Buffered, so failing a subscription nobody is reading errors from doesn't block
+	errorChannel := make(chan error, 1)
+	s.errorChannels = append(s.errorChannels, errorChannel)
+	return errorChannel, nil
+}
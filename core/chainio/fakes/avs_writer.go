@@ -0,0 +1,242 @@
+package fakes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+)
+
+var _ chainio.AvsWriterer = (*FakeAvsWriter)(nil)
+
+// Used when the test does not set them
+const (
+	DefaultFakeGasLimit uint64 = 300_000
+	DefaultFakeGasPrice int64  = 1_000_000_000
+)
+
+// AggregatedResponse is a response sent by a FakeAvsWriter
+type AggregatedResponse struct {
+	BatchIdentifierHash         [32]byte
+	BatchMerkleRoot             [32]byte
+	SenderAddress               [20]byte
+	NonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature
+	TxHash                      common.Hash
+}
+
+// FakeAvsWriter runs the cost checks of the AvsWriter against the balances and fee limits set by the test,
+// and records the responses it sends. Every response is included at once, and marks its batch as
+// responded on the reader, if any.
+type FakeAvsWriter struct {
+	mutex             sync.Mutex
+	reader            *FakeAvsReader
+	gasLimit          uint64
+	gasPrice          *big.Int
+	aggregatorBalance *big.Int
+	// Batchers without a balance can pay any cost
+	batcherBalances map[[20]byte]*big.Int
+	// Batches without a fee limit are checked against the simulated cost
+	feeLimits     map[[32]byte]*big.Int
+	simulationErr error
+	sendErr       error
+	nonce         uint64
+	// Simulated responses by the hash of their simulation transaction
+	simulated map[common.Hash]AggregatedResponse
+	responses []AggregatedResponse
+	// If set, responses are only simulated and ErrSimulationOnly is returned, like the AvsWriter
+	SimulationOnly bool
+}
+
+// NewFakeAvsWriter returns a writer with an aggregator balance of 1 ether. reader may be nil.
+func NewFakeAvsWriter(reader *FakeAvsReader) *FakeAvsWriter {
+	return &FakeAvsWriter{
+		reader:            reader,
+		gasLimit:          DefaultFakeGasLimit,
+		gasPrice:          big.NewInt(DefaultFakeGasPrice),
+		aggregatorBalance: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil),
+		batcherBalances:   make(map[[20]byte]*big.Int),
+		feeLimits:         make(map[[32]byte]*big.Int),
+		simulated:         make(map[common.Hash]AggregatedResponse),
+	}
+}
+
+// SetGas sets the gas limit and gas price of the simulated transactions
+func (w *FakeAvsWriter) SetGas(gasLimit uint64, gasPrice *big.Int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.gasLimit = gasLimit
+	w.gasPrice = new(big.Int).Set(gasPrice)
+}
+
+func (w *FakeAvsWriter) SetAggregatorBalance(balance *big.Int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.aggregatorBalance = new(big.Int).Set(balance)
+}
+
+func (w *FakeAvsWriter) SetBatcherBalance(senderAddress [20]byte, balance *big.Int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.batcherBalances[senderAddress] = new(big.Int).Set(balance)
+}
+
+func (w *FakeAvsWriter) SetRespondToTaskFeeLimit(batchIdentifierHash [32]byte, feeLimit *big.Int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.feeLimits[batchIdentifierHash] = new(big.Int).Set(feeLimit)
+}
+
+// SetSimulationError makes the simulations fail with ErrSimulationFailed, until it is set to nil
+func (w *FakeAvsWriter) SetSimulationError(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.simulationErr = err
+}
+
+// SetSendError makes the sends fail with err after the simulation, until it is set to nil
+func (w *FakeAvsWriter) SetSendError(err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.sendErr = err
+}
+
+// Responses returns the responses sent, in order
+func (w *FakeAvsWriter) Responses() []AggregatedResponse {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return append([]AggregatedResponse(nil), w.responses...)
+}
+
+func (w *FakeAvsWriter) SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*types.Receipt, error) {
+	simulation, err := w.SimulateAggregatedResponse(batchIdentifierHash, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature)
+	if err != nil {
+		return nil, err
+	}
+	return w.SendAggregatedResponseSimulation(ctx, simulation, nil)
+}
+
+func (w *FakeAvsWriter) SendAggregatedResponseSimulation(ctx context.Context, simulation *chainio.AggregatedResponseSimulation, onSent func(tx *types.Transaction)) (*types.Receipt, error) {
+	if w.SimulationOnly {
+		return nil, chainio.ErrSimulationOnly
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	w.mutex.Lock()
+	response, ok := w.simulated[simulation.Transaction.Hash()]
+	if !ok {
+		w.mutex.Unlock()
+		return nil, errors.New("simulation was not made by this writer")
+	}
+	if w.sendErr != nil {
+		err := w.sendErr
+		w.mutex.Unlock()
+		return nil, err
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		Nonce:     w.nonce,
+		Gas:       simulation.GasLimit,
+		GasFeeCap: simulation.Transaction.GasFeeCap(),
+		GasTipCap: simulation.Transaction.GasTipCap(),
+		Data:      simulation.Transaction.Data(),
+	})
+	w.nonce++
+	response.TxHash = tx.Hash()
+	w.responses = append(w.responses, response)
+	w.mutex.Unlock()
+
+	if onSent != nil {
+		onSent(tx)
+	}
+	var blockNumber uint64
+	if w.reader != nil {
+		w.reader.SetBatchResponded(response.BatchIdentifierHash, true)
+		blockNumber, _ = w.reader.BlockNumber(ctx)
+	}
+	return &types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            tx.Hash(),
+		GasUsed:           tx.Gas(),
+		EffectiveGasPrice: tx.GasFeeCap(),
+		BlockNumber:       new(big.Int).SetUint64(blockNumber),
+	}, nil
+}
+
+func (w *FakeAvsWriter) SimulateAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*chainio.AggregatedResponseSimulation, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	tx, err := w.simulateAggregatedResponse(batchIdentifierHash, batchMerkleRoot, senderAddress)
+	if err != nil {
+		return nil, err
+	}
+	w.simulated[tx.Hash()] = AggregatedResponse{
+		BatchIdentifierHash:         batchIdentifierHash,
+		BatchMerkleRoot:             batchMerkleRoot,
+		SenderAddress:               senderAddress,
+		NonSignerStakesAndSignature: nonSignerStakesAndSignature,
+	}
+
+	simulation := &chainio.AggregatedResponseSimulation{
+		Transaction: tx,
+		GasLimit:    tx.Gas(),
+		Cost:        new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice()),
+	}
+
+	// Same checks as the AvsWriter, against the fee limit if set or the cost otherwise
+	amount := simulation.Cost
+	if feeLimit, ok := w.feeLimits[batchIdentifierHash]; ok {
+		simulation.RespondToTaskFeeLimit = new(big.Int).Set(feeLimit)
+		if feeLimit.Cmp(simulation.Cost) < 0 {
+			return simulation, chainio.ErrFeeLimitExceeded
+		}
+		amount = feeLimit
+	}
+	if w.aggregatorBalance.Cmp(amount) < 0 {
+		return simulation, chainio.ErrAggregatorBalanceLow
+	}
+	if batcherBalance, ok := w.batcherBalances[senderAddress]; ok && batcherBalance.Cmp(amount) < 0 {
+		return simulation, chainio.ErrBatcherBalanceLow
+	}
+	return simulation, nil
+}
+
+func (w *FakeAvsWriter) EstimateAggregatedResponseGas(batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (uint64, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	tx, err := w.simulateAggregatedResponse(BatchIdentifierHash(batchMerkleRoot, senderAddress), batchMerkleRoot, senderAddress)
+	if err != nil {
+		return 0, err
+	}
+	return tx.Gas(), nil
+}
+
+func (w *FakeAvsWriter) AggregatorBalance(ctx context.Context) (*big.Int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return new(big.Int).Set(w.aggregatorBalance), nil
+}
+
+// simulateAggregatedResponse returns the unsent transaction of the response, with the batch as its data.
+// It must be called with the mutex held.
+func (w *FakeAvsWriter) simulateAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte) (*types.Transaction, error) {
+	if w.simulationErr != nil {
+		return nil, fmt.Errorf("%w: %v", chainio.ErrSimulationFailed, w.simulationErr)
+	}
+	data := append(append(batchIdentifierHash[:], batchMerkleRoot[:]...), senderAddress[:]...)
+	return types.NewTx(&types.DynamicFeeTx{
+		Gas:       w.gasLimit,
+		GasFeeCap: w.gasPrice,
+		GasTipCap: w.gasPrice,
+		Data:      data,
+	}), nil
+}
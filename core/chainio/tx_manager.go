@@ -0,0 +1,488 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	DefaultTxGasBumpPercentage = 20
+	DefaultTxStuckTimeout      = 36 * time.Second // 3 blocks
+	DefaultTxMaxBumps          = 5
+	// Nodes reject replacements that don't raise both fees by at least 10%
+	minTxGasBumpPercentage = 10
+	txReceiptPollInterval  = 2 * time.Second
+	maxTxNonceResyncs      = 3
+	txNonceResyncTimeout   = 10 * time.Second
+)
+
+// TxManagerConfig sets the fees of the transactions sent by the TxManager.
+// Zero values take the defaults, and a zero fee cap means no cap.
+type TxManagerConfig struct {
+	// Max fee per gas, in wei. Bumps never go over it
+	MaxFeePerGas *big.Int
+	// Max priority fee per gas, in wei
+	MaxPriorityFeePerGas *big.Int
+	// How much fees are raised when a transaction is replaced
+	GasBumpPercentage uint64
+	// How long a transaction waits to be included before it is replaced
+	StuckTimeout time.Duration
+	// Number of replacements before waiting on the sent transactions without bumping again
+	MaxBumps int
+}
+
+// TxBackend is the subset of the eth client used to send transactions
+type TxBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// TxManager sends EIP-1559 transactions from a single account. Nonces are tracked locally, so several
+// transactions can be in flight at once, and transactions not included in time are replaced with bumped fees.
+type TxManager struct {
+	backend         TxBackend
+	backendFallback TxBackend
+	from            common.Address
+	signerFn        bind.SignerFn
+	chainId         *big.Int
+	logger          logging.Logger
+
+	configMutex sync.Mutex
+	config      TxManagerConfig
+
+	// nextNonce is the nonce of the next transaction. It is read from the node when not known
+	nonceMutex sync.Mutex
+	nextNonce  *uint64
+	// Nonces held by Send calls in progress, broadcast or not. The node doesn't know the ones not broadcast yet
+	activeNonces map[uint64]int
+	// Transactions whose Send ended without a receipt, by inFlightTxKey. Sending the same
+	// transaction again replaces them instead of taking a new nonce
+	inFlightTxs map[common.Hash]*inFlightTx
+}
+
+// inFlightTx is a transaction sent with a nonce, and not known to be included or dropped
+type inFlightTx struct {
+	nonce     uint64
+	gasTipCap *big.Int
+	gasFeeCap *big.Int
+	txHashes  []common.Hash
+}
+
+func NewTxManager(backend TxBackend, backendFallback TxBackend, txOpts *bind.TransactOpts, chainId *big.Int, logger logging.Logger) *TxManager {
+	return &TxManager{
+		backend:         backend,
+		backendFallback: backendFallback,
+		from:            txOpts.From,
+		signerFn:        txOpts.Signer,
+		chainId:         chainId,
+		logger:          logger,
+		inFlightTxs:     make(map[common.Hash]*inFlightTx),
+		activeNonces:    make(map[uint64]int),
+	}
+}
+
+// SetConfig replaces the fee configuration. It applies to the next transactions sent
+func (m *TxManager) SetConfig(config TxManagerConfig) {
+	m.configMutex.Lock()
+	defer m.configMutex.Unlock()
+	m.config = config
+}
+
+func (m *TxManager) getConfig() TxManagerConfig {
+	m.configMutex.Lock()
+	config := m.config
+	m.configMutex.Unlock()
+
+	if config.GasBumpPercentage < minTxGasBumpPercentage {
+		config.GasBumpPercentage = DefaultTxGasBumpPercentage
+	}
+	if config.StuckTimeout == 0 {
+		config.StuckTimeout = DefaultTxStuckTimeout
+	}
+	if config.MaxBumps == 0 {
+		config.MaxBumps = DefaultTxMaxBumps
+	}
+	return config
+}
+
+// Send sends a transaction to the given address with the given data and gas limit, and waits until it is
+// included. The transaction is replaced with bumped fees every StuckTimeout, up to MaxBumps times.
+// If maxFeePerGas is not nil, it lowers the configured cap for this transaction.
+// If onSent is not nil, it is called with every transaction sent, including the replacements.
+// Returns an error if the transaction reverts.
+// If the context ends before the transaction is included, the nonce is resynced from the node, and sending
+// the same transaction again replaces the one still in flight with bumped fees instead of taking a new nonce.
+func (m *TxManager) Send(ctx context.Context, to common.Address, data []byte, gasLimit uint64, maxFeePerGas *big.Int, onSent func(tx *types.Transaction)) (*types.Receipt, error) {
+	config := m.getConfig()
+	if maxFeePerGas != nil && (config.MaxFeePerGas == nil || config.MaxFeePerGas.Sign() == 0 || maxFeePerGas.Cmp(config.MaxFeePerGas) < 0) {
+		config.MaxFeePerGas = maxFeePerGas
+	}
+
+	key := inFlightTxKey(to, data)
+	var nonce uint64
+	var sentTxHashes []common.Hash
+	inFlight := m.takeInFlightTx(key)
+	if inFlight != nil {
+		nonce = inFlight.nonce
+		sentTxHashes = inFlight.txHashes
+	} else {
+		var err error
+		if nonce, err = m.reserveNonce(ctx); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { m.releaseNonce(nonce) }()
+
+	gasTipCap, gasFeeCap, err := m.initialFees(ctx, config)
+	if err != nil {
+		if inFlight != nil {
+			m.putInFlightTx(key, inFlight)
+		} else {
+			m.resetNonce(nonce)
+		}
+		return nil, err
+	}
+	if inFlight != nil {
+		// The previous attempt was not included, so the replacement needs higher fees than it
+		bumpedTipCap, bumpedFeeCap := bumpFees(inFlight.gasTipCap, inFlight.gasFeeCap, config)
+		gasTipCap, gasFeeCap = maxBigInt(gasTipCap, bumpedTipCap), maxBigInt(gasFeeCap, bumpedFeeCap)
+		m.logger.Info("Transaction still in flight, replacing it", "nonce", nonce,
+			"gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
+	}
+
+	bumps := 0
+	nonceResyncs := 0
+	for {
+		tx, err := m.signerFn(m.from, types.NewTx(&types.DynamicFeeTx{
+			ChainID:   m.chainId,
+			Nonce:     nonce,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       gasLimit,
+			To:        &to,
+			Data:      data,
+		}))
+		if err != nil {
+			m.resetNonce(nonce)
+			return nil, fmt.Errorf("could not sign transaction: %w", err)
+		}
+
+		err = m.sendTransaction(ctx, tx)
+		switch {
+		case err == nil, isAlreadyKnownError(err):
+			// Resent without bumping once the bumps or the fee caps are exhausted
+			if len(sentTxHashes) == 0 || sentTxHashes[len(sentTxHashes)-1] != tx.Hash() {
+				m.logger.Info("Transaction sent", "txHash", tx.Hash().Hex(), "nonce", nonce,
+					"gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap)
+				sentTxHashes = append(sentTxHashes, tx.Hash())
+				if onSent != nil {
+					onSent(tx)
+				}
+			}
+
+		case isNonceTooLowError(err):
+			if len(sentTxHashes) > 0 {
+				// One of the previous attempts was included, wait for its receipt below
+				m.logger.Info("Nonce already used, one of the sent transactions was included", "nonce", nonce)
+				break
+			}
+			// The nonce was used outside of the manager, resync and start again with a new one
+			m.logger.Warn("Nonce too low, resyncing nonce from the node", "nonce", nonce)
+			m.resetNonce(nonce)
+			if nonceResyncs++; nonceResyncs > maxTxNonceResyncs {
+				return nil, err
+			}
+			newNonce, err := m.reserveNonce(ctx)
+			if err != nil {
+				return nil, err
+			}
+			m.releaseNonce(nonce)
+			nonce = newNonce
+			continue
+
+		case isUnderpricedError(err) && len(sentTxHashes) > 0:
+			// The replacement was not bumped enough for the node, wait and bump again
+			m.logger.Warn("Replacement transaction underpriced", "nonce", nonce, "err", err)
+
+		default:
+			if len(sentTxHashes) == 0 {
+				// Nothing was sent with this nonce, so it can be reused
+				m.resetNonce(nonce)
+				return nil, err
+			}
+			m.logger.Warn("Could not send replacement transaction, waiting on the previous ones", "nonce", nonce, "err", err)
+		}
+
+		receipt, err := m.waitForReceipt(ctx, sentTxHashes, config.StuckTimeout)
+		if err != nil {
+			// The transaction may still be included, or dropped and leave a nonce gap
+			m.putInFlightTx(key, &inFlightTx{nonce: nonce, gasTipCap: gasTipCap, gasFeeCap: gasFeeCap, txHashes: sentTxHashes})
+			m.resyncNonce(nonce)
+			return nil, err
+		}
+		if receipt != nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("transaction %s reverted", receipt.TxHash.Hex())
+			}
+			return receipt, nil
+		}
+
+		if bumps >= config.MaxBumps {
+			continue
+		}
+		bumpedTipCap, bumpedFeeCap := bumpFees(gasTipCap, gasFeeCap, config)
+		if bumpedFeeCap.Cmp(gasFeeCap) == 0 && bumpedTipCap.Cmp(gasTipCap) == 0 {
+			// Already at the fee caps, keep waiting
+			continue
+		}
+		bumps++
+		m.logger.Warn("Transaction not included in time, replacing it with bumped fees",
+			"nonce", nonce, "bump", bumps, "gasTipCap", bumpedTipCap, "gasFeeCap", bumpedFeeCap)
+		gasTipCap, gasFeeCap = bumpedTipCap, bumpedFeeCap
+	}
+}
+
+// reserveNonce returns the next nonce and increments it. The nonce is held until releaseNonce
+func (m *TxManager) reserveNonce(ctx context.Context) (uint64, error) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+
+	if m.nextNonce == nil {
+		nonce, err := m.pendingNonce(ctx)
+		if err != nil {
+			return 0, err
+		}
+		m.nextNonce = &nonce
+	}
+
+	nonce := *m.nextNonce
+	*m.nextNonce++
+	m.activeNonces[nonce]++
+	return nonce, nil
+}
+
+// releaseNonce is called once the Send holding the nonce returns
+func (m *TxManager) releaseNonce(nonce uint64) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+	if m.activeNonces[nonce]--; m.activeNonces[nonce] <= 0 {
+		delete(m.activeNonces, nonce)
+	}
+}
+
+// hasOtherActiveNonceLocked returns true if a Send other than the one holding except holds a nonce from
+// the given one. It must be called with nonceMutex held.
+func (m *TxManager) hasOtherActiveNonceLocked(from uint64, except uint64) bool {
+	for nonce := range m.activeNonces {
+		if nonce >= from && nonce != except {
+			return true
+		}
+	}
+	return false
+}
+
+// resetNonce makes the next transaction read the nonce from the node again. It is used when a reserved
+// nonce is never sent, so later transactions don't get stuck behind the gap. If later nonces are held,
+// the node doesn't know them yet, so the next nonce is kept and the gap is fixed by resyncNonce.
+func (m *TxManager) resetNonce(unsentNonce uint64) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+	if !m.hasOtherActiveNonceLocked(unsentNonce, unsentNonce) {
+		m.nextNonce = nil
+	}
+}
+
+// resyncNonce reads the pending nonce from the node after a transaction was not included in time.
+// The next nonce only goes back to it if the node dropped the transaction and no other Send holds a
+// nonce from it, and the transactions in flight from it are forgotten, so sending them again takes a
+// new nonce. Otherwise the next nonce never goes back, so held nonces are not reserved again.
+func (m *TxManager) resyncNonce(timedOutNonce uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), txNonceResyncTimeout)
+	defer cancel()
+	pendingNonce, err := m.pendingNonce(ctx)
+
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+	if err != nil {
+		m.logger.Warn("Could not resync nonce", "err", err)
+		return
+	}
+
+	dropped := pendingNonce <= timedOutNonce
+	if dropped && !m.hasOtherActiveNonceLocked(pendingNonce, timedOutNonce) {
+		m.nextNonce = &pendingNonce
+		for key, inFlight := range m.inFlightTxs {
+			if inFlight.nonce >= pendingNonce {
+				m.logger.Warn("Transaction dropped by the node, forgetting it", "nonce", inFlight.nonce)
+				delete(m.inFlightTxs, key)
+			}
+		}
+		return
+	}
+	if m.nextNonce != nil && pendingNonce > *m.nextNonce {
+		m.nextNonce = &pendingNonce
+	}
+}
+
+func (m *TxManager) pendingNonce(ctx context.Context) (uint64, error) {
+	nonce, err := m.backend.PendingNonceAt(ctx, m.from)
+	if err != nil {
+		nonce, err = m.backendFallback.PendingNonceAt(ctx, m.from)
+		if err != nil {
+			return 0, fmt.Errorf("could not get pending nonce: %w", err)
+		}
+	}
+	return nonce, nil
+}
+
+// inFlightTxKey identifies a transaction by what it does, so a retry finds the attempt still in flight
+func inFlightTxKey(to common.Address, data []byte) common.Hash {
+	return crypto.Keccak256Hash(to.Bytes(), data)
+}
+
+// takeInFlightTx removes and returns the transaction in flight for the key, nil if there is none.
+// Its nonce is held until releaseNonce
+func (m *TxManager) takeInFlightTx(key common.Hash) *inFlightTx {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+	inFlight := m.inFlightTxs[key]
+	delete(m.inFlightTxs, key)
+	if inFlight != nil {
+		m.activeNonces[inFlight.nonce]++
+	}
+	return inFlight
+}
+
+func (m *TxManager) putInFlightTx(key common.Hash, inFlight *inFlightTx) {
+	m.nonceMutex.Lock()
+	defer m.nonceMutex.Unlock()
+	m.inFlightTxs[key] = inFlight
+}
+
+// initialFees returns the tip suggested by the node and a fee cap of twice the base fee plus the tip,
+// limited by the configured caps
+func (m *TxManager) initialFees(ctx context.Context, config TxManagerConfig) (*big.Int, *big.Int, error) {
+	gasTipCap, err := m.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		gasTipCap, err = m.backendFallback.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get gas tip cap: %w", err)
+		}
+	}
+
+	header, err := m.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		header, err = m.backendFallback.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get latest header: %w", err)
+		}
+	}
+	if header.BaseFee == nil {
+		return nil, nil, errors.New("chain does not support EIP-1559")
+	}
+
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+	gasTipCap, gasFeeCap = capFees(gasTipCap, gasFeeCap, config)
+	return gasTipCap, gasFeeCap, nil
+}
+
+// bumpFees raises both fees by GasBumpPercentage, limited by the configured caps
+func bumpFees(gasTipCap *big.Int, gasFeeCap *big.Int, config TxManagerConfig) (*big.Int, *big.Int) {
+	bump := func(fee *big.Int) *big.Int {
+		bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+config.GasBumpPercentage)))
+		bumped.Div(bumped, big.NewInt(100))
+		// Zero or tiny fees don't grow with a percentage
+		if bumped.Cmp(fee) == 0 {
+			bumped.Add(bumped, big.NewInt(1))
+		}
+		return bumped
+	}
+	return capFees(bump(gasTipCap), bump(gasFeeCap), config)
+}
+
+// capFees limits the fees to the configured caps. The tip is never higher than the fee cap
+func capFees(gasTipCap *big.Int, gasFeeCap *big.Int, config TxManagerConfig) (*big.Int, *big.Int) {
+	if config.MaxFeePerGas != nil && config.MaxFeePerGas.Sign() > 0 && gasFeeCap.Cmp(config.MaxFeePerGas) > 0 {
+		gasFeeCap = new(big.Int).Set(config.MaxFeePerGas)
+	}
+	if config.MaxPriorityFeePerGas != nil && config.MaxPriorityFeePerGas.Sign() > 0 && gasTipCap.Cmp(config.MaxPriorityFeePerGas) > 0 {
+		gasTipCap = new(big.Int).Set(config.MaxPriorityFeePerGas)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap
+}
+
+func maxBigInt(a *big.Int, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func (m *TxManager) sendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := m.backend.SendTransaction(ctx, tx)
+	if err == nil || isAlreadyKnownError(err) || isNonceTooLowError(err) || isUnderpricedError(err) {
+		return err
+	}
+	// Retry with fallback
+	return m.backendFallback.SendTransaction(ctx, tx)
+}
+
+// waitForReceipt polls the receipts of every transaction sent with the nonce, as any of them may be included.
+// Returns a nil receipt if none was included before the timeout.
+func (m *TxManager) waitForReceipt(ctx context.Context, txHashes []common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, txHash := range txHashes {
+			receipt, err := m.backend.TransactionReceipt(ctx, txHash)
+			if err != nil {
+				receipt, err = m.backendFallback.TransactionReceipt(ctx, txHash)
+			}
+			if err == nil && receipt != nil {
+				return receipt, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(txReceiptPollInterval):
+		}
+	}
+}
+
+// Error messages vary between clients, so they are matched by substrings
+func isNonceTooLowError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+func isAlreadyKnownError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "already known") || strings.Contains(message, "already imported")
+}
+
+func isUnderpricedError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "underpriced")
+}
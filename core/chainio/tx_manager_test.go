@@ -0,0 +1,254 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeTxBackend keeps the sent transactions in a mempool and never includes them unless told to
+type fakeTxBackend struct {
+	mutex   sync.Mutex
+	mined   uint64
+	mempool map[uint64]*types.Transaction
+	sent    []*types.Transaction
+	// When set, the next SuggestGasTipCap closes reached and waits for release
+	tipCapGate *tipCapGate
+}
+
+type tipCapGate struct {
+	reached chan struct{}
+	release chan struct{}
+}
+
+func newFakeTxBackend() *fakeTxBackend {
+	return &fakeTxBackend{mempool: make(map[uint64]*types.Transaction)}
+}
+
+func (b *fakeTxBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	nonce := b.mined
+	for b.mempool[nonce] != nil {
+		nonce++
+	}
+	return nonce, nil
+}
+
+func (b *fakeTxBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	b.mutex.Lock()
+	gate := b.tipCapGate
+	b.tipCapGate = nil
+	b.mutex.Unlock()
+	if gate != nil {
+		close(gate.reached)
+		select {
+		case <-gate.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return big.NewInt(1_000), nil
+}
+
+// blockNextTipCap holds the next send after it reserved its nonce and before it is broadcast
+func (b *fakeTxBackend) blockNextTipCap() *tipCapGate {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tipCapGate = &tipCapGate{reached: make(chan struct{}), release: make(chan struct{})}
+	return b.tipCapGate
+}
+
+func (b *fakeTxBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: big.NewInt(10_000)}, nil
+}
+
+func (b *fakeTxBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if tx.Nonce() < b.mined {
+		return errors.New("nonce too low")
+	}
+	if previous := b.mempool[tx.Nonce()]; previous != nil && previous.GasFeeCap().Cmp(tx.GasFeeCap()) >= 0 {
+		return errors.New("replacement transaction underpriced")
+	}
+	b.mempool[tx.Nonce()] = tx
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *fakeTxBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, ethereum.NotFound
+}
+
+// drop removes a transaction from the mempool, as nodes do with transactions that wait too long
+func (b *fakeTxBackend) drop(nonce uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.mempool, nonce)
+}
+
+func (b *fakeTxBackend) sentTransactions() []*types.Transaction {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return append([]*types.Transaction(nil), b.sent...)
+}
+
+func newTestTxManager(t *testing.T, backend *fakeTxBackend) *TxManager {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Could not generate key: %v", err)
+	}
+	chainId := big.NewInt(1)
+	txOpts, err := bind.NewKeyedTransactorWithChainID(key, chainId)
+	if err != nil {
+		t.Fatalf("Could not create transactor: %v", err)
+	}
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	return NewTxManager(backend, backend, txOpts, chainId, logger)
+}
+
+// sendUntilTimeout sends a transaction that is never included before the context expires
+func sendUntilTimeout(t *testing.T, m *TxManager, data []byte) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := m.Send(ctx, common.Address{1}, data, 100_000, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the send to time out, got %v", err)
+	}
+}
+
+// waitForSent waits until the backend received n transactions
+func waitForSent(t *testing.T, backend *fakeTxBackend, n int) []*types.Transaction {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if sent := backend.sentTransactions(); len(sent) >= n {
+			return sent
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d transactions sent, got %d", n, len(backend.sentTransactions()))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// sendTimingOutWhileOtherSendHoldsNonce sends a transaction with nonce 0 that times out while another
+// send holds nonce 1 without having broadcast it. dropFirst makes the node drop the first one before.
+// It returns the nonce taken by a send after the timeout and the one broadcast by the held send.
+func sendTimingOutWhileOtherSendHoldsNonce(t *testing.T, dropFirst bool) (uint64, uint64) {
+	backend := newFakeTxBackend()
+	m := newTestTxManager(t, backend)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, err := m.Send(firstCtx, common.Address{1}, []byte{1}, 100_000, nil, nil)
+		firstDone <- err
+	}()
+	waitForSent(t, backend, 1)
+
+	gate := backend.blockNextTipCap()
+	heldCtx, cancelHeld := context.WithCancel(context.Background())
+	defer cancelHeld()
+	go m.Send(heldCtx, common.Address{1}, []byte{2}, 100_000, nil, nil)
+	<-gate.reached
+
+	if dropFirst {
+		backend.drop(0)
+	}
+	cancelFirst()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the first send to be canceled, got %v", err)
+	}
+
+	sendUntilTimeout(t, m, []byte{3})
+	close(gate.release)
+	sent := waitForSent(t, backend, 3)
+	return sent[1].Nonce(), sent[2].Nonce()
+}
+
+func TestTxManagerResyncKeepsNonceHeldByConcurrentSend(t *testing.T) {
+	next, held := sendTimingOutWhileOtherSendHoldsNonce(t, false)
+	if held != 1 {
+		t.Fatalf("Expected the held send to broadcast nonce 1, got %d", held)
+	}
+	if next != 2 {
+		t.Fatalf("Expected the send after the timeout to take nonce 2, got %d", next)
+	}
+}
+
+func TestTxManagerResyncOfDroppedTransactionKeepsNonceHeldByConcurrentSend(t *testing.T) {
+	next, held := sendTimingOutWhileOtherSendHoldsNonce(t, true)
+	if held != 1 {
+		t.Fatalf("Expected the held send to broadcast nonce 1, got %d", held)
+	}
+	if next != 2 {
+		t.Fatalf("Expected the send after the timeout to take nonce 2, got %d", next)
+	}
+}
+
+func TestTxManagerRetryReplacesTransactionInFlight(t *testing.T) {
+	backend := newFakeTxBackend()
+	m := newTestTxManager(t, backend)
+
+	sendUntilTimeout(t, m, []byte{1})
+	sendUntilTimeout(t, m, []byte{1})
+
+	sent := backend.sentTransactions()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 transactions sent, got %d", len(sent))
+	}
+	if sent[1].Nonce() != sent[0].Nonce() {
+		t.Fatalf("Expected the retry to reuse nonce %d, got %d", sent[0].Nonce(), sent[1].Nonce())
+	}
+	if sent[1].GasFeeCap().Cmp(sent[0].GasFeeCap()) <= 0 || sent[1].GasTipCap().Cmp(sent[0].GasTipCap()) <= 0 {
+		t.Fatalf("Expected the retry to bump the fees, got %v and %v", sent[0].GasFeeCap(), sent[1].GasFeeCap())
+	}
+
+	// Other transactions still take the next nonce
+	sendUntilTimeout(t, m, []byte{2})
+	if sent = backend.sentTransactions(); sent[2].Nonce() != sent[0].Nonce()+1 {
+		t.Fatalf("Expected nonce %d, got %d", sent[0].Nonce()+1, sent[2].Nonce())
+	}
+}
+
+func TestTxManagerResyncsNonceOfDroppedTransaction(t *testing.T) {
+	backend := newFakeTxBackend()
+	m := newTestTxManager(t, backend)
+
+	sendUntilTimeout(t, m, []byte{1})
+	backend.drop(0)
+	// Queued behind the dropped transaction. Its timeout finds the gap
+	sendUntilTimeout(t, m, []byte{2})
+	sendUntilTimeout(t, m, []byte{3})
+	sendUntilTimeout(t, m, []byte{1})
+
+	sent := backend.sentTransactions()
+	if len(sent) != 4 {
+		t.Fatalf("Expected 4 transactions sent, got %d", len(sent))
+	}
+	if sent[1].Nonce() != 1 {
+		t.Fatalf("Expected nonce 1, got %d", sent[1].Nonce())
+	}
+	if sent[2].Nonce() != 0 {
+		t.Fatalf("Expected the nonce of the dropped transaction to be used again, got %d", sent[2].Nonce())
+	}
+	if sent[3].Nonce() != 2 {
+		t.Fatalf("Expected the retry of the dropped transaction to take a new nonce, got %d", sent[3].Nonce())
+	}
+}
@@ -10,34 +10,149 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// Used when the garbage collector limits are not set
+const (
+	DefaultGarbageCollectorTaskRetention = 1 * time.Hour
+	DefaultGarbageCollectorMaxTasks      = 10000
+)
+
+// Used when `pending_response_ttl` and `max_pending_responses` are not set
+const (
+	DefaultPendingResponseTtl  = 2 * time.Minute
+	DefaultMaxPendingResponses = 10000
+)
+
+// Used when `backfill_interval` and `backfill_window_blocks` are not set
+const (
+	DefaultBackfillInterval     = 1 * time.Minute
+	DefaultBackfillWindowBlocks = 300
+)
+
+// Used when the telemetry queue settings are not set
+const (
+	DefaultTelemetryQueueSize      = 1000
+	DefaultTelemetryBatchSize      = 50
+	DefaultTelemetryFlushInterval  = 1 * time.Second
+	DefaultTelemetryRequestTimeout = 5 * time.Second
+	DefaultTelemetryMaxRetries     = 3
+)
+
+// Used when the aggregator RPC limits are not set
+const (
+	DefaultRpcRateLimitBurst = 20
+	DefaultRpcMaxRequestSize = 16 * 1024
+	DefaultRpcWorkers        = 64
+	DefaultRpcQueueSize      = 1024
+)
+
 type AggregatorConfig struct {
 	BaseConfig  *BaseConfig
 	EcdsaConfig *EcdsaConfig
 	BlsConfig   *BlsConfig
 	Aggregator  struct {
-		ServerIpPortAddress           string
-		BlsPublicKeyCompendiumAddress common.Address
-		AvsServiceManagerAddress      common.Address
-		EnableMetrics                 bool
-		MetricsIpPortAddress          string
-		TelemetryIpPortAddress        string
-		GarbageCollectorPeriod        time.Duration
-		GarbageCollectorTasksAge      uint64
-		GarbageCollectorTasksInterval uint64
+		ServerIpPortAddress             string
+		ServerTlsCertPath               string
+		ServerTlsKeyPath                string
+		ServerTlsClientCaPath           string
+		RequireSignedRequests           bool
+		RpcRateLimitPerIp               float64
+		RpcRateLimitPerOperator         float64
+		RpcRateLimitBurst               int
+		RpcMaxRequestSize               int64
+		RpcWorkers                      int
+		RpcQueueSize                    int
+		JsonRpcServerIpPortAddress      string
+		AdminApiIpPortAddress           string
+		AdminApiToken                   string
+		ShadowMode                      bool
+		BlsPublicKeyCompendiumAddress   common.Address
+		AvsServiceManagerAddress        common.Address
+		EnableMetrics                   bool
+		MetricsIpPortAddress            string
+		TelemetryIpPortAddress          string
+		OtlpTracesEndpoint              string
+		TelemetryQueueSize              int
+		TelemetryBatchSize              int
+		TelemetryFlushInterval          time.Duration
+		TelemetryRequestTimeout         time.Duration
+		TelemetryMaxRetries             int
+		GarbageCollectorPeriod          time.Duration
+		GarbageCollectorTaskRetention   time.Duration
+		GarbageCollectorMaxTasks        int
+		TaskStorePath                   string
+		DeadLetterStorePath             string
+		AuditLogPath                    string
+		LeaderLockPath                  string
+		LeaderLockEtcdUrl               string
+		LeaderLockKey                   string
+		LeaderLockTtl                   time.Duration
+		BackfillCheckpointPath          string
+		BackfillInterval                time.Duration
+		BackfillWindowBlocks            uint64
+		QuorumNumbers                   []uint8
+		QuorumThresholdPercentages      []uint8
+		PostQuorumCollectionWindow      time.Duration
+		PostQuorumTargetStakePercentage uint8
+		PendingResponseTtl              time.Duration
+		MaxPendingResponses             int
+		TxMaxFeePerGas                  uint64
+		TxMaxPriorityFeePerGas          uint64
+		TxGasBumpPercentage             uint64
+		TxStuckTimeout                  time.Duration
 	}
 }
 
 type AggregatorConfigFromYaml struct {
 	Aggregator struct {
-		ServerIpPortAddress           string         `yaml:"server_ip_port_address"`
-		BlsPublicKeyCompendiumAddress common.Address `yaml:"bls_public_key_compendium_address"`
-		AvsServiceManagerAddress      common.Address `yaml:"avs_service_manager_address"`
-		EnableMetrics                 bool           `yaml:"enable_metrics"`
-		MetricsIpPortAddress          string         `yaml:"metrics_ip_port_address"`
-		TelemetryIpPortAddress        string         `yaml:"telemetry_ip_port_address"`
-		GarbageCollectorPeriod        time.Duration  `yaml:"garbage_collector_period"`
-		GarbageCollectorTasksAge      uint64         `yaml:"garbage_collector_tasks_age"`
-		GarbageCollectorTasksInterval uint64         `yaml:"garbage_collector_tasks_interval"`
+		ServerIpPortAddress             string         `yaml:"server_ip_port_address"`
+		ServerTlsCertPath               string         `yaml:"server_tls_cert_path"`
+		ServerTlsKeyPath                string         `yaml:"server_tls_key_path"`
+		ServerTlsClientCaPath           string         `yaml:"server_tls_client_ca_path"`
+		RequireSignedRequests           bool           `yaml:"require_signed_requests"`
+		RpcRateLimitPerIp               float64        `yaml:"rpc_rate_limit_per_ip"`
+		RpcRateLimitPerOperator         float64        `yaml:"rpc_rate_limit_per_operator"`
+		RpcRateLimitBurst               int            `yaml:"rpc_rate_limit_burst"`
+		RpcMaxRequestSize               int64          `yaml:"rpc_max_request_size"`
+		RpcWorkers                      int            `yaml:"rpc_workers"`
+		RpcQueueSize                    int            `yaml:"rpc_queue_size"`
+		JsonRpcServerIpPortAddress      string         `yaml:"json_rpc_server_ip_port_address"`
+		AdminApiIpPortAddress           string         `yaml:"admin_api_ip_port_address"`
+		AdminApiToken                   string         `yaml:"admin_api_token"`
+		ShadowMode                      bool           `yaml:"shadow_mode"`
+		BlsPublicKeyCompendiumAddress   common.Address `yaml:"bls_public_key_compendium_address"`
+		AvsServiceManagerAddress        common.Address `yaml:"avs_service_manager_address"`
+		EnableMetrics                   bool           `yaml:"enable_metrics"`
+		MetricsIpPortAddress            string         `yaml:"metrics_ip_port_address"`
+		TelemetryIpPortAddress          string         `yaml:"telemetry_ip_port_address"`
+		OtlpTracesEndpoint              string         `yaml:"otlp_traces_endpoint"`
+		TelemetryQueueSize              int            `yaml:"telemetry_queue_size"`
+		TelemetryBatchSize              int            `yaml:"telemetry_batch_size"`
+		TelemetryFlushInterval          time.Duration  `yaml:"telemetry_flush_interval"`
+		TelemetryRequestTimeout         time.Duration  `yaml:"telemetry_request_timeout"`
+		TelemetryMaxRetries             int            `yaml:"telemetry_max_retries"`
+		GarbageCollectorPeriod          time.Duration  `yaml:"garbage_collector_period"`
+		GarbageCollectorTaskRetention   time.Duration  `yaml:"garbage_collector_task_retention"`
+		GarbageCollectorMaxTasks        int            `yaml:"garbage_collector_max_tasks"`
+		TaskStorePath                   string         `yaml:"task_store_path"`
+		DeadLetterStorePath             string         `yaml:"dead_letter_store_path"`
+		AuditLogPath                    string         `yaml:"audit_log_path"`
+		LeaderLockPath                  string         `yaml:"leader_lock_path"`
+		LeaderLockEtcdUrl               string         `yaml:"leader_lock_etcd_url"`
+		LeaderLockKey                   string         `yaml:"leader_lock_key"`
+		LeaderLockTtl                   time.Duration  `yaml:"leader_lock_ttl"`
+		BackfillCheckpointPath          string         `yaml:"backfill_checkpoint_path"`
+		BackfillInterval                time.Duration  `yaml:"backfill_interval"`
+		BackfillWindowBlocks            uint64         `yaml:"backfill_window_blocks"`
+		QuorumNumbers                   []uint8        `yaml:"quorum_numbers"`
+		QuorumThresholdPercentages      []uint8        `yaml:"quorum_threshold_percentages"`
+		PostQuorumCollectionWindow      time.Duration  `yaml:"post_quorum_collection_window"`
+		PostQuorumTargetStakePercentage uint8          `yaml:"post_quorum_target_stake_percentage"`
+		PendingResponseTtl              time.Duration  `yaml:"pending_response_ttl"`
+		MaxPendingResponses             int            `yaml:"max_pending_responses"`
+		TxMaxFeePerGas                  uint64         `yaml:"tx_max_fee_per_gas"`
+		TxMaxPriorityFeePerGas          uint64         `yaml:"tx_max_priority_fee_per_gas"`
+		TxGasBumpPercentage             uint64         `yaml:"tx_gas_bump_percentage"`
+		TxStuckTimeout                  time.Duration  `yaml:"tx_stuck_timeout"`
 	} `yaml:"aggregator"`
 }
 
@@ -68,20 +183,165 @@ func NewAggregatorConfig(configFilePath string) *AggregatorConfig {
 		log.Fatal("Error reading aggregator config: ", err)
 	}
 
+	if len(aggregatorConfigFromYaml.Aggregator.QuorumNumbers) == 0 {
+		aggregatorConfigFromYaml.Aggregator.QuorumNumbers = DefaultQuorumNumbers
+		if len(aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentages) == 0 {
+			aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentages = DefaultQuorumThresholdPercentages
+		}
+	}
+	err = ValidateQuorumNumbers(aggregatorConfigFromYaml.Aggregator.QuorumNumbers)
+	if err == nil {
+		err = ValidateQuorumThresholdPercentages(aggregatorConfigFromYaml.Aggregator.QuorumNumbers, aggregatorConfigFromYaml.Aggregator.QuorumThresholdPercentages)
+	}
+	if err != nil {
+		log.Fatal("Invalid aggregator quorums config: ", err)
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.PostQuorumTargetStakePercentage > 100 {
+		log.Fatal("post_quorum_target_stake_percentage must be at most 100")
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.AdminApiIpPortAddress != "" && aggregatorConfigFromYaml.Aggregator.AdminApiToken == "" {
+		log.Fatal("admin_api_token is required when admin_api_ip_port_address is set")
+	}
+
+	if (aggregatorConfigFromYaml.Aggregator.ServerTlsCertPath == "") != (aggregatorConfigFromYaml.Aggregator.ServerTlsKeyPath == "") {
+		log.Fatal("server_tls_cert_path and server_tls_key_path must be set together")
+	}
+	if aggregatorConfigFromYaml.Aggregator.ServerTlsClientCaPath != "" && aggregatorConfigFromYaml.Aggregator.ServerTlsCertPath == "" {
+		log.Fatal("server_tls_client_ca_path requires server_tls_cert_path and server_tls_key_path")
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.RpcRateLimitPerIp < 0 || aggregatorConfigFromYaml.Aggregator.RpcRateLimitPerOperator < 0 ||
+		aggregatorConfigFromYaml.Aggregator.RpcRateLimitBurst < 0 || aggregatorConfigFromYaml.Aggregator.RpcMaxRequestSize < 0 ||
+		aggregatorConfigFromYaml.Aggregator.RpcWorkers < 0 || aggregatorConfigFromYaml.Aggregator.RpcQueueSize < 0 {
+		log.Fatal("rpc limits must not be negative")
+	}
+	if aggregatorConfigFromYaml.Aggregator.GarbageCollectorTaskRetention < 0 || aggregatorConfigFromYaml.Aggregator.GarbageCollectorMaxTasks < 0 {
+		log.Fatal("garbage collector limits must not be negative")
+	}
+	if aggregatorConfigFromYaml.Aggregator.GarbageCollectorTaskRetention == 0 {
+		aggregatorConfigFromYaml.Aggregator.GarbageCollectorTaskRetention = DefaultGarbageCollectorTaskRetention
+	}
+	if aggregatorConfigFromYaml.Aggregator.GarbageCollectorMaxTasks == 0 {
+		aggregatorConfigFromYaml.Aggregator.GarbageCollectorMaxTasks = DefaultGarbageCollectorMaxTasks
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.LeaderLockPath != "" && aggregatorConfigFromYaml.Aggregator.LeaderLockEtcdUrl != "" {
+		log.Fatal("leader_lock_path and leader_lock_etcd_url must not be set together")
+	}
+	if aggregatorConfigFromYaml.Aggregator.LeaderLockTtl < 0 {
+		log.Fatal("leader_lock_ttl must not be negative")
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.PendingResponseTtl < 0 || aggregatorConfigFromYaml.Aggregator.MaxPendingResponses < 0 {
+		log.Fatal("pending_response_ttl and max_pending_responses must not be negative")
+	}
+	if aggregatorConfigFromYaml.Aggregator.PendingResponseTtl == 0 {
+		aggregatorConfigFromYaml.Aggregator.PendingResponseTtl = DefaultPendingResponseTtl
+	}
+	if aggregatorConfigFromYaml.Aggregator.MaxPendingResponses == 0 {
+		aggregatorConfigFromYaml.Aggregator.MaxPendingResponses = DefaultMaxPendingResponses
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.BackfillInterval < 0 {
+		log.Fatal("backfill_interval must not be negative")
+	}
+	if aggregatorConfigFromYaml.Aggregator.BackfillInterval == 0 {
+		aggregatorConfigFromYaml.Aggregator.BackfillInterval = DefaultBackfillInterval
+	}
+	if aggregatorConfigFromYaml.Aggregator.BackfillWindowBlocks == 0 {
+		aggregatorConfigFromYaml.Aggregator.BackfillWindowBlocks = DefaultBackfillWindowBlocks
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.TelemetryQueueSize < 0 || aggregatorConfigFromYaml.Aggregator.TelemetryBatchSize < 0 ||
+		aggregatorConfigFromYaml.Aggregator.TelemetryFlushInterval < 0 || aggregatorConfigFromYaml.Aggregator.TelemetryRequestTimeout < 0 ||
+		aggregatorConfigFromYaml.Aggregator.TelemetryMaxRetries < 0 {
+		log.Fatal("telemetry queue settings must not be negative")
+	}
+	if aggregatorConfigFromYaml.Aggregator.TelemetryQueueSize == 0 {
+		aggregatorConfigFromYaml.Aggregator.TelemetryQueueSize = DefaultTelemetryQueueSize
+	}
+	if aggregatorConfigFromYaml.Aggregator.TelemetryBatchSize == 0 {
+		aggregatorConfigFromYaml.Aggregator.TelemetryBatchSize = DefaultTelemetryBatchSize
+	}
+	if aggregatorConfigFromYaml.Aggregator.TelemetryFlushInterval == 0 {
+		aggregatorConfigFromYaml.Aggregator.TelemetryFlushInterval = DefaultTelemetryFlushInterval
+	}
+	if aggregatorConfigFromYaml.Aggregator.TelemetryRequestTimeout == 0 {
+		aggregatorConfigFromYaml.Aggregator.TelemetryRequestTimeout = DefaultTelemetryRequestTimeout
+	}
+	if aggregatorConfigFromYaml.Aggregator.TelemetryMaxRetries == 0 {
+		aggregatorConfigFromYaml.Aggregator.TelemetryMaxRetries = DefaultTelemetryMaxRetries
+	}
+
+	if aggregatorConfigFromYaml.Aggregator.RpcRateLimitBurst == 0 {
+		aggregatorConfigFromYaml.Aggregator.RpcRateLimitBurst = DefaultRpcRateLimitBurst
+	}
+	if aggregatorConfigFromYaml.Aggregator.RpcMaxRequestSize == 0 {
+		aggregatorConfigFromYaml.Aggregator.RpcMaxRequestSize = DefaultRpcMaxRequestSize
+	}
+	if aggregatorConfigFromYaml.Aggregator.RpcWorkers == 0 {
+		aggregatorConfigFromYaml.Aggregator.RpcWorkers = DefaultRpcWorkers
+	}
+	if aggregatorConfigFromYaml.Aggregator.RpcQueueSize == 0 {
+		aggregatorConfigFromYaml.Aggregator.RpcQueueSize = DefaultRpcQueueSize
+	}
+
 	return &AggregatorConfig{
 		BaseConfig:  baseConfig,
 		EcdsaConfig: ecdsaConfig,
 		BlsConfig:   blsConfig,
 		Aggregator: struct {
-			ServerIpPortAddress           string
-			BlsPublicKeyCompendiumAddress common.Address
-			AvsServiceManagerAddress      common.Address
-			EnableMetrics                 bool
-			MetricsIpPortAddress          string
-			TelemetryIpPortAddress        string
-			GarbageCollectorPeriod        time.Duration
-			GarbageCollectorTasksAge      uint64
-			GarbageCollectorTasksInterval uint64
+			ServerIpPortAddress             string
+			ServerTlsCertPath               string
+			ServerTlsKeyPath                string
+			ServerTlsClientCaPath           string
+			RequireSignedRequests           bool
+			RpcRateLimitPerIp               float64
+			RpcRateLimitPerOperator         float64
+			RpcRateLimitBurst               int
+			RpcMaxRequestSize               int64
+			RpcWorkers                      int
+			RpcQueueSize                    int
+			JsonRpcServerIpPortAddress      string
+			AdminApiIpPortAddress           string
+			AdminApiToken                   string
+			ShadowMode                      bool
+			BlsPublicKeyCompendiumAddress   common.Address
+			AvsServiceManagerAddress        common.Address
+			EnableMetrics                   bool
+			MetricsIpPortAddress            string
+			TelemetryIpPortAddress          string
+			OtlpTracesEndpoint              string
+			TelemetryQueueSize              int
+			TelemetryBatchSize              int
+			TelemetryFlushInterval          time.Duration
+			TelemetryRequestTimeout         time.Duration
+			TelemetryMaxRetries             int
+			GarbageCollectorPeriod          time.Duration
+			GarbageCollectorTaskRetention   time.Duration
+			GarbageCollectorMaxTasks        int
+			TaskStorePath                   string
+			DeadLetterStorePath             string
+			AuditLogPath                    string
+			LeaderLockPath                  string
+			LeaderLockEtcdUrl               string
+			LeaderLockKey                   string
+			LeaderLockTtl                   time.Duration
+			BackfillCheckpointPath          string
+			BackfillInterval                time.Duration
+			BackfillWindowBlocks            uint64
+			QuorumNumbers                   []uint8
+			QuorumThresholdPercentages      []uint8
+			PostQuorumCollectionWindow      time.Duration
+			PostQuorumTargetStakePercentage uint8
+			PendingResponseTtl              time.Duration
+			MaxPendingResponses             int
+			TxMaxFeePerGas                  uint64
+			TxMaxPriorityFeePerGas          uint64
+			TxGasBumpPercentage             uint64
+			TxStuckTimeout                  time.Duration
 		}(aggregatorConfigFromYaml.Aggregator),
 	}
 }
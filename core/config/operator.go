@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 
 	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,35 +17,75 @@ type OperatorConfig struct {
 	AlignedLayerDeploymentConfig *AlignedLayerDeploymentConfig
 
 	Operator struct {
-		AggregatorServerIpPortAddress string
-		OperatorTrackerIpPortAddress  string
-		Address                       common.Address
-		EarningsReceiverAddress       common.Address
-		DelegationApproverAddress     common.Address
-		StakerOptOutWindowBlocks      int
-		MetadataUrl                   string
-		RegisterOperatorOnStartup     bool
-		EnableMetrics                 bool
-		MetricsIpPortAddress          string
-		MaxBatchSize                  int64
-		LastProcessedBatchFilePath    string
+		AggregatorServerIpPortAddress   string
+		AggregatorServerIpPortAddresses []string
+		AggregatorFanOut                bool
+		AggregatorRpcTimeout            time.Duration
+		AggregatorTlsEnabled            bool
+		AggregatorTlsCaPath             string
+		AggregatorTlsCertPath           string
+		AggregatorTlsKeyPath            string
+		SignAggregatorRequests          bool
+		OperatorTrackerIpPortAddress    string
+		HeartbeatInterval               time.Duration
+		Address                         common.Address
+		EarningsReceiverAddress         common.Address
+		DelegationApproverAddress       common.Address
+		StakerOptOutWindowBlocks        int
+		MetadataUrl                     string
+		RegisterOperatorOnStartup       bool
+		QuorumNumbers                   []uint8
+		EnableMetrics                   bool
+		MetricsIpPortAddress            string
+		MaxBatchSize                    int64
+		LastProcessedBatchFilePath      string
+		ResponseOutboxFilePath          string
+		StatusIpPortAddress             string
+		VerificationPolicy              VerificationPolicyConfig
 	}
 }
 
+// VerificationPolicyConfig is the operator-local policy on how proofs are verified.
+// It is applied on top of the verifiers disabled on-chain.
+type VerificationPolicyConfig struct {
+	// Max number of concurrent verifications for heavy (SP1, Risc0) and light (gnark) proving systems.
+	// 0 means no limit
+	HeavyVerificationsConcurrency int `yaml:"heavy_verifications_concurrency"`
+	LightVerificationsConcurrency int `yaml:"light_verifications_concurrency"`
+	// Max duration of a single proof verification by proving system name. Proving systems without a
+	// timeout are not limited
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+	// Proving systems this operator refuses to verify. Batches containing them are not signed
+	DisabledVerifiers []string `yaml:"disabled_verifiers"`
+}
+
 type OperatorConfigFromYaml struct {
 	Operator struct {
-		AggregatorServerIpPortAddress string         `yaml:"aggregator_rpc_server_ip_port_address"`
-		OperatorTrackerIpPortAddress  string         `yaml:"operator_tracker_ip_port_address"`
-		Address                       common.Address `yaml:"address"`
-		EarningsReceiverAddress       common.Address `yaml:"earnings_receiver_address"`
-		DelegationApproverAddress     common.Address `yaml:"delegation_approver_address"`
-		StakerOptOutWindowBlocks      int            `yaml:"staker_opt_out_window_blocks"`
-		MetadataUrl                   string         `yaml:"metadata_url"`
-		RegisterOperatorOnStartup     bool           `yaml:"register_operator_on_startup"`
-		EnableMetrics                 bool           `yaml:"enable_metrics"`
-		MetricsIpPortAddress          string         `yaml:"metrics_ip_port_address"`
-		MaxBatchSize                  int64          `yaml:"max_batch_size"`
-		LastProcessedBatchFilePath    string         `yaml:"last_processed_batch_filepath"`
+		AggregatorServerIpPortAddress   string                   `yaml:"aggregator_rpc_server_ip_port_address"`
+		AggregatorServerIpPortAddresses []string                 `yaml:"aggregator_rpc_server_ip_port_addresses"`
+		AggregatorFanOut                bool                     `yaml:"aggregator_fan_out"`
+		AggregatorRpcTimeout            time.Duration            `yaml:"aggregator_rpc_timeout"`
+		AggregatorTlsEnabled            bool                     `yaml:"aggregator_tls_enabled"`
+		AggregatorTlsCaPath             string                   `yaml:"aggregator_tls_ca_path"`
+		AggregatorTlsCertPath           string                   `yaml:"aggregator_tls_cert_path"`
+		AggregatorTlsKeyPath            string                   `yaml:"aggregator_tls_key_path"`
+		SignAggregatorRequests          bool                     `yaml:"sign_aggregator_requests"`
+		OperatorTrackerIpPortAddress    string                   `yaml:"operator_tracker_ip_port_address"`
+		HeartbeatInterval               time.Duration            `yaml:"heartbeat_interval"`
+		Address                         common.Address           `yaml:"address"`
+		EarningsReceiverAddress         common.Address           `yaml:"earnings_receiver_address"`
+		DelegationApproverAddress       common.Address           `yaml:"delegation_approver_address"`
+		StakerOptOutWindowBlocks        int                      `yaml:"staker_opt_out_window_blocks"`
+		MetadataUrl                     string                   `yaml:"metadata_url"`
+		RegisterOperatorOnStartup       bool                     `yaml:"register_operator_on_startup"`
+		QuorumNumbers                   []uint8                  `yaml:"quorum_numbers"`
+		EnableMetrics                   bool                     `yaml:"enable_metrics"`
+		MetricsIpPortAddress            string                   `yaml:"metrics_ip_port_address"`
+		MaxBatchSize                    int64                    `yaml:"max_batch_size"`
+		LastProcessedBatchFilePath      string                   `yaml:"last_processed_batch_filepath"`
+		ResponseOutboxFilePath          string                   `yaml:"response_outbox_filepath"`
+		StatusIpPortAddress             string                   `yaml:"status_ip_port_address"`
+		VerificationPolicy              VerificationPolicyConfig `yaml:"verification_policy"`
 	} `yaml:"operator"`
 	EcdsaConfigFromYaml EcdsaConfigFromYaml `yaml:"ecdsa"`
 	BlsConfigFromYaml   BlsConfigFromYaml   `yaml:"bls"`
@@ -77,24 +118,45 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 		log.Fatal("Error reading operator config: ", err)
 	}
 
+	if len(operatorConfigFromYaml.Operator.QuorumNumbers) == 0 {
+		operatorConfigFromYaml.Operator.QuorumNumbers = DefaultQuorumNumbers
+	}
+	err = ValidateQuorumNumbers(operatorConfigFromYaml.Operator.QuorumNumbers)
+	if err != nil {
+		log.Fatal("Invalid operator quorums config: ", err)
+	}
+
 	return &OperatorConfig{
 		BaseConfig:                   baseConfig,
 		EcdsaConfig:                  ecdsaConfig,
 		BlsConfig:                    blsConfig,
 		AlignedLayerDeploymentConfig: baseConfig.AlignedLayerDeploymentConfig,
 		Operator: struct {
-			AggregatorServerIpPortAddress string
-			OperatorTrackerIpPortAddress  string
-			Address                       common.Address
-			EarningsReceiverAddress       common.Address
-			DelegationApproverAddress     common.Address
-			StakerOptOutWindowBlocks      int
-			MetadataUrl                   string
-			RegisterOperatorOnStartup     bool
-			EnableMetrics                 bool
-			MetricsIpPortAddress          string
-			MaxBatchSize                  int64
-			LastProcessedBatchFilePath    string
+			AggregatorServerIpPortAddress   string
+			AggregatorServerIpPortAddresses []string
+			AggregatorFanOut                bool
+			AggregatorRpcTimeout            time.Duration
+			AggregatorTlsEnabled            bool
+			AggregatorTlsCaPath             string
+			AggregatorTlsCertPath           string
+			AggregatorTlsKeyPath            string
+			SignAggregatorRequests          bool
+			OperatorTrackerIpPortAddress    string
+			HeartbeatInterval               time.Duration
+			Address                         common.Address
+			EarningsReceiverAddress         common.Address
+			DelegationApproverAddress       common.Address
+			StakerOptOutWindowBlocks        int
+			MetadataUrl                     string
+			RegisterOperatorOnStartup       bool
+			QuorumNumbers                   []uint8
+			EnableMetrics                   bool
+			MetricsIpPortAddress            string
+			MaxBatchSize                    int64
+			LastProcessedBatchFilePath      string
+			ResponseOutboxFilePath          string
+			StatusIpPortAddress             string
+			VerificationPolicy              VerificationPolicyConfig
 		}(operatorConfigFromYaml.Operator),
 	}
 }
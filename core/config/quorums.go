@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Used when `quorum_numbers` and `quorum_threshold_percentages` are not set
+var (
+	DefaultQuorumNumbers              = []uint8{0}
+	DefaultQuorumThresholdPercentages = []uint8{67}
+)
+
+// ValidateQuorumNumbers checks there is at least one quorum number and they have no duplicates.
+// Whether the quorums exist is checked against the registry coordinator at startup.
+func ValidateQuorumNumbers(quorumNumbers []uint8) error {
+	if len(quorumNumbers) == 0 {
+		return fmt.Errorf("at least one quorum number is required")
+	}
+
+	seen := make(map[uint8]bool, len(quorumNumbers))
+	for _, quorumNumber := range quorumNumbers {
+		if seen[quorumNumber] {
+			return fmt.Errorf("duplicated quorum number %d", quorumNumber)
+		}
+		seen[quorumNumber] = true
+	}
+
+	return nil
+}
+
+// ValidateQuorumThresholdPercentages checks there is one threshold per quorum, in the (0, 100] range
+func ValidateQuorumThresholdPercentages(quorumNumbers []uint8, quorumThresholdPercentages []uint8) error {
+	if len(quorumThresholdPercentages) != len(quorumNumbers) {
+		return fmt.Errorf("got %d quorum threshold percentages for %d quorum numbers", len(quorumThresholdPercentages), len(quorumNumbers))
+	}
+	for i, threshold := range quorumThresholdPercentages {
+		if threshold == 0 || threshold > 100 {
+			return fmt.Errorf("quorum %d threshold percentage must be between 1 and 100, got %d", quorumNumbers[i], threshold)
+		}
+	}
+	return nil
+}
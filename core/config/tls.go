@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// NewServerTLSConfig loads the server certificate. If clientCaPath is set, clients must present
+// a certificate signed by that CA (mutual TLS).
+func NewServerTLSConfig(certPath string, keyPath string, clientCaPath string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCaPath != "" {
+		clientCas, err := loadCertPool(clientCaPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = clientCas
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// NewClientTLSConfig verifies the server against caPath, or the system CAs if it is empty.
+// certPath and keyPath are the client certificate for mutual TLS, and are optional.
+func NewClientTLSConfig(caPath string, certPath string, keyPath string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if caPath != "" {
+		rootCas, err := loadCertPool(caPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = rootCas
+	}
+	if certPath != "" || keyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(caPath string) (*x509.CertPool, error) {
+	caPem, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPem) {
+		return nil, fmt.Errorf("no certificate found in %s", caPath)
+	}
+	return pool, nil
+}
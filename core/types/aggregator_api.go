@@ -0,0 +1,195 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Aggregator JSON-RPC 2.0 API, version 1.
+// Requests are POSTed to /v1 on the aggregator JSON-RPC address. All byte fields are 0x-prefixed hex strings.
+
+const (
+	JsonRpcVersion          = "2.0"
+	AggregatorApiV1         = "v1"
+	AggregatorApiV1Path     = "/v1"
+	SubmitSignedTaskMethod  = "submitSignedTaskResponse"
+	GetTaskStatusMethod     = "getTaskStatus"
+	AggregatorHealthMethod  = "health"
+	BlsSignatureLength      = 64
+	MaxJsonRpcBatchRequests = 32
+)
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	JsonRpcParseError     = -32700
+	JsonRpcInvalidRequest = -32600
+	JsonRpcMethodNotFound = -32601
+	JsonRpcInvalidParams  = -32602
+	JsonRpcInternalError  = -32603
+	// The aggregator could not process the signed task response. More specific
+	// rejections use the codes in signed_task_response_reply.go
+	JsonRpcResponseRejected = -32000
+)
+
+type JsonRpcRequest struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Id      json.RawMessage `json:"id,omitempty"`
+}
+
+type JsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *JsonRpcError   `json:"error,omitempty"`
+	Id      json.RawMessage `json:"id"`
+}
+
+type JsonRpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *JsonRpcError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+// SignedTaskResponseV1 are the params of submitSignedTaskResponse
+type SignedTaskResponseV1 struct {
+	BatchMerkleRoot     common.Hash    `json:"batch_merkle_root"`
+	SenderAddress       common.Address `json:"sender_address"`
+	BatchIdentifierHash common.Hash    `json:"batch_identifier_hash"`
+	// Uncompressed G1 point, X and Y as 32 bytes big endian each
+	BlsSignature hexutil.Bytes `json:"bls_signature"`
+	OperatorId   common.Hash   `json:"operator_id"`
+	// Optional, required if the aggregator requires signed requests. See AuthenticatedSignedTaskResponse
+	Timestamp      int64         `json:"timestamp,omitempty"`
+	EcdsaSignature hexutil.Bytes `json:"ecdsa_signature,omitempty"`
+	// Optional, links the operator spans into the batch trace. See TaskTraceContext
+	TraceContext *TaskTraceContextV1 `json:"trace_context,omitempty"`
+}
+
+// TaskTraceContextV1 is the v1 wire format of TaskTraceContext. Times are unix nanoseconds
+type TaskTraceContextV1 struct {
+	TraceId   hexutil.Bytes `json:"trace_id"`
+	SpanId    hexutil.Bytes `json:"span_id"`
+	StartTime int64         `json:"start_time"`
+	EndTime   int64         `json:"end_time"`
+	Spans     []TaskSpanV1  `json:"spans,omitempty"`
+}
+
+type TaskSpanV1 struct {
+	Name      string `json:"name"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SubmitSignedTaskResultV1 is the result of submitSignedTaskResponse
+type SubmitSignedTaskResultV1 struct {
+	Accepted bool `json:"accepted"`
+	// The aggregator has not seen the task yet, the response is processed once it does
+	Pending bool `json:"pending,omitempty"`
+}
+
+// TaskStatusParamsV1 are the params of getTaskStatus
+type TaskStatusParamsV1 struct {
+	BatchIdentifierHash common.Hash `json:"batch_identifier_hash"`
+}
+
+// TaskStatusV1 is the result of getTaskStatus
+type TaskStatusV1 struct {
+	BatchIdentifierHash common.Hash `json:"batch_identifier_hash"`
+	// Known is false if the aggregator is not tracking the task. The rest of fields are only set if it is
+	Known            bool   `json:"known"`
+	TaskIndex        uint32 `json:"task_index"`
+	TaskCreatedBlock uint64 `json:"task_created_block"`
+	Responded        bool   `json:"responded"`
+}
+
+// HealthV1 is the result of health
+type HealthV1 struct {
+	Status       string `json:"status"`
+	ApiVersion   string `json:"api_version"`
+	Leader       bool   `json:"leader"`
+	PendingTasks int    `json:"pending_tasks"`
+}
+
+// ToSignedTaskResponse validates the params and converts them to the internal type
+func (r *SignedTaskResponseV1) ToSignedTaskResponse() (*SignedTaskResponse, error) {
+	if len(r.BlsSignature) != BlsSignatureLength {
+		return nil, fmt.Errorf("bls_signature must be %d bytes, got %d", BlsSignatureLength, len(r.BlsSignature))
+	}
+	point := new(bls.G1Point).Deserialize(r.BlsSignature)
+	if !point.IsOnCurve() {
+		return nil, fmt.Errorf("bls_signature is not a valid G1 point")
+	}
+
+	var traceContext *TaskTraceContext
+	if r.TraceContext != nil {
+		if len(r.TraceContext.TraceId) != 16 || len(r.TraceContext.SpanId) != 8 {
+			return nil, fmt.Errorf("trace_context trace_id must be 16 bytes and span_id 8 bytes")
+		}
+		traceContext = &TaskTraceContext{
+			TraceId:   *(*[16]byte)(r.TraceContext.TraceId),
+			SpanId:    *(*[8]byte)(r.TraceContext.SpanId),
+			StartTime: r.TraceContext.StartTime,
+			EndTime:   r.TraceContext.EndTime,
+		}
+		for _, span := range r.TraceContext.Spans {
+			traceContext.Spans = append(traceContext.Spans, TaskSpan(span))
+		}
+	}
+
+	return &SignedTaskResponse{
+		BatchMerkleRoot:     r.BatchMerkleRoot,
+		SenderAddress:       r.SenderAddress,
+		BatchIdentifierHash: r.BatchIdentifierHash,
+		BlsSignature:        bls.Signature{G1Point: point},
+		OperatorId:          eigentypes.OperatorId(r.OperatorId),
+		TraceContext:        traceContext,
+	}, nil
+}
+
+// ToAuthenticatedSignedTaskResponse returns nil if the params carry no ECDSA signature
+func (r *SignedTaskResponseV1) ToAuthenticatedSignedTaskResponse(signedTaskResponse *SignedTaskResponse) *AuthenticatedSignedTaskResponse {
+	if len(r.EcdsaSignature) == 0 {
+		return nil
+	}
+	return &AuthenticatedSignedTaskResponse{
+		SignedTaskResponse: *signedTaskResponse,
+		Timestamp:          r.Timestamp,
+		EcdsaSignature:     r.EcdsaSignature,
+	}
+}
+
+// NewSignedTaskResponseV1 converts a signed task response to the v1 wire format
+func NewSignedTaskResponseV1(signedTaskResponse *SignedTaskResponse) SignedTaskResponseV1 {
+	var traceContext *TaskTraceContextV1
+	if signedTaskResponse.TraceContext != nil {
+		traceContext = &TaskTraceContextV1{
+			TraceId:   signedTaskResponse.TraceContext.TraceId[:],
+			SpanId:    signedTaskResponse.TraceContext.SpanId[:],
+			StartTime: signedTaskResponse.TraceContext.StartTime,
+			EndTime:   signedTaskResponse.TraceContext.EndTime,
+		}
+		for _, span := range signedTaskResponse.TraceContext.Spans {
+			traceContext.Spans = append(traceContext.Spans, TaskSpanV1(span))
+		}
+	}
+
+	return SignedTaskResponseV1{
+		BatchMerkleRoot:     signedTaskResponse.BatchMerkleRoot,
+		SenderAddress:       signedTaskResponse.SenderAddress,
+		BatchIdentifierHash: signedTaskResponse.BatchIdentifierHash,
+		BlsSignature:        signedTaskResponse.BlsSignature.Serialize(),
+		OperatorId:          common.Hash(signedTaskResponse.OperatorId),
+		TraceContext:        traceContext,
+	}
+}
@@ -11,4 +11,6 @@ type SignedTaskResponse struct {
 	BatchIdentifierHash [32]byte
 	BlsSignature    bls.Signature
 	OperatorId      eigentypes.OperatorId
+	// Optional, not covered by any signature. Only used for telemetry
+	TraceContext *TaskTraceContext
 }
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Prefix of the signed digest, so the signature can't be replayed as another message
+const signedTaskResponseAuthDomain = "aligned-layer:signed-task-response:v1"
+
+// AuthenticatedSignedTaskResponse is a SignedTaskResponse signed with the operator ECDSA key.
+// It is the argument of ProcessOperatorSignedTaskResponseV3.
+type AuthenticatedSignedTaskResponse struct {
+	SignedTaskResponse SignedTaskResponse
+	// Unix seconds. The aggregator rejects requests too far from its own clock
+	Timestamp int64
+	// 65 bytes [R || S || V] signature of SignedTaskResponseAuthDigest
+	EcdsaSignature []byte
+}
+
+// SignedTaskResponseAuthDigest is the hash the operator signs with its ECDSA key
+func SignedTaskResponseAuthDigest(signedTaskResponse *SignedTaskResponse, timestamp int64) [32]byte {
+	var blsSignature []byte
+	if signedTaskResponse.BlsSignature.G1Point != nil {
+		blsSignature = signedTaskResponse.BlsSignature.Serialize()
+	} else {
+		blsSignature = make([]byte, BlsSignatureLength)
+	}
+	timestampBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(timestampBytes, uint64(timestamp))
+
+	return crypto.Keccak256Hash(
+		[]byte(signedTaskResponseAuthDomain),
+		signedTaskResponse.BatchMerkleRoot[:],
+		signedTaskResponse.SenderAddress[:],
+		signedTaskResponse.BatchIdentifierHash[:],
+		blsSignature,
+		signedTaskResponse.OperatorId[:],
+		timestampBytes,
+	)
+}
+
+// SignSignedTaskResponse signs the response with the operator ECDSA key at the given time
+func SignSignedTaskResponse(signedTaskResponse *SignedTaskResponse, privateKey *ecdsa.PrivateKey, now time.Time) (*AuthenticatedSignedTaskResponse, error) {
+	timestamp := now.Unix()
+	digest := SignedTaskResponseAuthDigest(signedTaskResponse, timestamp)
+	signature, err := crypto.Sign(digest[:], privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthenticatedSignedTaskResponse{
+		SignedTaskResponse: *signedTaskResponse,
+		Timestamp:          timestamp,
+		EcdsaSignature:     signature,
+	}, nil
+}
+
+// Signer recovers the address that signed the request
+func (r *AuthenticatedSignedTaskResponse) Signer() (common.Address, error) {
+	if len(r.EcdsaSignature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("ecdsa signature must be %d bytes, got %d", crypto.SignatureLength, len(r.EcdsaSignature))
+	}
+	digest := SignedTaskResponseAuthDigest(&r.SignedTaskResponse, r.Timestamp)
+	publicKey, err := crypto.SigToPub(digest[:], r.EcdsaSignature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
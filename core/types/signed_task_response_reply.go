@@ -0,0 +1,103 @@
+package types
+
+// Replies of ProcessOperatorSignedTaskResponseV2.
+// 0 and 1 keep their original meaning, so operators that only check for 0 keep working.
+const (
+	SignedTaskResponseAccepted uint8 = iota
+	// Unexpected error in the aggregator. The operator may retry
+	SignedTaskResponseInternalError
+	// The aggregator is not tracking the task, it may not have seen it yet. The operator may retry
+	SignedTaskResponseUnknownTask
+	// The signature does not verify against the operator's registered pubkey
+	SignedTaskResponseInvalidSignature
+	// The aggregator already has a signature from this operator for the task
+	SignedTaskResponseDuplicate
+	// The BLS aggregation service did not process the signature in time. The operator may retry
+	SignedTaskResponseTimeout
+	// The operator is not registered in the task quorums at the task creation block
+	SignedTaskResponseUnknownOperator
+	// The request is not signed by the operator ECDSA key, and the aggregator requires it
+	SignedTaskResponseUnauthorized
+	// The operator or its IP went over the rate limit, or the aggregator has too many responses in flight.
+	// The operator may retry
+	SignedTaskResponseRateLimited
+	// The aggregator has not seen the task yet. The response is buffered in memory and processed once
+	// it does. The operator keeps it and sends it again later, as the buffer does not survive a restart
+	SignedTaskResponseAcceptedPending
+)
+
+// Aggregator JSON-RPC error codes for rejected signed task responses, one per reply
+const (
+	JsonRpcUnknownTask      = -32001
+	JsonRpcInvalidSignature = -32002
+	JsonRpcDuplicate        = -32003
+	JsonRpcTimeout          = -32004
+	JsonRpcUnknownOperator  = -32005
+	JsonRpcUnauthorized     = -32006
+	JsonRpcRateLimited      = -32007
+)
+
+// SignedTaskResponseReplyString returns a human readable description of a reply
+func SignedTaskResponseReplyString(reply uint8) string {
+	switch reply {
+	case SignedTaskResponseAccepted:
+		return "accepted"
+	case SignedTaskResponseInternalError:
+		return "internal error"
+	case SignedTaskResponseUnknownTask:
+		return "unknown task"
+	case SignedTaskResponseInvalidSignature:
+		return "invalid signature"
+	case SignedTaskResponseDuplicate:
+		return "duplicate"
+	case SignedTaskResponseTimeout:
+		return "timeout"
+	case SignedTaskResponseUnknownOperator:
+		return "unknown operator"
+	case SignedTaskResponseUnauthorized:
+		return "unauthorized"
+	case SignedTaskResponseRateLimited:
+		return "rate limited"
+	case SignedTaskResponseAcceptedPending:
+		return "accepted, pending"
+	default:
+		return "unknown reply"
+	}
+}
+
+// SignedTaskResponseReplyIsAccepted returns true if the aggregator took the response, now or once it sees the task
+func SignedTaskResponseReplyIsAccepted(reply uint8) bool {
+	return reply == SignedTaskResponseAccepted || reply == SignedTaskResponseAcceptedPending
+}
+
+// SignedTaskResponseReplyIsRetryable returns true if sending the same response again may succeed
+func SignedTaskResponseReplyIsRetryable(reply uint8) bool {
+	switch reply {
+	case SignedTaskResponseInvalidSignature, SignedTaskResponseDuplicate, SignedTaskResponseUnknownOperator, SignedTaskResponseUnauthorized:
+		return false
+	default:
+		return true
+	}
+}
+
+// SignedTaskResponseReplyJsonRpcCode maps a reply to its JSON-RPC error code
+func SignedTaskResponseReplyJsonRpcCode(reply uint8) int {
+	switch reply {
+	case SignedTaskResponseUnknownTask:
+		return JsonRpcUnknownTask
+	case SignedTaskResponseInvalidSignature:
+		return JsonRpcInvalidSignature
+	case SignedTaskResponseDuplicate:
+		return JsonRpcDuplicate
+	case SignedTaskResponseTimeout:
+		return JsonRpcTimeout
+	case SignedTaskResponseUnknownOperator:
+		return JsonRpcUnknownOperator
+	case SignedTaskResponseUnauthorized:
+		return JsonRpcUnauthorized
+	case SignedTaskResponseRateLimited:
+		return JsonRpcRateLimited
+	default:
+		return JsonRpcResponseRejected
+	}
+}
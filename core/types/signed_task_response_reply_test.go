@@ -0,0 +1,46 @@
+package types
+
+import "testing"
+
+// Operators running older versions rely on these values, so they must not change
+func TestSignedTaskResponseReplies(t *testing.T) {
+	tests := []struct {
+		reply       uint8
+		code        uint8
+		str         string
+		accepted    bool
+		retryable   bool
+		jsonRpcCode int
+	}{
+		{SignedTaskResponseAccepted, 0, "accepted", true, true, JsonRpcResponseRejected},
+		{SignedTaskResponseInternalError, 1, "internal error", false, true, JsonRpcResponseRejected},
+		{SignedTaskResponseUnknownTask, 2, "unknown task", false, true, JsonRpcUnknownTask},
+		{SignedTaskResponseInvalidSignature, 3, "invalid signature", false, false, JsonRpcInvalidSignature},
+		{SignedTaskResponseDuplicate, 4, "duplicate", false, false, JsonRpcDuplicate},
+		{SignedTaskResponseTimeout, 5, "timeout", false, true, JsonRpcTimeout},
+		{SignedTaskResponseUnknownOperator, 6, "unknown operator", false, false, JsonRpcUnknownOperator},
+		{SignedTaskResponseUnauthorized, 7, "unauthorized", false, false, JsonRpcUnauthorized},
+		{SignedTaskResponseRateLimited, 8, "rate limited", false, true, JsonRpcRateLimited},
+		{SignedTaskResponseAcceptedPending, 9, "accepted, pending", true, true, JsonRpcResponseRejected},
+	}
+
+	for _, test := range tests {
+		t.Run(test.str, func(t *testing.T) {
+			if test.reply != test.code {
+				t.Errorf("Expected reply code %d, got %d", test.code, test.reply)
+			}
+			if str := SignedTaskResponseReplyString(test.reply); str != test.str {
+				t.Errorf("Expected %q, got %q", test.str, str)
+			}
+			if accepted := SignedTaskResponseReplyIsAccepted(test.reply); accepted != test.accepted {
+				t.Errorf("Expected accepted to be %v, got %v", test.accepted, accepted)
+			}
+			if retryable := SignedTaskResponseReplyIsRetryable(test.reply); retryable != test.retryable {
+				t.Errorf("Expected retryable to be %v, got %v", test.retryable, retryable)
+			}
+			if jsonRpcCode := SignedTaskResponseReplyJsonRpcCode(test.reply); jsonRpcCode != test.jsonRpcCode {
+				t.Errorf("Expected JSON-RPC code %d, got %d", test.jsonRpcCode, jsonRpcCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package types
+
+// Max stage spans the aggregator links from a single operator response, the rest are dropped
+const MaxTaskTraceSpans = 16
+
+// Max length of a span name the aggregator accepts
+const MaxTaskSpanNameLength = 64
+
+// TaskTraceContext is the trace of an operator processing a batch, sent along with its SignedTaskResponse.
+// Its trace id is derived from the batch merkle root, so the aggregator links the operator spans into the
+// batch trace.
+type TaskTraceContext struct {
+	TraceId [16]byte
+	// Span of the whole operator processing, parent of Spans
+	SpanId [8]byte
+	// Unix nanoseconds
+	StartTime int64
+	EndTime   int64
+	Spans     []TaskSpan
+}
+
+// TaskSpan is a stage of the operator processing a batch, like downloading it or verifying its proofs
+type TaskSpan struct {
+	Name string
+	// Unix nanoseconds
+	StartTime int64
+	EndTime   int64
+	// Empty if the stage succeeded
+	Error string
+}
+
+// TaskTraceId returns the trace id of a batch, shared by the operator and aggregator spans
+func TaskTraceId(batchMerkleRoot [32]byte) [16]byte {
+	return *(*[16]byte)(batchMerkleRoot[:16])
+}
@@ -3,7 +3,9 @@ package metrics
 import (
 	"context"
 	"errors"
+	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
@@ -18,6 +20,39 @@ type Metrics struct {
 	numAggregatedResponses     prometheus.Counter
 	numAggregatorReceivedTasks prometheus.Counter
 	numOperatorTaskResponses   prometheus.Counter
+	aggregatorIsLeader         prometheus.Gauge
+
+	aggregatorTaskToQuorumLatency         prometheus.Histogram
+	aggregatorQuorumToConfirmationLatency prometheus.Histogram
+	aggregatorSigningStakePercentage      *prometheus.GaugeVec
+	aggregatorRespondToTaskGasUsed        prometheus.Histogram
+	aggregatorRespondToTaskCost           prometheus.Histogram
+	aggregatorSimulatedCostToFeeLimit     prometheus.Histogram
+	numAggregatorRespondToTaskFailures    *prometheus.CounterVec
+	aggregatorWalletBalance               prometheus.Gauge
+	numAggregatorPostQuorumSignatures     prometheus.Counter
+	aggregatorPostQuorumGasSaved          prometheus.Counter
+	aggregatorOperatorTasksSeen           *prometheus.GaugeVec
+	aggregatorOperatorTasksSigned         *prometheus.GaugeVec
+	aggregatorOperatorResponseDelay       *prometheus.GaugeVec
+	aggregatorOperatorInvalidSubmissions  *prometheus.GaugeVec
+	numAggregatorEvictedTasks             *prometheus.CounterVec
+	aggregatorTrackedTasks                prometheus.Gauge
+	numAggregatorShadowSimulations        *prometheus.CounterVec
+	numAggregatorBackfilledTasks          prometheus.Counter
+	numAggregatorTelemetryExportedEvents  *prometheus.CounterVec
+	numAggregatorTelemetryDroppedEvents   *prometheus.CounterVec
+	aggregatorTelemetryQueueLength        prometheus.Gauge
+
+	operatorVerificationsConcurrency  *prometheus.GaugeVec
+	operatorVerificationTimeout       *prometheus.GaugeVec
+	operatorLocallyDisabledVerifiers  *prometheus.GaugeVec
+	numOperatorLocallyRejectedBatches *prometheus.CounterVec
+	numOperatorVerificationTimeouts   *prometheus.CounterVec
+	operatorResponseOutboxDepth       prometheus.Gauge
+	operatorAggregatorEndpointUp      *prometheus.GaugeVec
+	operatorDisabledVerifiers         *prometheus.GaugeVec
+	numOperatorVerifierStatusChanges  *prometheus.CounterVec
 }
 
 const alignedNamespace = "aligned"
@@ -41,6 +76,166 @@ func NewMetrics(ipPortAddress string, reg prometheus.Registerer, logger logging.
 			Name:      "aggregator_received_tasks",
 			Help:      "Number of tasks received by the Service Manager",
 		}),
+		aggregatorIsLeader: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_is_leader",
+			Help:      "Whether the aggregator instance holds the leader lease (1) or is a standby (0)",
+		}),
+		aggregatorTaskToQuorumLatency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_task_to_quorum_seconds",
+			Help:      "Time from a new task to reaching quorum",
+			Buckets:   []float64{1, 2, 5, 10, 20, 30, 60, 100},
+		}),
+		aggregatorQuorumToConfirmationLatency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_quorum_to_confirmation_seconds",
+			Help:      "Time from reaching quorum to the aggregated response being included",
+			Buckets:   []float64{5, 12, 24, 36, 60, 120, 300, 600},
+		}),
+		aggregatorSigningStakePercentage: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_signing_stake_percentage",
+			Help:      "Percentage of the quorum stake that signed the last aggregated response",
+		}, []string{"quorum"}),
+		aggregatorRespondToTaskGasUsed: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_respond_to_task_gas_used",
+			Help:      "Gas used by each RespondToTaskV2 transaction",
+			Buckets:   prometheus.ExponentialBuckets(100_000, 1.5, 10),
+		}),
+		aggregatorRespondToTaskCost: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_respond_to_task_cost_eth",
+			Help:      "Cost in ETH of each RespondToTaskV2 transaction",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 12),
+		}),
+		aggregatorSimulatedCostToFeeLimit: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_simulated_cost_to_fee_limit_ratio",
+			Help:      "Simulated cost of each RespondToTaskV2 over the batch RespondToTaskFeeLimit, above 1 it is not sent",
+			Buckets:   []float64{0.1, 0.25, 0.5, 0.75, 0.9, 1, 1.5, 2},
+		}),
+		numAggregatorRespondToTaskFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_respond_to_task_failures",
+			Help:      "Number of aggregated responses that could not be sent, by reason",
+		}, []string{"reason"}),
+		aggregatorWalletBalance: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_wallet_balance_eth",
+			Help:      "Balance in ETH of the account that sends the aggregated responses",
+		}),
+		numAggregatorPostQuorumSignatures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_post_quorum_signatures",
+			Help:      "Number of signatures added to aggregated responses after reaching quorum",
+		}),
+		aggregatorPostQuorumGasSaved: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_post_quorum_gas_saved",
+			Help:      "Gas saved by the signatures collected after reaching quorum, as estimated by simulating both responses",
+		}),
+		aggregatorOperatorTasksSeen: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_operator_tasks_seen",
+			Help:      "Number of the last tasks the operator was expected to sign",
+		}, []string{"operator_id"}),
+		aggregatorOperatorTasksSigned: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_operator_tasks_signed",
+			Help:      "Number of the last tasks the operator signed",
+		}, []string{"operator_id"}),
+		aggregatorOperatorResponseDelay: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_operator_response_delay_seconds",
+			Help:      "Delay of the operator responses since the aggregator saw the task, over the last tasks it signed",
+		}, []string{"operator_id", "quantile"}),
+		aggregatorOperatorInvalidSubmissions: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_operator_invalid_submissions",
+			Help:      "Number of responses with an invalid signature sent by the operator over the last tasks",
+		}, []string{"operator_id"}),
+		numAggregatorEvictedTasks: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_evicted_tasks",
+			Help:      "Number of tasks removed by the garbage collector, by reason",
+		}, []string{"reason"}),
+		aggregatorTrackedTasks: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_tracked_tasks",
+			Help:      "Number of tasks kept in memory by the aggregator",
+		}),
+		numAggregatorShadowSimulations: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_shadow_simulations",
+			Help:      "Number of aggregated responses simulated in shadow mode instead of being sent, by result",
+		}, []string{"result"}),
+		numAggregatorBackfilledTasks: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_backfilled_tasks",
+			Help:      "Number of unresponded tasks missed by the task subscription and initialized by the backfill",
+		}),
+		numAggregatorTelemetryExportedEvents: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_telemetry_exported_events",
+			Help:      "Number of telemetry events exported, by exporter",
+		}, []string{"exporter"}),
+		numAggregatorTelemetryDroppedEvents: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_telemetry_dropped_events",
+			Help:      "Number of telemetry events dropped, by reason: queue_full or send_failed",
+		}, []string{"reason"}),
+		aggregatorTelemetryQueueLength: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "aggregator_telemetry_queue_length",
+			Help:      "Telemetry events waiting to be exported",
+		}),
+		operatorVerificationsConcurrency: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verifications_concurrency",
+			Help:      "Max number of concurrent verifications per resource class, 0 means no limit",
+		}, []string{"class"}),
+		operatorVerificationTimeout: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_timeout_seconds",
+			Help:      "Max duration of a proof verification per proving system, 0 means no limit",
+		}, []string{"proving_system"}),
+		operatorLocallyDisabledVerifiers: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_locally_disabled_verifiers",
+			Help:      "Whether the proving system is disabled in the operator local policy (1) or not (0)",
+		}, []string{"proving_system"}),
+		numOperatorLocallyRejectedBatches: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_locally_rejected_batches",
+			Help:      "Number of batches not signed because they contain a proving system disabled in the operator local policy",
+		}, []string{"proving_system"}),
+		numOperatorVerificationTimeouts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_timeouts",
+			Help:      "Number of proof verifications that exceeded the proving system timeout",
+		}, []string{"proving_system"}),
+		operatorResponseOutboxDepth: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_response_outbox_depth",
+			Help:      "Number of signed task responses waiting to be accepted by the aggregator",
+		}),
+		operatorAggregatorEndpointUp: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_aggregator_endpoint_up",
+			Help:      "Whether the operator is connected to the aggregator endpoint (1) or not (0)",
+		}, []string{"endpoint"}),
+		operatorDisabledVerifiers: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_disabled_verifiers",
+			Help:      "Whether the proving system is disabled on-chain (1) or not (0)",
+		}, []string{"proving_system"}),
+		numOperatorVerifierStatusChanges: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verifier_status_changes",
+			Help:      "Number of on-chain status changes of the proving system seen by the operator",
+		}, []string{"proving_system"}),
 	}
 }
 
@@ -83,6 +278,147 @@ func (m *Metrics) IncAggregatedResponses() {
 	m.numAggregatedResponses.Inc()
 }
 
+func (m *Metrics) SetAggregatorIsLeader(isLeader bool) {
+	value := 0.0
+	if isLeader {
+		value = 1.0
+	}
+	m.aggregatorIsLeader.Set(value)
+}
+
+func (m *Metrics) ObserveAggregatorTaskToQuorumLatency(latency time.Duration) {
+	m.aggregatorTaskToQuorumLatency.Observe(latency.Seconds())
+}
+
+func (m *Metrics) ObserveAggregatorQuorumToConfirmationLatency(latency time.Duration) {
+	m.aggregatorQuorumToConfirmationLatency.Observe(latency.Seconds())
+}
+
+func (m *Metrics) SetAggregatorSigningStakePercentage(quorum uint8, percentage float64) {
+	m.aggregatorSigningStakePercentage.WithLabelValues(strconv.Itoa(int(quorum))).Set(percentage)
+}
+
+// ObserveAggregatorRespondToTask records the gas used and the cost, in wei, of a RespondToTaskV2 transaction
+func (m *Metrics) ObserveAggregatorRespondToTask(gasUsed uint64, cost *big.Int) {
+	m.aggregatorRespondToTaskGasUsed.Observe(float64(gasUsed))
+	m.aggregatorRespondToTaskCost.Observe(weiToEth(cost))
+}
+
+func (m *Metrics) ObserveAggregatorSimulatedCostToFeeLimit(simulatedCost *big.Int, respondToTaskFeeLimit *big.Int) {
+	if respondToTaskFeeLimit == nil || respondToTaskFeeLimit.Sign() == 0 {
+		return
+	}
+	ratio, _ := new(big.Rat).SetFrac(simulatedCost, respondToTaskFeeLimit).Float64()
+	m.aggregatorSimulatedCostToFeeLimit.Observe(ratio)
+}
+
+func (m *Metrics) IncAggregatorRespondToTaskFailures(reason string) {
+	m.numAggregatorRespondToTaskFailures.WithLabelValues(reason).Inc()
+}
+
+// SetAggregatorWalletBalance takes the balance in wei
+func (m *Metrics) SetAggregatorWalletBalance(balance *big.Int) {
+	m.aggregatorWalletBalance.Set(weiToEth(balance))
+}
+
+func (m *Metrics) AddAggregatorPostQuorumSignatures(signatures int) {
+	m.numAggregatorPostQuorumSignatures.Add(float64(signatures))
+}
+
+func (m *Metrics) AddAggregatorPostQuorumGasSaved(gas uint64) {
+	m.aggregatorPostQuorumGasSaved.Add(float64(gas))
+}
+
+// SetAggregatorOperatorScore exports the operator scoreboard entry of an operator
+func (m *Metrics) SetAggregatorOperatorScore(operatorId string, tasksSeen int, tasksSigned int, medianDelaySeconds float64, p99DelaySeconds float64, invalidSubmissions int) {
+	m.aggregatorOperatorTasksSeen.WithLabelValues(operatorId).Set(float64(tasksSeen))
+	m.aggregatorOperatorTasksSigned.WithLabelValues(operatorId).Set(float64(tasksSigned))
+	m.aggregatorOperatorResponseDelay.WithLabelValues(operatorId, "0.5").Set(medianDelaySeconds)
+	m.aggregatorOperatorResponseDelay.WithLabelValues(operatorId, "0.99").Set(p99DelaySeconds)
+	m.aggregatorOperatorInvalidSubmissions.WithLabelValues(operatorId).Set(float64(invalidSubmissions))
+}
+
+func (m *Metrics) AddAggregatorEvictedTasks(reason string, tasks int) {
+	m.numAggregatorEvictedTasks.WithLabelValues(reason).Add(float64(tasks))
+}
+
+func (m *Metrics) SetAggregatorTrackedTasks(tasks int) {
+	m.aggregatorTrackedTasks.Set(float64(tasks))
+}
+
+func (m *Metrics) IncAggregatorShadowSimulations(result string) {
+	m.numAggregatorShadowSimulations.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) AddAggregatorBackfilledTasks(tasks int) {
+	m.numAggregatorBackfilledTasks.Add(float64(tasks))
+}
+
+func (m *Metrics) AddAggregatorTelemetryExportedEvents(exporter string, events int) {
+	m.numAggregatorTelemetryExportedEvents.WithLabelValues(exporter).Add(float64(events))
+}
+
+func (m *Metrics) AddAggregatorTelemetryDroppedEvents(reason string, events int) {
+	m.numAggregatorTelemetryDroppedEvents.WithLabelValues(reason).Add(float64(events))
+}
+
+func (m *Metrics) SetAggregatorTelemetryQueueLength(events int) {
+	m.aggregatorTelemetryQueueLength.Set(float64(events))
+}
+
+func weiToEth(wei *big.Int) float64 {
+	eth, _ := new(big.Rat).SetFrac(wei, big.NewInt(1e18)).Float64()
+	return eth
+}
+
 func (m *Metrics) IncOperatorTaskResponses() {
 	m.numOperatorTaskResponses.Inc()
 }
+
+func (m *Metrics) SetOperatorVerificationsConcurrency(class string, concurrency int) {
+	m.operatorVerificationsConcurrency.WithLabelValues(class).Set(float64(concurrency))
+}
+
+func (m *Metrics) SetOperatorVerificationTimeout(provingSystem string, timeout time.Duration) {
+	m.operatorVerificationTimeout.WithLabelValues(provingSystem).Set(timeout.Seconds())
+}
+
+func (m *Metrics) SetOperatorLocallyDisabledVerifier(provingSystem string, disabled bool) {
+	value := 0.0
+	if disabled {
+		value = 1.0
+	}
+	m.operatorLocallyDisabledVerifiers.WithLabelValues(provingSystem).Set(value)
+}
+
+func (m *Metrics) IncOperatorLocallyRejectedBatches(provingSystem string) {
+	m.numOperatorLocallyRejectedBatches.WithLabelValues(provingSystem).Inc()
+}
+
+func (m *Metrics) IncOperatorVerificationTimeouts(provingSystem string) {
+	m.numOperatorVerificationTimeouts.WithLabelValues(provingSystem).Inc()
+}
+
+func (m *Metrics) SetOperatorResponseOutboxDepth(depth int) {
+	m.operatorResponseOutboxDepth.Set(float64(depth))
+}
+
+func (m *Metrics) SetOperatorAggregatorEndpointUp(endpoint string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	m.operatorAggregatorEndpointUp.WithLabelValues(endpoint).Set(value)
+}
+
+func (m *Metrics) SetOperatorDisabledVerifier(provingSystem string, disabled bool) {
+	value := 0.0
+	if disabled {
+		value = 1.0
+	}
+	m.operatorDisabledVerifiers.WithLabelValues(provingSystem).Set(value)
+}
+
+func (m *Metrics) IncOperatorVerifierStatusChanges(provingSystem string) {
+	m.numOperatorVerifierStatusChanges.WithLabelValues(provingSystem).Inc()
+}
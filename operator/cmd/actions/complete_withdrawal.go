@@ -0,0 +1,116 @@
+package actions
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var (
+	FromBlockFlag = &cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: "Block from which to look for queued withdrawals",
+		Value: 0,
+	}
+)
+
+var CompleteWithdrawalCommand = &cli.Command{
+	Name:        "complete-withdrawal",
+	Usage:       "Complete the queued withdrawals whose delay has passed",
+	Description: "CLI command to complete the operator queued withdrawals, receiving the underlying tokens",
+	Flags:       completeWithdrawalFlags,
+	Action:      completeWithdrawalMain,
+}
+
+var completeWithdrawalFlags = []cli.Flag{
+	FromBlockFlag,
+	config.ConfigFileFlag,
+}
+
+func completeWithdrawalMain(ctx *cli.Context) error {
+	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	logger := config.BaseConfig.Logger
+	operatorAddr := config.Operator.Address
+
+	eigenLayerClients, err := newEigenLayerClients(config)
+	if err != nil {
+		return err
+	}
+	delegationManager := eigenLayerClients.bindings.DelegationManager
+
+	currentBlock, err := config.BaseConfig.EthRpcClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+
+	logs, err := delegationManager.FilterWithdrawalQueued(&bind.FilterOpts{Start: ctx.Uint64(FromBlockFlag.Name), Context: context.Background()})
+	if err != nil {
+		logger.Error("Error getting queued withdrawals", "err", err)
+		return err
+	}
+	defer logs.Close()
+
+	completed := 0
+	for logs.Next() {
+		withdrawal := logs.Event.Withdrawal
+		if withdrawal.Withdrawer != operatorAddr {
+			continue
+		}
+
+		pending, err := delegationManager.PendingWithdrawals(&bind.CallOpts{}, logs.Event.WithdrawalRoot)
+		if err != nil {
+			return err
+		}
+		if !pending {
+			// Already completed
+			continue
+		}
+
+		delay, err := delegationManager.GetWithdrawalDelay(&bind.CallOpts{}, withdrawal.Strategies)
+		if err != nil {
+			return err
+		}
+		withdrawableBlock := new(big.Int).Add(big.NewInt(int64(withdrawal.StartBlock)), delay)
+		if withdrawableBlock.Cmp(new(big.Int).SetUint64(currentBlock)) > 0 {
+			logger.Info("Withdrawal is not completable yet",
+				"withdrawalRoot", common.Hash(logs.Event.WithdrawalRoot), "completableAtBlock", withdrawableBlock)
+			continue
+		}
+
+		tokens := make([]common.Address, len(withdrawal.Strategies))
+		for i, strategyAddr := range withdrawal.Strategies {
+			_, tokens[i], err = eigenLayerClients.reader.GetStrategyAndUnderlyingToken(&bind.CallOpts{}, strategyAddr)
+			if err != nil {
+				return err
+			}
+		}
+
+		noSendTxOpts, err := eigenLayerClients.txMgr.GetNoSendTxOpts()
+		if err != nil {
+			return err
+		}
+		tx, err := delegationManager.CompleteQueuedWithdrawal(noSendTxOpts, withdrawal, tokens, big.NewInt(0), true)
+		if err != nil {
+			logger.Error("Error building complete withdrawal transaction", "err", err)
+			return err
+		}
+		receipt, err := eigenLayerClients.txMgr.Send(context.Background(), tx, true)
+		if err != nil {
+			logger.Errorf("Error completing withdrawal")
+			return err
+		}
+
+		logger.Info("Withdrawal completed", "withdrawalRoot", common.Hash(logs.Event.WithdrawalRoot), "txHash", receipt.TxHash)
+		completed++
+	}
+	if err := logs.Error(); err != nil {
+		return err
+	}
+
+	logger.Info("Finished completing withdrawals", "completed", completed)
+	return nil
+}
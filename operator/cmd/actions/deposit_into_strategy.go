@@ -5,11 +5,6 @@ import (
 	"log"
 	"math/big"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
-	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
-	"github.com/Layr-Labs/eigensdk-go/metrics"
-	"github.com/Layr-Labs/eigensdk-go/signerv2"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
@@ -59,33 +54,12 @@ func depositIntoStrategyMain(ctx *cli.Context) error {
 	log.Println("Depositing into strategy", strategyAddressStr)
 	strategyAddr := common.HexToAddress(strategyAddressStr)
 
-	delegationManagerAddr := config.BaseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr
-	avsDirectoryAddr := config.BaseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr
-
-	signerConfig := signerv2.Config{
-		PrivateKey: config.EcdsaConfig.PrivateKey,
-	}
-	signerFn, _, err := signerv2.SignerFromConfig(signerConfig, config.BaseConfig.ChainId)
-	if err != nil {
-		return err
-	}
-	w, err := wallet.NewPrivateKeyWallet(&config.BaseConfig.EthRpcClient, signerFn,
-		config.Operator.Address, config.BaseConfig.Logger)
-
-	if err != nil {
-		return err
-	}
-
-	txMgr := txmgr.NewSimpleTxManager(w, &config.BaseConfig.EthRpcClient, config.BaseConfig.Logger,
-		config.Operator.Address)
-	eigenMetrics := metrics.NewNoopMetrics()
-	eigenLayerWriter, err := elcontracts.BuildELChainWriter(delegationManagerAddr, avsDirectoryAddr,
-		&config.BaseConfig.EthRpcClient, config.BaseConfig.Logger, eigenMetrics, txMgr)
+	eigenLayerClients, err := newEigenLayerClients(config)
 	if err != nil {
 		return err
 	}
 
-	_, err = eigenLayerWriter.DepositERC20IntoStrategy(context.Background(), strategyAddr, amount, true)
+	_, err = eigenLayerClients.writer.DepositERC20IntoStrategy(context.Background(), strategyAddr, amount, true)
 	if err != nil {
 		config.BaseConfig.Logger.Errorf("Error depositing into strategy")
 		return err
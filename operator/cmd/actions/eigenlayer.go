@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	"github.com/Layr-Labs/eigensdk-go/metrics"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// eigenLayerClients groups the EigenLayer core contract bindings and clients
+// used by the staking and delegation commands of the operator CLI
+type eigenLayerClients struct {
+	bindings *elcontracts.ContractBindings
+	reader   *elcontracts.ChainReader
+	writer   *elcontracts.ChainWriter
+	txMgr    *txmgr.SimpleTxManager
+}
+
+func newEigenLayerClients(config *config.OperatorConfig) (*eigenLayerClients, error) {
+	delegationManagerAddr := config.BaseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr
+	avsDirectoryAddr := config.BaseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr
+
+	signerConfig := signerv2.Config{
+		PrivateKey: config.EcdsaConfig.PrivateKey,
+	}
+	signerFn, _, err := signerv2.SignerFromConfig(signerConfig, config.BaseConfig.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	w, err := wallet.NewPrivateKeyWallet(&config.BaseConfig.EthRpcClient, signerFn,
+		config.Operator.Address, config.BaseConfig.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	txMgr := txmgr.NewSimpleTxManager(w, &config.BaseConfig.EthRpcClient, config.BaseConfig.Logger,
+		config.Operator.Address)
+
+	bindings, err := elcontracts.NewEigenlayerContractBindings(delegationManagerAddr, avsDirectoryAddr,
+		&config.BaseConfig.EthRpcClient, config.BaseConfig.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := elcontracts.NewChainReader(bindings.Slasher, bindings.DelegationManager, bindings.StrategyManager,
+		bindings.AvsDirectory, bindings.RewardsCoordinator, config.BaseConfig.Logger, &config.BaseConfig.EthRpcClient)
+
+	eigenMetrics := metrics.NewNoopMetrics()
+	writer := elcontracts.NewChainWriter(bindings.Slasher, bindings.DelegationManager, bindings.StrategyManager,
+		bindings.RewardsCoordinator, bindings.AvsDirectory, bindings.StrategyManagerAddr, reader,
+		&config.BaseConfig.EthRpcClient, config.BaseConfig.Logger, eigenMetrics, txMgr)
+
+	return &eigenLayerClients{
+		bindings: bindings,
+		reader:   reader,
+		writer:   writer,
+		txMgr:    txMgr,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var ListRestakeableStrategiesCommand = &cli.Command{
+	Name:        "list-restakeable-strategies",
+	Usage:       "List the strategies that can be restaked in Aligned Layer",
+	Description: "CLI command to list the strategies accepted by the Aligned Layer service manager",
+	Flags:       listStrategiesFlags,
+	Action:      listRestakeableStrategiesMain,
+}
+
+var listStrategiesFlags = []cli.Flag{
+	config.ConfigFileFlag,
+}
+
+func listRestakeableStrategiesMain(ctx *cli.Context) error {
+	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+
+	avsReader, err := chainio.NewAvsReaderFromConfig(config.BaseConfig, config.EcdsaConfig)
+	if err != nil {
+		return err
+	}
+
+	strategies, err := avsReader.GetRestakeableStrategies()
+	if err != nil {
+		config.BaseConfig.Logger.Error("Error getting restakeable strategies", "err", err)
+		return err
+	}
+
+	eigenLayerClients, err := newEigenLayerClients(config)
+	if err != nil {
+		return err
+	}
+
+	for _, strategyAddr := range strategies {
+		_, underlyingTokenAddr, err := eigenLayerClients.reader.GetStrategyAndUnderlyingToken(&bind.CallOpts{}, strategyAddr)
+		if err != nil {
+			config.BaseConfig.Logger.Warn("Could not get underlying token of strategy", "strategy", strategyAddr, "err", err)
+			continue
+		}
+		config.BaseConfig.Logger.Info("Restakeable strategy", "strategy", strategyAddr, "underlyingToken", underlyingTokenAddr)
+	}
+
+	return nil
+}
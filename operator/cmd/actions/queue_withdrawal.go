@@ -0,0 +1,84 @@
+package actions
+
+import (
+	"context"
+	"log"
+	"math/big"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var (
+	SharesFlag = &cli.StringFlag{
+		Name:     "shares",
+		Usage:    "Amount of shares to withdraw",
+		Required: true,
+	}
+)
+
+var QueueWithdrawalCommand = &cli.Command{
+	Name:        "queue-withdrawal",
+	Usage:       "Queue a withdrawal of shares from a strategy",
+	Description: "CLI command to queue a withdrawal of the operator shares from a given strategy",
+	Flags:       queueWithdrawalFlags,
+	Action:      queueWithdrawalMain,
+}
+
+var queueWithdrawalFlags = []cli.Flag{
+	SharesFlag,
+	StrategyAddressFlag,
+	config.ConfigFileFlag,
+}
+
+func queueWithdrawalMain(ctx *cli.Context) error {
+	shares, ok := new(big.Int).SetString(ctx.String(SharesFlag.Name), 10)
+	if !ok || shares.Cmp(big.NewInt(0)) <= 0 {
+		log.Println("Shares must be a number greater than 0")
+		return nil
+	}
+
+	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	strategyAddressStr := ctx.String(StrategyAddressFlag.Name)
+	if strategyAddressStr == "" {
+		log.Println("Strategy address is required")
+		return nil
+	}
+	log.Println("Queueing withdrawal from strategy", strategyAddressStr)
+	strategyAddr := common.HexToAddress(strategyAddressStr)
+
+	eigenLayerClients, err := newEigenLayerClients(config)
+	if err != nil {
+		return err
+	}
+
+	noSendTxOpts, err := eigenLayerClients.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return err
+	}
+
+	params := []delegationmanager.IDelegationManagerQueuedWithdrawalParams{
+		{
+			Strategies: []common.Address{strategyAddr},
+			Shares:     []*big.Int{shares},
+			Withdrawer: config.Operator.Address,
+		},
+	}
+	tx, err := eigenLayerClients.bindings.DelegationManager.QueueWithdrawals(noSendTxOpts, params)
+	if err != nil {
+		config.BaseConfig.Logger.Error("Error building queue withdrawal transaction", "err", err)
+		return err
+	}
+
+	receipt, err := eigenLayerClients.txMgr.Send(context.Background(), tx, true)
+	if err != nil {
+		config.BaseConfig.Logger.Errorf("Error queueing withdrawal")
+		return err
+	}
+
+	config.BaseConfig.Logger.Info("Withdrawal queued. It can be completed with `complete-withdrawal` once the withdrawal delay has passed",
+		"txHash", receipt.TxHash, "blockNumber", receipt.BlockNumber)
+	return nil
+}
@@ -25,7 +25,7 @@ var RegisterCommand = &cli.Command{
 func registerOperatorMain(ctx *cli.Context) error {
 	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
 
-	quorumNumbers := []byte{0}
+	quorumNumbers := config.Operator.QuorumNumbers
 
 	// Generate salt and expiry
 	privateKeyBytes := []byte(config.BlsConfig.KeyPair.PrivKey.String())
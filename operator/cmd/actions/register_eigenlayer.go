@@ -0,0 +1,72 @@
+package actions
+
+import (
+	"context"
+	"log"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var registerEigenLayerFlags = []cli.Flag{
+	config.ConfigFileFlag,
+}
+
+var RegisterEigenLayerCommand = &cli.Command{
+	Name:        "register-eigenlayer",
+	Usage:       "Register operator with EigenLayer",
+	Description: "CLI command to register the operator in the EigenLayer delegation manager",
+	Flags:       registerEigenLayerFlags,
+	Action:      registerEigenLayerMain,
+}
+
+func registerEigenLayerMain(ctx *cli.Context) error {
+	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	logger := config.BaseConfig.Logger
+
+	if config.Operator.StakerOptOutWindowBlocks < 0 {
+		log.Println("Staker opt out window blocks must not be negative")
+		return nil
+	}
+
+	eigenLayerClients, err := newEigenLayerClients(config)
+	if err != nil {
+		return err
+	}
+
+	registered, err := eigenLayerClients.bindings.DelegationManager.IsOperator(&bind.CallOpts{}, config.Operator.Address)
+	if err != nil {
+		return err
+	}
+	if registered {
+		logger.Info("Operator is already registered with EigenLayer", "operator", config.Operator.Address)
+		return nil
+	}
+
+	operatorDetails := delegationmanager.IDelegationManagerOperatorDetails{
+		DeprecatedEarningsReceiver: config.Operator.EarningsReceiverAddress,
+		DelegationApprover:         config.Operator.DelegationApproverAddress,
+		StakerOptOutWindowBlocks:   uint32(config.Operator.StakerOptOutWindowBlocks),
+	}
+
+	noSendTxOpts, err := eigenLayerClients.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return err
+	}
+	tx, err := eigenLayerClients.bindings.DelegationManager.RegisterAsOperator(noSendTxOpts, operatorDetails, config.Operator.MetadataUrl)
+	if err != nil {
+		logger.Error("Error building register as operator transaction", "err", err)
+		return err
+	}
+
+	receipt, err := eigenLayerClients.txMgr.Send(context.Background(), tx, true)
+	if err != nil {
+		logger.Error("Failed to register operator with EigenLayer", "err", err)
+		return err
+	}
+
+	logger.Info("Operator registered with EigenLayer", "operator", config.Operator.Address, "txHash", receipt.TxHash)
+	return nil
+}
@@ -34,9 +34,8 @@ func operatorMain(ctx *cli.Context) error {
 		return err
 	}
 
-	err = operator.SendTelemetryData(ctx)
-	if err != nil {
-		return err
+	if operatorConfig.Operator.OperatorTrackerIpPortAddress != "" {
+		go operator.ProcessHeartbeats(ctx.App.Version)
 	}
 
 	operator.Logger.Info("Operator starting...")
@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var StrategySharesCommand = &cli.Command{
+	Name:        "strategy-shares",
+	Usage:       "Show the shares the operator holds in each strategy",
+	Description: "CLI command to show the deposited and delegated shares of the operator per strategy",
+	Flags:       strategySharesFlags,
+	Action:      strategySharesMain,
+}
+
+var strategySharesFlags = []cli.Flag{
+	config.ConfigFileFlag,
+}
+
+func strategySharesMain(ctx *cli.Context) error {
+	config := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+
+	eigenLayerClients, err := newEigenLayerClients(config)
+	if err != nil {
+		return err
+	}
+
+	operatorAddr := config.Operator.Address
+
+	// Shares deposited by the operator address itself, which are the ones it can withdraw
+	strategies, shares, err := eigenLayerClients.bindings.DelegationManager.GetDelegatableShares(&bind.CallOpts{}, operatorAddr)
+	if err != nil {
+		config.BaseConfig.Logger.Error("Error getting delegatable shares", "err", err)
+		return err
+	}
+
+	if len(strategies) == 0 {
+		config.BaseConfig.Logger.Info("Operator has no deposited shares", "operator", operatorAddr)
+		return nil
+	}
+
+	for i, strategyAddr := range strategies {
+		// Shares delegated to the operator, including the ones from other stakers
+		delegatedShares, err := eigenLayerClients.reader.GetOperatorSharesInStrategy(&bind.CallOpts{}, operatorAddr, strategyAddr)
+		if err != nil {
+			config.BaseConfig.Logger.Warn("Could not get delegated shares", "strategy", strategyAddr, "err", err)
+			continue
+		}
+
+		config.BaseConfig.Logger.Info("Strategy shares",
+			"strategy", strategyAddr,
+			"depositedShares", shares[i],
+			"delegatedShares", delegatedShares)
+	}
+
+	return nil
+}
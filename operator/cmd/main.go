@@ -20,6 +20,11 @@ func main() {
 			actions.RegisterCommand,
 			actions.StartCommand,
 			actions.DepositIntoStrategyCommand,
+			actions.ListRestakeableStrategiesCommand,
+			actions.StrategySharesCommand,
+			actions.QueueWithdrawalCommand,
+			actions.CompleteWithdrawalCommand,
+			actions.RegisterEigenLayerCommand,
 		},
 		Version: Version,
 	}
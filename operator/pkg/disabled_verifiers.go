@@ -0,0 +1,148 @@
+package operator
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+const DisabledVerifiersReconcileInterval = 5 * time.Minute
+
+// DisabledVerifiersCache is an in-memory view of the on-chain disabled verifiers bitmap.
+// It is loaded at startup, updated with the VerifierDisabled/VerifierEnabled events and
+// periodically reconciled with the contract, so batches don't need an extra RPC call.
+type DisabledVerifiersCache struct {
+	bitmap *big.Int
+	loaded bool
+	mutex  sync.RWMutex
+}
+
+// VerifierStatusChange is a verifier whose on-chain status changed
+type VerifierStatusChange struct {
+	VerifierIdx uint8
+	Disabled    bool
+}
+
+func NewDisabledVerifiersCache() *DisabledVerifiersCache {
+	return &DisabledVerifiersCache{bitmap: new(big.Int)}
+}
+
+// Bitmap returns a copy of the cached bitmap, and false if it was never loaded from the contract
+func (c *DisabledVerifiersCache) Bitmap() (*big.Int, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return new(big.Int).Set(c.bitmap), c.loaded
+}
+
+// SetStatus sets the status of a verifier. Returns true if it changed.
+func (c *DisabledVerifiersCache) SetStatus(verifierIdx uint8, disabled bool) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bit := uint(0)
+	if disabled {
+		bit = 1
+	}
+	if c.bitmap.Bit(int(verifierIdx)) == bit {
+		return false
+	}
+	c.bitmap.SetBit(c.bitmap, int(verifierIdx), bit)
+	return true
+}
+
+// Reconcile replaces the cached bitmap with the one read from the contract and returns the
+// verifiers whose status differed, which means an event was missed or not received yet
+func (c *DisabledVerifiersCache) Reconcile(bitmap *big.Int) []VerifierStatusChange {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var changes []VerifierStatusChange
+	if c.loaded {
+		maxLen := c.bitmap.BitLen()
+		if bitmap.BitLen() > maxLen {
+			maxLen = bitmap.BitLen()
+		}
+		for i := 0; i < maxLen; i++ {
+			if c.bitmap.Bit(i) != bitmap.Bit(i) {
+				changes = append(changes, VerifierStatusChange{VerifierIdx: uint8(i), Disabled: bitmap.Bit(i) == 1})
+			}
+		}
+	}
+
+	c.bitmap = new(big.Int).Set(bitmap)
+	c.loaded = true
+	return changes
+}
+
+// getDisabledVerifiers returns the disabled verifiers bitmap from the cache, falling back to the
+// contract if it could not be loaded yet
+func (o *Operator) getDisabledVerifiers() (*big.Int, error) {
+	bitmap, loaded := o.disabledVerifiers.Bitmap()
+	if loaded {
+		return bitmap, nil
+	}
+
+	bitmap, err := o.avsReader.DisabledVerifiers()
+	if err != nil {
+		return nil, err
+	}
+	o.reconcileDisabledVerifiers(bitmap)
+	return bitmap, nil
+}
+
+// ProcessDisabledVerifiersReconciliation is a long-lived goroutine that periodically reconciles the
+// cached disabled verifiers bitmap with the contract
+func (o *Operator) ProcessDisabledVerifiersReconciliation() {
+	ticker := time.NewTicker(DisabledVerifiersReconcileInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		bitmap, err := o.avsReader.DisabledVerifiers()
+		if err != nil {
+			o.Logger.Warn("Could not reconcile disabled verifiers", "err", err)
+			continue
+		}
+		o.reconcileDisabledVerifiers(bitmap)
+	}
+}
+
+func (o *Operator) reconcileDisabledVerifiers(bitmap *big.Int) {
+	for _, change := range o.disabledVerifiers.Reconcile(bitmap) {
+		o.Logger.Warn("Verifier status was out of date, updated from the contract",
+			"verifier", verifierName(change.VerifierIdx), "disabled", change.Disabled)
+		o.reportVerifierStatus(change.VerifierIdx, change.Disabled)
+	}
+	o.reportDisabledVerifiers(bitmap)
+}
+
+// handleVerifierStatusChange applies a VerifierDisabled/VerifierEnabled event to the cache
+func (o *Operator) handleVerifierStatusChange(verifierIdx uint8, disabled bool) {
+	if !o.disabledVerifiers.SetStatus(verifierIdx, disabled) {
+		return
+	}
+	o.Logger.Info("Verifier status changed on-chain", "verifier", verifierName(verifierIdx), "disabled", disabled)
+	o.reportVerifierStatus(verifierIdx, disabled)
+}
+
+func (o *Operator) reportVerifierStatus(verifierIdx uint8, disabled bool) {
+	name := verifierName(verifierIdx)
+	o.metrics.SetOperatorDisabledVerifier(name, disabled)
+	o.metrics.IncOperatorVerifierStatusChanges(name)
+}
+
+func (o *Operator) reportDisabledVerifiers(bitmap *big.Int) {
+	for _, provingSystemId := range ProvingSystems {
+		o.metrics.SetOperatorDisabledVerifier(verifierName(uint8(provingSystemId)), IsVerifierDisabled(bitmap, provingSystemId))
+	}
+}
+
+func verifierName(verifierIdx uint8) string {
+	name, err := common.ProvingSystemIdToString(common.ProvingSystemId(verifierIdx))
+	if err != nil {
+		return fmt.Sprintf("verifier_%d", verifierIdx)
+	}
+	return name
+}
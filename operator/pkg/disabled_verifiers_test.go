@@ -0,0 +1,88 @@
+package operator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+func TestDisabledVerifiersCache(t *testing.T) {
+	cache := NewDisabledVerifiersCache()
+	if _, loaded := cache.Bitmap(); loaded {
+		t.Fatalf("Cache should not be loaded before the first reconciliation")
+	}
+
+	// SP1 disabled on-chain
+	if changes := cache.Reconcile(big.NewInt(1 << common.SP1)); len(changes) != 0 {
+		t.Errorf("The first reconciliation should not report changes, got %+v", changes)
+	}
+
+	if !cache.SetStatus(uint8(common.Risc0), true) {
+		t.Errorf("Disabling Risc0 should change the bitmap")
+	}
+	if cache.SetStatus(uint8(common.Risc0), true) {
+		t.Errorf("Disabling Risc0 twice should not change the bitmap")
+	}
+	if !cache.SetStatus(uint8(common.SP1), false) {
+		t.Errorf("Enabling SP1 should change the bitmap")
+	}
+
+	bitmap, loaded := cache.Bitmap()
+	if !loaded || IsVerifierDisabled(bitmap, common.SP1) || !IsVerifierDisabled(bitmap, common.Risc0) {
+		t.Fatalf("Unexpected bitmap %b", bitmap)
+	}
+
+	// The contract says SP1 is still disabled and Risc0 enabled, so both events were missed
+	changes := cache.Reconcile(big.NewInt(1 << common.SP1))
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	for _, change := range changes {
+		if change.VerifierIdx == uint8(common.SP1) && !change.Disabled {
+			t.Errorf("SP1 should be reported as disabled")
+		}
+		if change.VerifierIdx == uint8(common.Risc0) && change.Disabled {
+			t.Errorf("Risc0 should be reported as enabled")
+		}
+	}
+}
+
+func TestGetDisabledVerifiersLoadsFromContract(t *testing.T) {
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	reader := fakes.NewFakeAvsReader()
+	reader.SetDisabledVerifiers(big.NewInt(1 << common.SP1))
+	o := &Operator{
+		avsReader:         reader,
+		disabledVerifiers: NewDisabledVerifiersCache(),
+		Logger:            logger,
+		metrics:           metrics.NewMetrics("", prometheus.NewRegistry(), logger),
+	}
+
+	bitmap, err := o.getDisabledVerifiers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !IsVerifierDisabled(bitmap, common.SP1) {
+		t.Fatalf("SP1 should be disabled, got bitmap %b", bitmap)
+	}
+
+	// Once loaded, the cache is used without reading the contract
+	reader.SetError(errors.New("connection refused"))
+	o.disabledVerifiers.SetStatus(uint8(common.Risc0), true)
+	bitmap, err = o.getDisabledVerifiers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !IsVerifierDisabled(bitmap, common.SP1) || !IsVerifierDisabled(bitmap, common.Risc0) {
+		t.Fatalf("SP1 and Risc0 should be disabled, got bitmap %b", bitmap)
+	}
+}
@@ -0,0 +1,200 @@
+package operator
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+const (
+	DefaultHeartbeatInterval = 1 * time.Minute
+	HeartbeatMaxRetries      = 3
+	HeartbeatInitialBackoff  = 2 * time.Second
+	HeartbeatRequestTimeout  = 10 * time.Second
+	HeartbeatEndpointPath    = "/heartbeats"
+)
+
+// Heartbeat is the operator state periodically reported to the operator tracker
+type Heartbeat struct {
+	Version            string   `json:"version"`
+	Address            string   `json:"address"`
+	OperatorId         string   `json:"operator_id"`
+	LastProcessedBlock uint32   `json:"last_processed_block"`
+	VerifiedBatches    uint64   `json:"verified_batches"`
+	RejectedBatches    uint64   `json:"rejected_batches"`
+	EnabledVerifiers   []string `json:"enabled_verifiers"`
+	EthRpcHealthy      bool     `json:"eth_rpc_healthy"`
+	// Hosts of the eth endpoints, without paths that may carry API keys
+	EthRpcUrl           string                     `json:"eth_rpc_url"`
+	EthRpcUrlFallback   string                     `json:"eth_rpc_url_fallback"`
+	EthWsUrl            string                     `json:"eth_ws_url"`
+	EthWsUrlFallback    string                     `json:"eth_ws_url_fallback"`
+	AggregatorEndpoints []AggregatorEndpointStatus `json:"aggregator_endpoints"`
+	// Timestamp and Nonce make every signed payload unique, so heartbeats can't be replayed
+	Timestamp int64  `json:"timestamp"`
+	Nonce     string `json:"nonce"`
+}
+
+// SignedHeartbeat is the body posted to the tracker. The signature is over the keccak256 of
+// the payload bytes, which are sent as is so the tracker can verify them without re-encoding.
+type SignedHeartbeat struct {
+	Payload   string `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// HeartbeatSender signs heartbeats with the operator ECDSA key and posts them to the operator tracker
+type HeartbeatSender struct {
+	endpoint   string
+	privateKey *ecdsa.PrivateKey
+	httpClient *http.Client
+	backoff    time.Duration
+	logger     logging.Logger
+}
+
+func NewHeartbeatSender(trackerUrl string, privateKey *ecdsa.PrivateKey, logger logging.Logger) *HeartbeatSender {
+	return &HeartbeatSender{
+		endpoint:   trackerUrl + HeartbeatEndpointPath,
+		privateKey: privateKey,
+		httpClient: &http.Client{Timeout: HeartbeatRequestTimeout},
+		backoff:    HeartbeatInitialBackoff,
+		logger:     logger,
+	}
+}
+
+// Sign stamps the heartbeat with the given time and a random nonce, and signs it
+func (s *HeartbeatSender) Sign(heartbeat Heartbeat, now time.Time) (*SignedHeartbeat, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	heartbeat.Timestamp = now.Unix()
+	heartbeat.Nonce = hex.EncodeToString(nonce)
+
+	payload, err := json.Marshal(heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(crypto.Keccak256(payload), s.privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedHeartbeat{Payload: string(payload), Signature: signature}, nil
+}
+
+// Send signs and posts the heartbeat, retrying with exponential backoff.
+// Each attempt is signed again, so retries carry a fresh timestamp and nonce.
+func (s *HeartbeatSender) Send(heartbeat Heartbeat) error {
+	backoff := s.backoff
+	var err error
+	for attempt := 1; attempt <= HeartbeatMaxRetries; attempt++ {
+		err = s.post(heartbeat)
+		if err == nil {
+			return nil
+		}
+		s.logger.Warn("Error sending heartbeat to operator tracker", "attempt", attempt, "err", err)
+		if attempt < HeartbeatMaxRetries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+func (s *HeartbeatSender) post(heartbeat Heartbeat) error {
+	signedHeartbeat, err := s.Sign(heartbeat, time.Now())
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(signedHeartbeat)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.httpClient.Post(s.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return nil
+}
+
+// ProcessHeartbeats is a long-lived goroutine that sends a heartbeat to the operator tracker on every interval
+func (o *Operator) ProcessHeartbeats(version string) {
+	interval := o.Config.Operator.HeartbeatInterval
+	if interval == 0 {
+		interval = DefaultHeartbeatInterval
+	}
+
+	sender := NewHeartbeatSender(o.Config.Operator.OperatorTrackerIpPortAddress, o.Config.EcdsaConfig.PrivateKey, o.Logger)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := sender.Send(o.buildHeartbeat(version))
+		if err != nil {
+			// Heartbeats are best effort, the next one is sent on the next tick anyway
+			o.Logger.Warn("Could not send heartbeat to operator tracker", "err", err)
+		} else {
+			o.Logger.Debug("Heartbeat sent to operator tracker")
+		}
+		<-ticker.C
+	}
+}
+
+func (o *Operator) buildHeartbeat(version string) Heartbeat {
+	heartbeat := Heartbeat{
+		Version:             version,
+		Address:             o.Address.Hex(),
+		OperatorId:          "0x" + hex.EncodeToString(o.OperatorId[:]),
+		LastProcessedBlock:  o.LastProcessedBlock(),
+		VerifiedBatches:     o.verifiedBatches.Load(),
+		RejectedBatches:     o.rejectedBatches.Load(),
+		EnabledVerifiers:    []string{},
+		AggregatorEndpoints: o.aggRpcClient.Endpoints(),
+		EthRpcUrl:           hostOrEmpty(o.Config.BaseConfig.EthRpcUrl),
+		EthRpcUrlFallback:   hostOrEmpty(o.Config.BaseConfig.EthRpcUrlFallback),
+		EthWsUrl:            hostOrEmpty(o.Config.BaseConfig.EthWsUrl),
+		EthWsUrlFallback:    hostOrEmpty(o.Config.BaseConfig.EthWsUrlFallback),
+	}
+
+	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
+	heartbeat.EthRpcHealthy = err == nil
+
+	for _, provingSystemId := range ProvingSystems {
+		if o.verificationPolicy.IsLocallyDisabled(provingSystemId) {
+			continue
+		}
+		// If the bitmap can't be read, report the verifiers enabled by the local policy only
+		if err == nil && IsVerifierDisabled(disabledVerifiersBitmap, provingSystemId) {
+			continue
+		}
+		name, _ := common.ProvingSystemIdToString(provingSystemId)
+		heartbeat.EnabledVerifiers = append(heartbeat.EnabledVerifiers, name)
+	}
+
+	return heartbeat
+}
+
+// hostOrEmpty returns the host of the URL, or an empty string if it is not a valid URL
+func hostOrEmpty(url string) string {
+	host, err := BaseUrlOnly(url)
+	if err != nil {
+		return ""
+	}
+	return host
+}
@@ -0,0 +1,100 @@
+package operator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestHeartbeatSender(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Could not generate key: %v", err)
+	}
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+
+	// The tracker stand-in fails the first request to exercise the retries
+	var mutex sync.Mutex
+	var received []SignedHeartbeat
+	requests := 0
+	tracker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests++
+		if r.URL.Path != HeartbeatEndpointPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var signedHeartbeat SignedHeartbeat
+		if err := json.NewDecoder(r.Body).Decode(&signedHeartbeat); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, signedHeartbeat)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer tracker.Close()
+
+	sender := NewHeartbeatSender(tracker.URL, privateKey, logger)
+	sender.backoff = time.Millisecond
+
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(Heartbeat{Version: "v0.0.1", LastProcessedBlock: 10}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if requests != 3 || len(received) != 2 {
+		t.Fatalf("Expected 3 requests and 2 heartbeats, got %d and %d", requests, len(received))
+	}
+
+	nonces := make(map[string]bool)
+	for _, signedHeartbeat := range received {
+		publicKey, err := crypto.SigToPub(crypto.Keccak256([]byte(signedHeartbeat.Payload)), signedHeartbeat.Signature)
+		if err != nil {
+			t.Fatalf("Could not recover signer: %v", err)
+		}
+		if crypto.PubkeyToAddress(*publicKey) != crypto.PubkeyToAddress(privateKey.PublicKey) {
+			t.Errorf("Heartbeat was not signed by the operator key")
+		}
+
+		var heartbeat Heartbeat
+		if err := json.Unmarshal([]byte(signedHeartbeat.Payload), &heartbeat); err != nil {
+			t.Fatalf("Could not decode payload: %v", err)
+		}
+		if heartbeat.Version != "v0.0.1" || heartbeat.LastProcessedBlock != 10 || heartbeat.Timestamp == 0 {
+			t.Errorf("Unexpected heartbeat payload: %+v", heartbeat)
+		}
+		if nonces[heartbeat.Nonce] {
+			t.Errorf("Nonce %s was reused", heartbeat.Nonce)
+		}
+		nonces[heartbeat.Nonce] = true
+	}
+}
+
+func TestHeartbeatEthUrlsKeepOnlyTheHost(t *testing.T) {
+	tests := map[string]string{
+		"https://eth-holesky.g.alchemy.com/v2/secret-key": "eth-holesky.g.alchemy.com",
+		"ws://localhost:8546":                             "localhost:8546",
+		"":                                                "",
+		"not a url":                                       "",
+	}
+	for url, expected := range tests {
+		if host := hostOrEmpty(url); host != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, url, host)
+		}
+	}
+}
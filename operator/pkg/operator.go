@@ -9,16 +9,14 @@ import (
 	"fmt"
 	"log"
 	"math/big"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/operator/risc_zero"
-	"golang.org/x/crypto/sha3"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yetanotherco/aligned_layer/metrics"
@@ -49,16 +47,25 @@ type Operator struct {
 	PrivKey                   *ecdsa.PrivateKey
 	KeyPair                   *bls.KeyPair
 	OperatorId                eigentypes.OperatorId
-	avsSubscriber             chainio.AvsSubscriber
-	avsReader                 chainio.AvsReader
+	avsSubscriber             chainio.AvsSubscriberer
+	avsReader                 chainio.AvsReaderer
 	NewTaskCreatedChanV2      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
 	NewTaskCreatedChanV3      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
 	Logger                    logging.Logger
-	aggRpcClient              AggregatorRpcClient
+	aggRpcClient              *AggregatorRpcClient
 	metricsReg                *prometheus.Registry
 	metrics                   *metrics.Metrics
 	lastProcessedBatch        OperatorLastProcessedBatch
 	lastProcessedBatchLogFile string
+	verificationPolicy        *VerificationPolicy
+	responseOutbox            *ResponseOutbox
+	responseOutboxWakeup      chan struct{}
+	BatchVerifiedChan         chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified
+	VerifierDisabledChan      chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled
+	VerifierEnabledChan       chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled
+	disabledVerifiers         *DisabledVerifiersCache
+	verifiedBatches           atomic.Uint64
+	rejectedBatches           atomic.Uint64
 	//Socket  string
 	//Timeout time.Duration
 }
@@ -85,7 +92,10 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 
 	if !registered {
 		log.Println("Operator is not registered with AlignedLayer AVS, registering...")
-		quorumNumbers := []byte{0}
+		quorumNumbers := configuration.Operator.QuorumNumbers
+		if err := avsReader.CheckQuorumsExist(quorumNumbers); err != nil {
+			log.Fatalf("Invalid quorum numbers: %v", err)
+		}
 
 		// Generate salt and expiry
 		privateKeyBytes := []byte(configuration.BlsConfig.KeyPair.PrivKey.String())
@@ -106,11 +116,6 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	newTaskCreatedChanV2 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2)
 	newTaskCreatedChanV3 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
-	rpcClient, err := NewAggregatorRpcClient(configuration.Operator.AggregatorServerIpPortAddress, logger)
-	if err != nil {
-		return nil, fmt.Errorf("could not create RPC client: %s. Is aggregator running?", err)
-	}
-
 	operatorId := eigentypes.OperatorIdFromKeyPair(configuration.BlsConfig.KeyPair)
 	address := configuration.Operator.Address
 	lastProcessedBatchLogFile := configuration.Operator.LastProcessedBatchFilePath
@@ -119,23 +124,70 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 		logger.Fatalf("Config file field: `last_processed_batch_filepath` not provided.")
 	}
 
+	verificationPolicy, err := NewVerificationPolicy(configuration.Operator.VerificationPolicy)
+	if err != nil {
+		logger.Fatalf("Invalid verification policy: %v. This is related to the `verification_policy` field passed in the config file", err)
+	}
+
+	// Pending responses are stored next to the last processed batch file, unless configured otherwise
+	responseOutboxFile := configuration.Operator.ResponseOutboxFilePath
+	if responseOutboxFile == "" {
+		responseOutboxFile = filepath.Join(filepath.Dir(lastProcessedBatchLogFile), ResponseOutboxDefaultFile)
+	}
+	responseOutbox, err := NewResponseOutbox(responseOutboxFile)
+	if err != nil {
+		logger.Fatalf("Error while loading response outbox: %v. This is probably related to the `response_outbox_filepath` field passed in the config file", err)
+	}
+
 	// Metrics
 	reg := prometheus.NewRegistry()
 	operatorMetrics := metrics.NewMetrics(configuration.Operator.MetricsIpPortAddress, reg, logger)
 
+	var rpcSecurity AggregatorRpcSecurity
+	if configuration.Operator.AggregatorTlsEnabled {
+		rpcSecurity.TLSConfig, err = config.NewClientTLSConfig(configuration.Operator.AggregatorTlsCaPath,
+			configuration.Operator.AggregatorTlsCertPath, configuration.Operator.AggregatorTlsKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load aggregator TLS config: %s. This is related to the `aggregator_tls_*` fields passed in the config file", err)
+		}
+	}
+	if configuration.Operator.SignAggregatorRequests {
+		rpcSecurity.SigningKey = configuration.EcdsaConfig.PrivateKey
+	}
+
+	// The operator starts even if no aggregator is reachable, responses are queued in the outbox meanwhile
+	rpcClient, err := NewAggregatorRpcClient(
+		aggregatorEndpoints(configuration),
+		configuration.Operator.AggregatorFanOut,
+		configuration.Operator.AggregatorRpcTimeout,
+		rpcSecurity,
+		logger,
+		operatorMetrics,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create RPC client: %s. This is related to the `aggregator_rpc_server_ip_port_addresses` field passed in the config file", err)
+	}
+
 	operator := &Operator{
 		Config:                    configuration,
 		Logger:                    logger,
-		avsSubscriber:             *avsSubscriber,
-		avsReader:                 *avsReader,
+		avsSubscriber:             avsSubscriber,
+		avsReader:                 avsReader,
 		Address:                   address,
 		NewTaskCreatedChanV2:      newTaskCreatedChanV2,
 		NewTaskCreatedChanV3:      newTaskCreatedChanV3,
-		aggRpcClient:              *rpcClient,
+		aggRpcClient:              rpcClient,
 		OperatorId:                operatorId,
 		metricsReg:                reg,
 		metrics:                   operatorMetrics,
 		lastProcessedBatchLogFile: lastProcessedBatchLogFile,
+		verificationPolicy:        verificationPolicy,
+		responseOutbox:            responseOutbox,
+		responseOutboxWakeup:      make(chan struct{}, 1),
+		BatchVerifiedChan:         make(chan *servicemanager.ContractAlignedLayerServiceManagerBatchVerified),
+		VerifierDisabledChan:      make(chan *servicemanager.ContractAlignedLayerServiceManagerVerifierDisabled),
+		VerifierEnabledChan:       make(chan *servicemanager.ContractAlignedLayerServiceManagerVerifierEnabled),
+		disabledVerifiers:         NewDisabledVerifiersCache(),
 		lastProcessedBatch: OperatorLastProcessedBatch{
 			BlockNumber:        0,
 			batchProcessedChan: make(chan uint32),
@@ -145,6 +197,16 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 		// Socket
 	}
 
+	operator.reportVerificationPolicy()
+
+	// If the bitmap can't be loaded now, it is fetched again with the first batch
+	disabledVerifiersBitmap, err := avsReader.DisabledVerifiers()
+	if err != nil {
+		logger.Warn("Could not load disabled verifiers", "err", err)
+	} else {
+		operator.reconcileDisabledVerifiers(disabledVerifiersBitmap)
+	}
+
 	err = operator.LoadLastProcessedBatch()
 	if err != nil {
 		logger.Fatalf("Error while loading last process batch: %v. This is probably related to the `last_processed_batch_filepath` field passed in the config file", err)
@@ -153,6 +215,21 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	return operator, nil
 }
 
+// aggregatorEndpoints returns the configured aggregator endpoints in priority order.
+// The single `aggregator_rpc_server_ip_port_address` field is kept for backwards compatibility.
+func aggregatorEndpoints(configuration config.OperatorConfig) []string {
+	var endpoints []string
+	if configuration.Operator.AggregatorServerIpPortAddress != "" {
+		endpoints = append(endpoints, configuration.Operator.AggregatorServerIpPortAddress)
+	}
+	for _, endpoint := range configuration.Operator.AggregatorServerIpPortAddresses {
+		if endpoint != configuration.Operator.AggregatorServerIpPortAddress {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
 func (o *Operator) SubscribeToNewTasksV2() (chan error, error) {
 	return o.avsSubscriber.SubscribeToNewTasksV2(o.NewTaskCreatedChanV2)
 }
@@ -161,9 +238,26 @@ func (o *Operator) SubscribeToNewTasksV3() (chan error, error) {
 	return o.avsSubscriber.SubscribeToNewTasksV3(o.NewTaskCreatedChanV3)
 }
 
+func (o *Operator) SubscribeToBatchVerified() (chan error, error) {
+	return o.avsSubscriber.SubscribeToBatchVerified(o.BatchVerifiedChan)
+}
+
+func (o *Operator) SubscribeToVerifierStatusChanges() (chan error, error) {
+	return o.avsSubscriber.SubscribeToVerifierStatusChanges(o.VerifierDisabledChan, o.VerifierEnabledChan)
+}
+
 type OperatorLastProcessedBatch struct {
-	BlockNumber        uint32      `json:"block_number"`
-	batchProcessedChan chan uint32 `json:"-"`
+	BlockNumber        uint32       `json:"block_number"`
+	batchProcessedChan chan uint32  `json:"-"`
+	mutex              sync.RWMutex `json:"-"`
+}
+
+// LastProcessedBlock returns the block number of the latest processed batch.
+// It is safe to call from any goroutine.
+func (o *Operator) LastProcessedBlock() uint32 {
+	o.lastProcessedBatch.mutex.RLock()
+	defer o.lastProcessedBatch.mutex.RUnlock()
+	return o.lastProcessedBatch.BlockNumber
 }
 
 func (o *Operator) LoadLastProcessedBatch() error {
@@ -193,15 +287,18 @@ func (o *Operator) LoadLastProcessedBatch() error {
 }
 
 func (o *Operator) UpdateLastProcessBatch(blockNumber uint32) error {
+	o.lastProcessedBatch.mutex.Lock()
 	// we want to store the latest block number
 	if blockNumber < o.lastProcessedBatch.BlockNumber {
+		o.lastProcessedBatch.mutex.Unlock()
 		return nil
 	}
 
 	o.lastProcessedBatch.BlockNumber = blockNumber
 
 	// write to a file so it can be recovered in case of operator outage
-	json, err := json.Marshal(o.lastProcessedBatch)
+	json, err := json.Marshal(&o.lastProcessedBatch)
+	o.lastProcessedBatch.mutex.Unlock()
 
 	if err != nil {
 		return fmt.Errorf("failed to marshal batch: %v", err)
@@ -228,6 +325,16 @@ func (o *Operator) Start(ctx context.Context) error {
 		log.Fatal("Could not subscribe to new tasks")
 	}
 
+	subBatchVerified, err := o.SubscribeToBatchVerified()
+	if err != nil {
+		log.Fatal("Could not subscribe to verified batches")
+	}
+
+	subVerifierStatus, err := o.SubscribeToVerifierStatusChanges()
+	if err != nil {
+		log.Fatal("Could not subscribe to verifier status changes")
+	}
+
 	var metricsErrChan <-chan error
 	if o.Config.Operator.EnableMetrics {
 		metricsErrChan = o.metrics.Start(ctx, o.metricsReg)
@@ -235,6 +342,23 @@ func (o *Operator) Start(ctx context.Context) error {
 		metricsErrChan = make(chan error, 1)
 	}
 
+	if o.Config.Operator.StatusIpPortAddress != "" {
+		go func() {
+			err := o.ServeStatus()
+			if err != nil {
+				o.Logger.Error("Status server failed", "err", err)
+			}
+		}()
+	}
+
+	if pending := o.responseOutbox.Len(); pending > 0 {
+		o.Logger.Infof("Resuming %d pending signed task responses", pending)
+	}
+	o.metrics.SetOperatorResponseOutboxDepth(o.responseOutbox.Len())
+	go o.ProcessResponseOutbox()
+	go o.aggRpcClient.ProcessHealthChecks()
+	go o.ProcessDisabledVerifiersReconciliation()
+
 	go o.ProcessMissedBatchesWhileOffline()
 
 	for {
@@ -256,6 +380,26 @@ func (o *Operator) Start(ctx context.Context) error {
 			if err != nil {
 				o.Logger.Fatal("Could not subscribe to new tasks V3")
 			}
+		case err := <-subBatchVerified:
+			o.Logger.Infof("Error in websocket subscription", "err", err)
+			subBatchVerified, err = o.SubscribeToBatchVerified()
+			if err != nil {
+				o.Logger.Fatal("Could not subscribe to verified batches")
+			}
+		case err := <-subVerifierStatus:
+			o.Logger.Infof("Error in websocket subscription", "err", err)
+			subVerifierStatus, err = o.SubscribeToVerifierStatusChanges()
+			if err != nil {
+				o.Logger.Fatal("Could not subscribe to verifier status changes")
+			}
+		case verifierDisabled := <-o.VerifierDisabledChan:
+			o.handleVerifierStatusChange(verifierDisabled.VerifierIdx, true)
+		case verifierEnabled := <-o.VerifierEnabledChan:
+			o.handleVerifierStatusChange(verifierEnabled.VerifierIdx, false)
+		case batchVerified := <-o.BatchVerifiedChan:
+			// The batch was responded, so there is no need to keep sending our response
+			batchIdentifier := append(batchVerified.BatchMerkleRoot[:], batchVerified.SenderAddress[:]...)
+			o.removeFromResponseOutbox(*(*[32]byte)(crypto.Keccak256(batchIdentifier)))
 		case newBatchLogV2 := <-o.NewTaskCreatedChanV2:
 			go o.handleNewBatchLogV2(newBatchLogV2)
 		case newBatchLogV3 := <-o.NewTaskCreatedChanV3:
@@ -276,7 +420,8 @@ func (o *Operator) Start(ctx context.Context) error {
 func (o *Operator) ProcessMissedBatchesWhileOffline() {
 	// this is the default value
 	// and it means there was no file so no batches have been verified
-	if o.lastProcessedBatch.BlockNumber == 0 {
+	lastProcessedBlock := o.LastProcessedBlock()
+	if lastProcessedBlock == 0 {
 		o.Logger.Info("Not continuing with missed batch processing, as operator hasn't verified anything yet...")
 		return
 	}
@@ -285,10 +430,10 @@ func (o *Operator) ProcessMissedBatchesWhileOffline() {
 
 	// this check is necessary for overflows as go does not do saturating arithmetic
 	var fromBlock uint64
-	if o.lastProcessedBatch.BlockNumber < UnverifiedBatchOffset {
+	if lastProcessedBlock < UnverifiedBatchOffset {
 		fromBlock = 0
 	} else {
-		fromBlock = uint64(o.lastProcessedBatch.BlockNumber - UnverifiedBatchOffset)
+		fromBlock = uint64(lastProcessedBlock - UnverifiedBatchOffset)
 	}
 
 	logs, err := o.avsReader.GetNotRespondedTasksFrom(fromBlock)
@@ -323,7 +468,8 @@ func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlign
 	defer func() { o.afterHandlingBatchV2(newBatchLog, err == nil) }()
 
 	o.Logger.Info("Received new batch log V2")
-	err = o.ProcessNewBatchLogV2(newBatchLog)
+	trace := newTaskTrace(newBatchLog.BatchMerkleRoot)
+	err = o.ProcessNewBatchLogV2(contextWithTaskTrace(context.Background(), trace), newBatchLog)
 	if err != nil {
 		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
 		return
@@ -331,7 +477,9 @@ func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlign
 
 	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
 	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+	endSign := trace.startSpan("sign")
 	responseSignature := o.SignTaskResponse(batchIdentifierHash)
+	endSign(nil)
 	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
 
 	signedTaskResponse := types.SignedTaskResponse{
@@ -340,6 +488,7 @@ func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlign
 		SenderAddress:       newBatchLog.SenderAddress,
 		BlsSignature:        *responseSignature,
 		OperatorId:          o.OperatorId,
+		TraceContext:        trace.finish(),
 	}
 	o.Logger.Infof("Signed Task Response to send: BatchIdentifierHash=%s, BatchMerkleRoot=%s, SenderAddress=%s",
 		hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
@@ -347,16 +496,19 @@ func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlign
 		hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
 	)
 
-	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
+	o.enqueueSignedTaskResponse(signedTaskResponse)
 }
-func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
+
+// ProcessNewBatchLogV2 downloads and verifies the batch, recording its stages on the trace of ctx, if any
+func (o *Operator) ProcessNewBatchLogV2(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
+	trace := taskTraceFromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, BatchDownloadTimeout)
 	defer cancel()
 
 	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
@@ -365,17 +517,25 @@ func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlig
 		return err
 	}
 
+	endPolicyCheck := trace.startSpan("verification_policy")
+	err = o.checkVerificationPolicy(verificationDataBatch)
+	endPolicyCheck(err)
+	if err != nil {
+		return err
+	}
+
 	verificationDataBatchLen := len(verificationDataBatch)
 	results := make(chan bool, verificationDataBatchLen)
 	var wg sync.WaitGroup
 	wg.Add(verificationDataBatchLen)
 
-	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
+	disabledVerifiersBitmap, err := o.getDisabledVerifiers()
 	if err != nil {
 		o.Logger.Errorf("Could not check verifiers status: %s", err)
 		results <- false
 		return err
 	}
+	endVerify := trace.startSpan("verify")
 
 	for _, verificationData := range verificationDataBatch {
 		go func(data VerificationData) {
@@ -392,9 +552,12 @@ func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlig
 
 	for result := range results {
 		if !result {
-			return fmt.Errorf("invalid proof")
+			err = fmt.Errorf("invalid proof")
+			endVerify(err)
+			return err
 		}
 	}
+	endVerify(nil)
 
 	return nil
 }
@@ -404,7 +567,8 @@ func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlign
 	var err error
 	defer func() { o.afterHandlingBatchV3(newBatchLog, err == nil) }()
 	o.Logger.Infof("Received new batch log V3")
-	err = o.ProcessNewBatchLogV3(newBatchLog)
+	trace := newTaskTrace(newBatchLog.BatchMerkleRoot)
+	err = o.ProcessNewBatchLogV3(contextWithTaskTrace(context.Background(), trace), newBatchLog)
 	if err != nil {
 		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
 		return
@@ -412,7 +576,9 @@ func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlign
 
 	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
 	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
+	endSign := trace.startSpan("sign")
 	responseSignature := o.SignTaskResponse(batchIdentifierHash)
+	endSign(nil)
 	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
 
 	signedTaskResponse := types.SignedTaskResponse{
@@ -421,6 +587,7 @@ func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlign
 		SenderAddress:       newBatchLog.SenderAddress,
 		BlsSignature:        *responseSignature,
 		OperatorId:          o.OperatorId,
+		TraceContext:        trace.finish(),
 	}
 	o.Logger.Infof("Signed Task Response to send: BatchIdentifierHash=%s, BatchMerkleRoot=%s, SenderAddress=%s",
 		hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
@@ -428,16 +595,19 @@ func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlign
 		hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
 	)
 
-	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
+	o.enqueueSignedTaskResponse(signedTaskResponse)
 }
-func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
+
+// ProcessNewBatchLogV3 downloads and verifies the batch, recording its stages on the trace of ctx, if any
+func (o *Operator) ProcessNewBatchLogV3(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
+	trace := taskTraceFromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, BatchDownloadTimeout)
 	defer cancel()
 
 	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
@@ -446,16 +616,24 @@ func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlig
 		return err
 	}
 
+	endPolicyCheck := trace.startSpan("verification_policy")
+	err = o.checkVerificationPolicy(verificationDataBatch)
+	endPolicyCheck(err)
+	if err != nil {
+		return err
+	}
+
 	verificationDataBatchLen := len(verificationDataBatch)
 	results := make(chan bool, verificationDataBatchLen)
 	var wg sync.WaitGroup
 	wg.Add(verificationDataBatchLen)
-	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
+	disabledVerifiersBitmap, err := o.getDisabledVerifiers()
 	if err != nil {
 		o.Logger.Errorf("Could not check verifiers status: %s", err)
 		results <- false
 		return err
 	}
+	endVerify := trace.startSpan("verify")
 	for _, verificationData := range verificationDataBatch {
 		go func(data VerificationData) {
 			defer wg.Done()
@@ -471,22 +649,31 @@ func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlig
 
 	for result := range results {
 		if !result {
-			return fmt.Errorf("invalid proof")
+			err = fmt.Errorf("invalid proof")
+			endVerify(err)
+			return err
 		}
 	}
+	endVerify(nil)
 
 	return nil
 }
 
 func (o *Operator) afterHandlingBatchV2(log *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, succeeded bool) {
 	if succeeded {
+		o.verifiedBatches.Add(1)
 		o.lastProcessedBatch.batchProcessedChan <- uint32(log.Raw.BlockNumber)
+	} else {
+		o.rejectedBatches.Add(1)
 	}
 }
 
 func (o *Operator) afterHandlingBatchV3(log *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, succeeded bool) {
 	if succeeded {
+		o.verifiedBatches.Add(1)
 		o.lastProcessedBatch.batchProcessedChan <- uint32(log.Raw.BlockNumber)
+	} else {
+		o.rejectedBatches.Add(1)
 	}
 }
 
@@ -497,49 +684,99 @@ func (o *Operator) verify(verificationData VerificationData, disabledVerifiersBi
 		results <- false
 		return
 	}
+
+	release := o.verificationPolicy.Acquire(verificationData.ProvingSystemId)
+	timeout := o.verificationPolicy.Timeout(verificationData.ProvingSystemId)
+	if timeout == 0 {
+		defer release()
+		results <- o.verifyProof(verificationData)
+		return
+	}
+
+	// Verifiers can't be interrupted, so the resource slot is released once
+	// the verification finishes even if it already timed out
+	verificationResult := make(chan bool, 1)
+	go func() {
+		defer release()
+		verificationResult <- o.verifyProof(verificationData)
+	}()
+
+	select {
+	case result := <-verificationResult:
+		results <- result
+	case <-time.After(timeout):
+		provingSystem, _ := common.ProvingSystemIdToString(verificationData.ProvingSystemId)
+		o.Logger.Warnf("%s proof verification timed out after %v. Returning false", provingSystem, timeout)
+		o.metrics.IncOperatorVerificationTimeouts(provingSystem)
+		results <- false
+	}
+}
+
+func (o *Operator) verifyProof(verificationData VerificationData) bool {
 	switch verificationData.ProvingSystemId {
 	case common.GnarkPlonkBls12_381:
 		verificationResult := o.verifyPlonkProofBLS12_381(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
 		o.Logger.Infof("PLONK BLS12-381 proof verification result: %t", verificationResult)
-
-		results <- verificationResult
+		return verificationResult
 
 	case common.GnarkPlonkBn254:
 		verificationResult := o.verifyPlonkProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
 		o.Logger.Infof("PLONK BN254 proof verification result: %t", verificationResult)
-
-		results <- verificationResult
+		return verificationResult
 
 	case common.Groth16Bn254:
 		verificationResult := o.verifyGroth16ProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
 		o.Logger.Infof("GROTH16 BN254 proof verification result: %t", verificationResult)
-
-		results <- verificationResult
+		return verificationResult
 
 	case common.SP1:
 		verificationResult, err := sp1.VerifySp1Proof(verificationData.Proof, verificationData.VmProgramCode)
-		o.handleVerificationResult(results, verificationResult, err, "SP1 proof verification")
+		return o.handleVerificationResult(verificationResult, err, "SP1 proof verification")
 
 	case common.Risc0:
 		verificationResult, err := risc_zero.VerifyRiscZeroReceipt(verificationData.Proof,
 			verificationData.VmProgramCode, verificationData.PubInput)
-		o.handleVerificationResult(results, verificationResult, err, "RiscZero proof verification")
+		return o.handleVerificationResult(verificationResult, err, "RiscZero proof verification")
 
-		o.Logger.Infof("Risc0 proof verification result: %t", verificationResult)
-		results <- verificationResult
 	default:
 		o.Logger.Error("Unrecognized proving system ID")
-		results <- false
+		return false
 	}
 }
 
-func (o *Operator) handleVerificationResult(results chan bool, isVerified bool, err error, name string) {
+func (o *Operator) handleVerificationResult(isVerified bool, err error, name string) bool {
 	if err != nil {
 		o.Logger.Errorf("%v failed %v", name, err)
-		results <- false
-	} else {
-		o.Logger.Infof("%v result: %t", name, isVerified)
-		results <- isVerified
+		return false
+	}
+	o.Logger.Infof("%v result: %t", name, isVerified)
+	return isVerified
+}
+
+// checkVerificationPolicy refuses batches with proofs of a proving system disabled in the
+// operator local policy. This is the local kill-switch, so it is reported as an alert.
+func (o *Operator) checkVerificationPolicy(verificationDataBatch []VerificationData) error {
+	for _, verificationData := range verificationDataBatch {
+		if o.verificationPolicy.IsLocallyDisabled(verificationData.ProvingSystemId) {
+			provingSystem, _ := common.ProvingSystemIdToString(verificationData.ProvingSystemId)
+			o.Logger.Error("[ALERT] Batch contains a proving system disabled in the local verification policy, it won't be signed",
+				"provingSystem", provingSystem)
+			o.metrics.IncOperatorLocallyRejectedBatches(provingSystem)
+			return fmt.Errorf("proving system %s is disabled in the local verification policy", provingSystem)
+		}
+	}
+	return nil
+}
+
+// reportVerificationPolicy exports the effective verification policy as metrics
+func (o *Operator) reportVerificationPolicy() {
+	for _, class := range []VerificationClass{LightVerification, HeavyVerification} {
+		o.metrics.SetOperatorVerificationsConcurrency(class.String(), o.verificationPolicy.concurrency[class])
+	}
+	for _, provingSystemId := range []common.ProvingSystemId{common.GnarkPlonkBls12_381, common.GnarkPlonkBn254, common.Groth16Bn254, common.SP1, common.Risc0} {
+		provingSystem, _ := common.ProvingSystemIdToString(provingSystemId)
+		o.metrics.SetOperatorVerificationTimeout(provingSystem, o.verificationPolicy.Timeout(provingSystemId))
+		o.metrics.SetOperatorLocallyDisabledVerifier(provingSystem, o.verificationPolicy.IsLocallyDisabled(provingSystemId))
 	}
 }
 
@@ -624,65 +861,3 @@ func (o *Operator) SignTaskResponse(batchIdentifierHash [32]byte) *bls.Signature
 	responseSignature := *o.Config.BlsConfig.KeyPair.SignMessage(batchIdentifierHash)
 	return &responseSignature
 }
-
-func (o *Operator) SendTelemetryData(ctx *cli.Context) error {
-	// hash version
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write([]byte(ctx.App.Version))
-
-	// get hash
-	version := hash.Sum(nil)
-
-	// sign version
-	signature, err := crypto.Sign(version[:], o.Config.EcdsaConfig.PrivateKey)
-	if err != nil {
-		return err
-	}
-	ethRpcUrl, err := BaseUrlOnly(o.Config.BaseConfig.EthRpcUrl)
-	if err != nil {
-		return err
-	}
-	ethRpcUrlFallback, err := BaseUrlOnly(o.Config.BaseConfig.EthRpcUrlFallback)
-	if err != nil {
-		return err
-	}
-	ethWsUrl, err := BaseUrlOnly(o.Config.BaseConfig.EthWsUrl)
-	if err != nil {
-		return err
-	}
-	ethWsUrlFallback, err := BaseUrlOnly(o.Config.BaseConfig.EthWsUrlFallback)
-	if err != nil {
-		return err
-	}
-
-	body := map[string]interface{}{
-		"version":              ctx.App.Version,
-		"signature":            signature,
-		"eth_rpc_url":          ethRpcUrl,
-		"eth_rpc_url_fallback": ethRpcUrlFallback,
-		"eth_ws_url":           ethWsUrl,
-		"eth_ws_url_fallback":  ethWsUrlFallback,
-	}
-
-	bodyBuffer := new(bytes.Buffer)
-
-	bodyReader := json.NewEncoder(bodyBuffer)
-	err = bodyReader.Encode(body)
-	if err != nil {
-		return err
-	}
-
-	// send version to operator tracker server
-	endpoint := o.Config.Operator.OperatorTrackerIpPortAddress + "/versions"
-	o.Logger.Info("Sending version to operator tracker server: ", "endpoint", endpoint)
-
-	res, err := http.Post(endpoint, "application/json", bodyBuffer)
-	if err != nil {
-		// Dont prevent operator from starting if operator tracker server is down
-		o.Logger.Warn("Error sending version to metrics server: ", "err", err)
-	} else if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusNoContent {
-		o.Logger.Warn("Error sending version to operator tracker server: ", "status_code", res.StatusCode)
-	}
-
-	return nil
-}
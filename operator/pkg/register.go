@@ -3,9 +3,9 @@ package operator
 import (
 	"context"
 
-	"github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 // RegisterOperator operator registers the operator with the given public key for the given quorum IDs.
@@ -25,7 +25,7 @@ func RegisterOperator(
 
 	socket := "Not Needed"
 
-	quorumNumbers := types.QuorumNums{0}
+	quorumNumbers := utils.BytesToQuorumNumbers(configuration.Operator.QuorumNumbers)
 
 	_, err = writer.RegisterOperator(ctx, configuration.EcdsaConfig.PrivateKey,
 		configuration.BlsConfig.KeyPair,
@@ -0,0 +1,315 @@
+package operator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+const (
+	ResponseOutboxEntryTTL       = 30 * time.Minute
+	ResponseOutboxInitialBackoff = 2 * time.Second
+	ResponseOutboxMaxBackoff     = 1 * time.Minute
+	ResponseOutboxPollInterval   = 1 * time.Second
+	ResponseOutboxDefaultFile    = "operator.response_outbox.json"
+)
+
+// ResponseOutboxEntry is a signed task response waiting to be accepted by the aggregator
+type ResponseOutboxEntry struct {
+	SignedTaskResponse types.SignedTaskResponse `json:"signed_task_response"`
+	CreatedAt          time.Time                `json:"created_at"`
+	Attempts           int                      `json:"attempts"`
+	NextAttemptAt      time.Time                `json:"next_attempt_at"`
+	inFlight           bool
+}
+
+// ResponseOutbox is a persistent queue of signed task responses, keyed by batch identifier hash.
+// Every change is written to filePath, so pending responses survive operator restarts.
+type ResponseOutbox struct {
+	filePath string
+	entries  map[[32]byte]*ResponseOutboxEntry
+	mutex    sync.Mutex
+}
+
+// NewResponseOutbox creates an outbox backed by filePath, loading the entries stored in it if it exists
+func NewResponseOutbox(filePath string) (*ResponseOutbox, error) {
+	outbox := &ResponseOutbox{
+		filePath: filePath,
+		entries:  make(map[[32]byte]*ResponseOutboxEntry),
+	}
+
+	file, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// Check that the file can be created later
+		if _, err := os.Stat(filepath.Dir(filePath)); err != nil {
+			return nil, err
+		}
+		return outbox, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*ResponseOutboxEntry
+	if err := json.Unmarshal(file, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response outbox: %v", err)
+	}
+	for _, entry := range entries {
+		outbox.entries[entry.SignedTaskResponse.BatchIdentifierHash] = entry
+	}
+
+	return outbox, nil
+}
+
+// Add stores a signed task response to be sent as soon as possible.
+// If there is already a response for the batch, it is replaced.
+func (q *ResponseOutbox) Add(signedTaskResponse types.SignedTaskResponse, now time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.entries[signedTaskResponse.BatchIdentifierHash] = &ResponseOutboxEntry{
+		SignedTaskResponse: signedTaskResponse,
+		CreatedAt:          now,
+		NextAttemptAt:      now,
+	}
+	return q.persist()
+}
+
+// Remove drops the response of the given batch. Returns true if there was one.
+func (q *ResponseOutbox) Remove(batchIdentifierHash [32]byte) (bool, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, ok := q.entries[batchIdentifierHash]; !ok {
+		return false, nil
+	}
+	delete(q.entries, batchIdentifierHash)
+	return true, q.persist()
+}
+
+// RemoveSent drops the response of the batch only if it is still the given entry.
+// A response added for the same batch while the entry was being sent is kept.
+func (q *ResponseOutbox) RemoveSent(sent ResponseOutboxEntry) (bool, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	batchIdentifierHash := sent.SignedTaskResponse.BatchIdentifierHash
+	entry, ok := q.entries[batchIdentifierHash]
+	if !ok || !entry.CreatedAt.Equal(sent.CreatedAt) || entry.Attempts != sent.Attempts {
+		return false, nil
+	}
+	delete(q.entries, batchIdentifierHash)
+	return true, q.persist()
+}
+
+// TakeDue returns the entries whose next attempt is due and marks them as in flight,
+// so they are not taken again until Retry is called for them
+func (q *ResponseOutbox) TakeDue(now time.Time) []ResponseOutboxEntry {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var due []ResponseOutboxEntry
+	for _, entry := range q.entries {
+		if entry.inFlight || entry.NextAttemptAt.After(now) {
+			continue
+		}
+		entry.inFlight = true
+		due = append(due, *entry)
+	}
+	return due
+}
+
+// Retry schedules a new attempt for a failed entry with exponential backoff
+func (q *ResponseOutbox) Retry(batchIdentifierHash [32]byte, now time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	entry, ok := q.entries[batchIdentifierHash]
+	if !ok {
+		return nil
+	}
+	entry.inFlight = false
+	entry.Attempts++
+	entry.NextAttemptAt = now.Add(responseOutboxBackoff(entry.Attempts))
+	return q.persist()
+}
+
+// RemoveExpired drops the entries older than ttl and returns them
+func (q *ResponseOutbox) RemoveExpired(now time.Time, ttl time.Duration) ([]ResponseOutboxEntry, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var expired []ResponseOutboxEntry
+	for batchIdentifierHash, entry := range q.entries {
+		if entry.inFlight || now.Sub(entry.CreatedAt) < ttl {
+			continue
+		}
+		expired = append(expired, *entry)
+		delete(q.entries, batchIdentifierHash)
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	return expired, q.persist()
+}
+
+func (q *ResponseOutbox) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.entries)
+}
+
+// persist writes all the entries to the outbox file. Must be called with the mutex held.
+func (q *ResponseOutbox) persist() error {
+	entries := make([]*ResponseOutboxEntry, 0, len(q.entries))
+	for _, entry := range q.entries {
+		entries = append(entries, entry)
+	}
+
+	file, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response outbox: %v", err)
+	}
+
+	if err := utils.WriteFileAtomic(q.filePath, file, 0644); err != nil {
+		return fmt.Errorf("failed to write response outbox: %v", err)
+	}
+	return nil
+}
+
+func responseOutboxBackoff(attempts int) time.Duration {
+	backoff := ResponseOutboxInitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= ResponseOutboxMaxBackoff {
+			return ResponseOutboxMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// enqueueSignedTaskResponse stores the response in the outbox and wakes up the outbox
+// worker, so the first attempt is sent right away
+func (o *Operator) enqueueSignedTaskResponse(signedTaskResponse types.SignedTaskResponse) {
+	err := o.responseOutbox.Add(signedTaskResponse, time.Now())
+	if err != nil {
+		// The response is still kept in memory, it just won't survive a restart
+		o.Logger.Errorf("Could not persist signed task response: %v", err)
+	}
+	o.metrics.SetOperatorResponseOutboxDepth(o.responseOutbox.Len())
+
+	select {
+	case o.responseOutboxWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessResponseOutbox is a long-lived goroutine that sends the due responses of the outbox
+// to the aggregator, retrying failed ones with backoff until they are accepted, the batch is
+// responded on-chain or they expire
+func (o *Operator) ProcessResponseOutbox() {
+	ticker := time.NewTicker(ResponseOutboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-o.responseOutboxWakeup:
+		}
+
+		now := time.Now()
+		expired, err := o.responseOutbox.RemoveExpired(now, ResponseOutboxEntryTTL)
+		if err != nil {
+			o.Logger.Errorf("Could not persist signed task response: %v", err)
+		}
+		for _, entry := range expired {
+			o.Logger.Warn("Signed task response expired before being accepted by the aggregator, dropping it",
+				"batchIdentifierHash", "0x"+hex.EncodeToString(entry.SignedTaskResponse.BatchIdentifierHash[:]),
+				"attempts", entry.Attempts)
+		}
+
+		for _, entry := range o.responseOutbox.TakeDue(now) {
+			go o.sendOutboxEntry(entry)
+		}
+		o.metrics.SetOperatorResponseOutboxDepth(o.responseOutbox.Len())
+	}
+}
+
+func (o *Operator) sendOutboxEntry(entry ResponseOutboxEntry) {
+	batchIdentifierHash := entry.SignedTaskResponse.BatchIdentifierHash
+
+	// On retries, check the batch still needs the response. The BatchVerified event
+	// may have been missed while the operator was offline
+	if entry.Attempts > 0 {
+		responded, err := o.avsReader.IsBatchResponded(batchIdentifierHash)
+		if err != nil {
+			o.Logger.Warn("Could not check if batch was responded", "err", err)
+		} else if responded {
+			o.removeFromResponseOutbox(batchIdentifierHash)
+			return
+		}
+	}
+
+	err := o.aggRpcClient.SendSignedTaskResponseToAggregator(&entry.SignedTaskResponse)
+	if err == nil {
+		o.removeSentFromResponseOutbox(entry)
+		return
+	}
+	if errors.Is(err, ErrResponsePending) {
+		// Kept until the aggregator accepts it for a task it knows, as its buffer does not survive a restart
+		o.Logger.Info("Signed task response pending in the aggregator, sending it again later",
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+		if err := o.responseOutbox.Retry(batchIdentifierHash, time.Now()); err != nil {
+			o.Logger.Errorf("Could not persist signed task response: %v", err)
+		}
+		return
+	}
+	if isFinalRejection(err) {
+		o.Logger.Error("Signed task response rejected by aggregator, dropping it",
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+			"err", err)
+		o.removeSentFromResponseOutbox(entry)
+		return
+	}
+
+	o.Logger.Warn("Could not send signed task response to aggregator, retrying later",
+		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
+		"attempts", entry.Attempts+1,
+		"err", err)
+	if err := o.responseOutbox.Retry(batchIdentifierHash, time.Now()); err != nil {
+		o.Logger.Errorf("Could not persist signed task response: %v", err)
+	}
+}
+
+func (o *Operator) removeFromResponseOutbox(batchIdentifierHash [32]byte) {
+	removed, err := o.responseOutbox.Remove(batchIdentifierHash)
+	if err != nil {
+		o.Logger.Errorf("Could not persist signed task response outbox: %v", err)
+	}
+	if removed {
+		o.Logger.Debug("Signed task response removed from outbox",
+			"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+	}
+	o.metrics.SetOperatorResponseOutboxDepth(o.responseOutbox.Len())
+}
+
+// removeSentFromResponseOutbox drops a sent entry, keeping any newer response stored for the same batch
+func (o *Operator) removeSentFromResponseOutbox(entry ResponseOutboxEntry) {
+	removed, err := o.responseOutbox.RemoveSent(entry)
+	if err != nil {
+		o.Logger.Errorf("Could not persist signed task response outbox: %v", err)
+	}
+	if removed {
+		o.Logger.Debug("Signed task response removed from outbox",
+			"batchIdentifierHash", "0x"+hex.EncodeToString(entry.SignedTaskResponse.BatchIdentifierHash[:]))
+	}
+	o.metrics.SetOperatorResponseOutboxDepth(o.responseOutbox.Len())
+}
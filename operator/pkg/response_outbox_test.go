@@ -0,0 +1,233 @@
+package operator
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/core/chainio/fakes"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+func newTestResponse(id byte) types.SignedTaskResponse {
+	return types.SignedTaskResponse{
+		BatchIdentifierHash: [32]byte{id},
+		BatchMerkleRoot:     [32]byte{id},
+	}
+}
+
+func TestResponseOutboxPersistence(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), ResponseOutboxDefaultFile)
+	now := time.Now()
+
+	outbox, err := NewResponseOutbox(filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Add(newTestResponse(1), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Add(newTestResponse(2), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Retry([32]byte{2}, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := outbox.Remove([32]byte{1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reloaded, err := NewResponseOutbox(filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if reloaded.Len() != 1 {
+		t.Fatalf("Expected 1 entry after reload, got %d", reloaded.Len())
+	}
+	entry := reloaded.entries[[32]byte{2}]
+	if entry == nil || entry.Attempts != 1 {
+		t.Fatalf("Expected the retried entry to be reloaded with 1 attempt, got %+v", entry)
+	}
+}
+
+func TestResponseOutboxTakeDue(t *testing.T) {
+	outbox, err := NewResponseOutbox(filepath.Join(t.TempDir(), ResponseOutboxDefaultFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Now()
+	if err := outbox.Add(newTestResponse(1), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if due := outbox.TakeDue(now); len(due) != 1 {
+		t.Fatalf("Expected 1 due entry, got %d", len(due))
+	}
+	// In flight entries are not taken again
+	if due := outbox.TakeDue(now); len(due) != 0 {
+		t.Fatalf("Expected no due entries while in flight, got %d", len(due))
+	}
+
+	if err := outbox.Retry([32]byte{1}, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if due := outbox.TakeDue(now); len(due) != 0 {
+		t.Fatalf("Expected no due entries before the backoff elapses, got %d", len(due))
+	}
+	if due := outbox.TakeDue(now.Add(ResponseOutboxInitialBackoff)); len(due) != 1 {
+		t.Fatalf("Expected 1 due entry after the backoff, got %d", len(due))
+	}
+}
+
+func TestResponseOutboxRemoveExpired(t *testing.T) {
+	outbox, err := NewResponseOutbox(filepath.Join(t.TempDir(), ResponseOutboxDefaultFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Now()
+	if err := outbox.Add(newTestResponse(1), now.Add(-2*ResponseOutboxEntryTTL)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Add(newTestResponse(2), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expired, err := outbox.RemoveExpired(now, ResponseOutboxEntryTTL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(expired) != 1 || expired[0].SignedTaskResponse.BatchIdentifierHash != [32]byte{1} {
+		t.Fatalf("Expected only the old entry to expire, got %+v", expired)
+	}
+	if outbox.Len() != 1 {
+		t.Fatalf("Expected 1 remaining entry, got %d", outbox.Len())
+	}
+}
+
+func TestResponseOutboxRemoveSentKeepsNewerEntry(t *testing.T) {
+	outbox, err := NewResponseOutbox(filepath.Join(t.TempDir(), ResponseOutboxDefaultFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	now := time.Now()
+	if err := outbox.Add(newTestResponse(1), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	due := outbox.TakeDue(now)
+	if len(due) != 1 {
+		t.Fatalf("Expected 1 due entry, got %d", len(due))
+	}
+
+	// A new response for the same batch is added while the old one is being sent
+	if err := outbox.Add(newTestResponse(1), now.Add(time.Second)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	removed, err := outbox.RemoveSent(due[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if removed || outbox.Len() != 1 {
+		t.Fatalf("Expected the newer entry to be kept, removed %v, got %d entries", removed, outbox.Len())
+	}
+
+	due = outbox.TakeDue(now.Add(time.Second))
+	if len(due) != 1 {
+		t.Fatalf("Expected the newer entry to be due, got %d", len(due))
+	}
+	removed, err = outbox.RemoveSent(due[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !removed || outbox.Len() != 0 {
+		t.Fatalf("Expected the sent entry to be removed, removed %v, got %d entries", removed, outbox.Len())
+	}
+}
+
+func TestResponseOutboxBackoff(t *testing.T) {
+	if responseOutboxBackoff(1) != ResponseOutboxInitialBackoff {
+		t.Errorf("Expected first backoff to be %v, got %v", ResponseOutboxInitialBackoff, responseOutboxBackoff(1))
+	}
+	if responseOutboxBackoff(2) != 2*ResponseOutboxInitialBackoff {
+		t.Errorf("Expected second backoff to be %v, got %v", 2*ResponseOutboxInitialBackoff, responseOutboxBackoff(2))
+	}
+	if responseOutboxBackoff(100) != ResponseOutboxMaxBackoff {
+		t.Errorf("Expected backoff to be capped at %v, got %v", ResponseOutboxMaxBackoff, responseOutboxBackoff(100))
+	}
+}
+
+func TestSendOutboxEntryDropsRespondedBatch(t *testing.T) {
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	now := time.Now()
+	outbox, err := NewResponseOutbox(filepath.Join(t.TempDir(), ResponseOutboxDefaultFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Add(newTestResponse(1), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reader := fakes.NewFakeAvsReader()
+	reader.SetBatchResponded([32]byte{1}, true)
+	// Without an aggregator client, sending the response would panic
+	o := &Operator{
+		avsReader:      reader,
+		responseOutbox: outbox,
+		Logger:         logger,
+		metrics:        metrics.NewMetrics("", prometheus.NewRegistry(), logger),
+	}
+
+	o.sendOutboxEntry(ResponseOutboxEntry{SignedTaskResponse: newTestResponse(1), CreatedAt: now, Attempts: 1})
+	if outbox.Len() != 0 {
+		t.Fatalf("Expected the response of a responded batch to be dropped, got %d entries", outbox.Len())
+	}
+}
+
+func TestSendOutboxEntryKeepsPendingResponse(t *testing.T) {
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	filePath := filepath.Join(t.TempDir(), ResponseOutboxDefaultFile)
+	now := time.Now()
+	outbox, err := NewResponseOutbox(filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := outbox.Add(newTestResponse(1), now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, pendingServer := startTestAggregator(t, types.SignedTaskResponseAcceptedPending)
+	o := &Operator{
+		avsReader:      fakes.NewFakeAvsReader(),
+		aggRpcClient:   newTestRpcClient(t, []string{pendingServer.Listener.Addr().String()}, false),
+		responseOutbox: outbox,
+		Logger:         logger,
+		metrics:        metrics.NewMetrics("", prometheus.NewRegistry(), logger),
+	}
+
+	// The aggregator only holds the response in memory, so it stays in the outbox
+	o.sendOutboxEntry(outbox.TakeDue(now)[0])
+	reloaded, err := NewResponseOutbox(filePath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entry := reloaded.entries[[32]byte{1}]
+	if entry == nil || entry.Attempts != 1 || !entry.NextAttemptAt.After(now) {
+		t.Fatalf("Expected the pending response to be rescheduled, got %+v", entry)
+	}
+
+	// Once the aggregator accepts it for a task it knows, it is dropped
+	_, acceptingServer := startTestAggregator(t, types.SignedTaskResponseAccepted)
+	o.aggRpcClient = newTestRpcClient(t, []string{acceptingServer.Listener.Addr().String()}, false)
+	o.sendOutboxEntry(outbox.TakeDue(now.Add(time.Hour))[0])
+	if outbox.Len() != 0 {
+		t.Fatalf("Expected the accepted response to be dropped, got %d entries", outbox.Len())
+	}
+}
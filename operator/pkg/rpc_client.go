@@ -1,64 +1,369 @@
 package operator
 
 import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"net/rpc"
+	"sync"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/metrics"
 )
 
-// AggregatorRpcClient is the client to communicate with the aggregator via RPC
+const (
+	// The aggregator may hold a response while it waits for the task, so the default timeout is generous
+	DefaultAggregatorRpcTimeout    = 1 * time.Minute
+	AggregatorHealthCheckInterval  = 10 * time.Second
+	AggregatorHealthCheckTimeout   = 5 * time.Second
+	processSignedTaskResponseV2Rpc = "Aggregator.ProcessOperatorSignedTaskResponseV2"
+	processSignedTaskResponseV3Rpc = "Aggregator.ProcessOperatorSignedTaskResponseV3"
+)
+
+// AggregatorRpcSecurity configures how the client connects to the aggregators.
+// The zero value connects in plain TCP and sends unsigned requests.
+type AggregatorRpcSecurity struct {
+	// Connect over TLS if set
+	TLSConfig *tls.Config
+	// Sign the requests with this key, the operator ECDSA key, if set
+	SigningKey *ecdsa.PrivateKey
+}
+
+// ErrResponseRejected is wrapped by every ResponseRejectedError
+var ErrResponseRejected = errors.New("aggregator could not process the signed task response")
+
+// ResponseRejectedError is returned when the aggregator replies with an error code
+type ResponseRejectedError struct {
+	Address string
+	Reply   uint8
+}
+
+func (e *ResponseRejectedError) Error() string {
+	return fmt.Sprintf("%v: aggregator %s replied %d (%s)", ErrResponseRejected, e.Address, e.Reply, types.SignedTaskResponseReplyString(e.Reply))
+}
+
+func (e *ResponseRejectedError) Unwrap() error {
+	return ErrResponseRejected
+}
+
+// Retryable returns false if sending the same response again can't succeed
+func (e *ResponseRejectedError) Retryable() bool {
+	return types.SignedTaskResponseReplyIsRetryable(e.Reply)
+}
+
+// isFinalRejection returns true if the error is a rejection that no aggregator would accept on a retry
+func isFinalRejection(err error) bool {
+	var rejectedErr *ResponseRejectedError
+	return errors.As(err, &rejectedErr) && !rejectedErr.Retryable()
+}
+
+// ErrResponsePending is returned when the aggregator buffered the response until it sees the task.
+// The buffer only lives in the aggregator memory, so the response must be kept and sent again later.
+var ErrResponsePending = errors.New("signed task response pending until the aggregator sees the task")
+
+// ErrNoAggregatorAvailable is returned when no aggregator endpoint could be reached
+var ErrNoAggregatorAvailable = errors.New("no aggregator endpoint available")
+
+type aggregatorEndpoint struct {
+	address string
+	client  *rpc.Client
+}
+
+// AggregatorEndpointStatus is the state of an aggregator endpoint, as reported on the status endpoint
+type AggregatorEndpointStatus struct {
+	Address string `json:"address"`
+	Healthy bool   `json:"healthy"`
+	Primary bool   `json:"primary"`
+}
+
+// AggregatorRpcClient is the client to communicate with the aggregators via RPC.
+// Endpoints are tried in order starting from the current primary, failing over to the
+// next one on errors or timeouts. With fan out enabled, responses are sent to every endpoint.
 type AggregatorRpcClient struct {
-	rpcClient            *rpc.Client
-	aggregatorIpPortAddr string
-	logger               logging.Logger
+	endpoints []*aggregatorEndpoint
+	primary   int
+	fanOut    bool
+	timeout   time.Duration
+	security  AggregatorRpcSecurity
+	mutex     sync.Mutex
+	logger    logging.Logger
+	metrics   *metrics.Metrics
 }
 
-const (
-	MaxRetries    = 10
-	RetryInterval = 10 * time.Second
-)
+// NewAggregatorRpcClient creates a client for the given aggregator endpoints. Unreachable endpoints
+// don't make it fail, they are reconnected by the health checks.
+func NewAggregatorRpcClient(aggregatorIpPortAddrs []string, fanOut bool, timeout time.Duration, security AggregatorRpcSecurity, logger logging.Logger, operatorMetrics *metrics.Metrics) (*AggregatorRpcClient, error) {
+	if len(aggregatorIpPortAddrs) == 0 {
+		return nil, fmt.Errorf("no aggregator endpoint provided")
+	}
+	if timeout == 0 {
+		timeout = DefaultAggregatorRpcTimeout
+	}
 
-func NewAggregatorRpcClient(aggregatorIpPortAddr string, logger logging.Logger) (*AggregatorRpcClient, error) {
-	client, err := rpc.DialHTTP("tcp", aggregatorIpPortAddr)
-	if err != nil {
-		return nil, err
+	c := &AggregatorRpcClient{
+		fanOut:   fanOut,
+		timeout:  timeout,
+		security: security,
+		logger:   logger,
+		metrics:  operatorMetrics,
+	}
+	for _, address := range aggregatorIpPortAddrs {
+		endpoint := &aggregatorEndpoint{address: address}
+		client, err := dialAggregator(address, security.TLSConfig)
+		if err != nil {
+			logger.Warn("Could not connect to aggregator, responses will be queued until it is reachable", "address", address, "err", err)
+		} else {
+			endpoint.client = client
+		}
+		c.endpoints = append(c.endpoints, endpoint)
+		c.reportEndpoint(endpoint)
 	}
 
-	return &AggregatorRpcClient{
-		rpcClient:            client,
-		aggregatorIpPortAddr: aggregatorIpPortAddr,
-		logger:               logger,
-	}, nil
+	return c, nil
 }
 
 // SendSignedTaskResponseToAggregator is the method called by operators via RPC to send
-// their signed task response.
-func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskResponse *types.SignedTaskResponse) {
+// their signed task response. Retries are handled by the response outbox, so each endpoint
+// is tried at most once.
+func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskResponse *types.SignedTaskResponse) error {
+	if c.fanOut {
+		return c.sendToAllEndpoints(signedTaskResponse)
+	}
+
+	c.mutex.Lock()
+	primary := c.primary
+	c.mutex.Unlock()
+
+	err := ErrNoAggregatorAvailable
+	for i := 0; i < len(c.endpoints); i++ {
+		idx := (primary + i) % len(c.endpoints)
+		err = c.sendToEndpoint(c.endpoints[idx], signedTaskResponse)
+		if err == nil {
+			c.setPrimary(idx)
+			return nil
+		}
+		if isFinalRejection(err) || errors.Is(err, ErrResponsePending) {
+			// The aggregator is up and checked the response, failing over won't help
+			c.setPrimary(idx)
+			return err
+		}
+		c.logger.Warn("Could not send signed task response to aggregator, failing over", "address", c.endpoints[idx].address, "err", err)
+	}
+	return err
+}
+
+// sendToAllEndpoints sends the response to every endpoint concurrently. It succeeds if any of them accepts it.
+func (c *AggregatorRpcClient) sendToAllEndpoints(signedTaskResponse *types.SignedTaskResponse) error {
+	errs := make(chan error, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		go func(endpoint *aggregatorEndpoint) {
+			err := c.sendToEndpoint(endpoint, signedTaskResponse)
+			if err != nil {
+				c.logger.Warn("Could not send signed task response to aggregator", "address", endpoint.address, "err", err)
+			}
+			errs <- err
+		}(endpoint)
+	}
+
+	err := ErrNoAggregatorAvailable
+	accepted := false
+	pending := false
+	for range c.endpoints {
+		if sendErr := <-errs; sendErr == nil {
+			accepted = true
+		} else if errors.Is(sendErr, ErrResponsePending) {
+			pending = true
+		} else if !isFinalRejection(err) {
+			// Final rejections are kept over other errors, so the caller doesn't retry
+			err = sendErr
+		}
+	}
+	if accepted {
+		return nil
+	}
+	if pending && !isFinalRejection(err) {
+		return ErrResponsePending
+	}
+	return err
+}
+
+func (c *AggregatorRpcClient) sendToEndpoint(endpoint *aggregatorEndpoint, signedTaskResponse *types.SignedTaskResponse) error {
+	client := c.endpointClient(endpoint)
+	if client == nil {
+		return fmt.Errorf("%w: %s is not connected", ErrNoAggregatorAvailable, endpoint.address)
+	}
+
 	var reply uint8
-	for retries := 0; retries < MaxRetries; retries++ {
-		err := c.rpcClient.Call("Aggregator.ProcessOperatorSignedTaskResponseV2", signedTaskResponse, &reply)
+	var call *rpc.Call
+	if c.security.SigningKey != nil {
+		// Signed again on every send, so retries carry a fresh timestamp
+		authenticated, err := types.SignSignedTaskResponse(signedTaskResponse, c.security.SigningKey, time.Now())
 		if err != nil {
-			c.logger.Error("Received error from aggregator", "err", err)
-			if errors.Is(err, rpc.ErrShutdown) {
-				c.logger.Error("Aggregator is shutdown. Reconnecting...")
-				client, err := rpc.DialHTTP("tcp", c.aggregatorIpPortAddr)
-				if err != nil {
-					c.logger.Error("Could not reconnect to aggregator", "err", err)
-					time.Sleep(RetryInterval)
-				} else {
-					c.rpcClient = client
-					c.logger.Info("Reconnected to aggregator")
-				}
-			} else {
-				c.logger.Infof("Received error from aggregator: %s. Retrying ProcessOperatorSignedTaskResponseV2 RPC call...", err)
-				time.Sleep(RetryInterval)
-			}
-		} else {
-			c.logger.Info("Signed task response header accepted by aggregator.", "reply", reply)
+			return fmt.Errorf("could not sign request to aggregator %s: %w", endpoint.address, err)
+		}
+		call = client.Go(processSignedTaskResponseV3Rpc, authenticated, &reply, make(chan *rpc.Call, 1))
+	} else {
+		call = client.Go(processSignedTaskResponseV2Rpc, signedTaskResponse, &reply, make(chan *rpc.Call, 1))
+	}
+	select {
+	case <-call.Done:
+	case <-time.After(c.timeout):
+		// The endpoint is not disconnected, as the aggregator may just be waiting for the task.
+		// If it is down, the health checks will find out.
+		return fmt.Errorf("aggregator %s timed out after %v", endpoint.address, c.timeout)
+	}
+
+	if call.Error != nil {
+		// Only transport errors mean the endpoint is down, rpc.ServerError is returned by the aggregator itself
+		var serverErr rpc.ServerError
+		if !errors.As(call.Error, &serverErr) {
+			c.disconnect(endpoint, client)
+		}
+		return fmt.Errorf("received error from aggregator %s: %w", endpoint.address, call.Error)
+	}
+	if reply == types.SignedTaskResponseDuplicate {
+		c.logger.Info("Signed task response already received by aggregator.", "address", endpoint.address)
+		return nil
+	}
+	if !types.SignedTaskResponseReplyIsAccepted(reply) {
+		return &ResponseRejectedError{Address: endpoint.address, Reply: reply}
+	}
+	if reply == types.SignedTaskResponseAcceptedPending {
+		c.logger.Info("Signed task response accepted by aggregator, pending until it sees the task.", "address", endpoint.address)
+		return fmt.Errorf("aggregator %s: %w", endpoint.address, ErrResponsePending)
+	}
+
+	c.logger.Info("Signed task response header accepted by aggregator.", "address", endpoint.address, "reply", reply)
+	return nil
+}
+
+// ProcessHealthChecks is a long-lived goroutine that checks the connected endpoints are still
+// reachable and reconnects to the ones that went down
+func (c *AggregatorRpcClient) ProcessHealthChecks() {
+	ticker := time.NewTicker(AggregatorHealthCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, endpoint := range c.endpoints {
+			c.checkEndpoint(endpoint)
+		}
+	}
+}
+
+func (c *AggregatorRpcClient) checkEndpoint(endpoint *aggregatorEndpoint) {
+	client := c.endpointClient(endpoint)
+	if client != nil {
+		conn, err := net.DialTimeout("tcp", endpoint.address, AggregatorHealthCheckTimeout)
+		if err == nil {
+			conn.Close()
 			return
 		}
+		c.logger.Warn("Aggregator health check failed", "address", endpoint.address, "err", err)
+		c.disconnect(endpoint, client)
+		return
+	}
+
+	client, err := dialAggregator(endpoint.address, c.security.TLSConfig)
+	if err != nil {
+		c.logger.Debug("Aggregator still unreachable", "address", endpoint.address, "err", err)
+		return
+	}
+
+	c.mutex.Lock()
+	endpoint.client = client
+	c.mutex.Unlock()
+	c.reportEndpoint(endpoint)
+	c.logger.Info("Reconnected to aggregator", "address", endpoint.address)
+}
+
+// dialAggregator is rpc.DialHTTP with a connection timeout, so blackholed endpoints don't block the operator.
+// The connection uses TLS if tlsConfig is not nil.
+func dialAggregator(address string, tlsConfig *tls.Config) (*rpc.Client, error) {
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		dialer := &net.Dialer{Timeout: AggregatorHealthCheckTimeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", address, AggregatorHealthCheckTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(AggregatorHealthCheckTimeout))
+	_, err = io.WriteString(conn, "CONNECT "+rpc.DefaultRPCPath+" HTTP/1.0\n\n")
+	if err == nil {
+		var resp *http.Response
+		resp, err = http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "CONNECT"})
+		if err == nil && resp.Status != "200 Connected to Go RPC" {
+			err = fmt.Errorf("unexpected HTTP response: %s", resp.Status)
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return rpc.NewClient(conn), nil
+}
+
+// Endpoints returns the state of every aggregator endpoint, in priority order
+func (c *AggregatorRpcClient) Endpoints() []AggregatorEndpointStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	statuses := make([]AggregatorEndpointStatus, 0, len(c.endpoints))
+	for i, endpoint := range c.endpoints {
+		statuses = append(statuses, AggregatorEndpointStatus{
+			Address: endpoint.address,
+			Healthy: endpoint.client != nil,
+			Primary: i == c.primary,
+		})
+	}
+	return statuses
+}
+
+func (c *AggregatorRpcClient) endpointClient(endpoint *aggregatorEndpoint) *rpc.Client {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return endpoint.client
+}
+
+func (c *AggregatorRpcClient) setPrimary(idx int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.primary != idx {
+		c.logger.Info("Aggregator primary endpoint changed", "address", c.endpoints[idx].address)
+		c.primary = idx
+	}
+}
+
+// disconnect drops the client of the endpoint, unless it was already replaced by a new one
+func (c *AggregatorRpcClient) disconnect(endpoint *aggregatorEndpoint, client *rpc.Client) {
+	c.mutex.Lock()
+	if endpoint.client != client {
+		c.mutex.Unlock()
+		return
+	}
+	endpoint.client = nil
+	c.mutex.Unlock()
+
+	client.Close()
+	c.reportEndpoint(endpoint)
+}
+
+func (c *AggregatorRpcClient) reportEndpoint(endpoint *aggregatorEndpoint) {
+	if c.metrics == nil {
+		return
 	}
+	c.metrics.SetOperatorAggregatorEndpointUp(endpoint.address, c.endpointClient(endpoint) != nil)
 }
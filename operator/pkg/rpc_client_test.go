@@ -0,0 +1,219 @@
+package operator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// testAggregator stands in for the aggregator RPC server, replying with a fixed code
+type testAggregator struct {
+	reply         uint8
+	responses     chan types.SignedTaskResponse
+	authenticated chan types.AuthenticatedSignedTaskResponse
+}
+
+func (a *testAggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	a.responses <- *signedTaskResponse
+	*reply = a.reply
+	return nil
+}
+
+func (a *testAggregator) ProcessOperatorSignedTaskResponseV3(authenticated *types.AuthenticatedSignedTaskResponse, reply *uint8) error {
+	a.authenticated <- *authenticated
+	*reply = a.reply
+	return nil
+}
+
+func startTestAggregator(t *testing.T, reply uint8) (*testAggregator, *httptest.Server) {
+	aggregator, server := newTestAggregator(t, reply)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return aggregator, httpServer
+}
+
+func newTestAggregator(t *testing.T, reply uint8) (*testAggregator, *rpc.Server) {
+	aggregator := &testAggregator{
+		reply:         reply,
+		responses:     make(chan types.SignedTaskResponse, 10),
+		authenticated: make(chan types.AuthenticatedSignedTaskResponse, 10),
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("Aggregator", aggregator); err != nil {
+		t.Fatalf("Could not register test aggregator: %v", err)
+	}
+	return aggregator, server
+}
+
+// unreachableAddress returns an address nothing is listening on
+func unreachableAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+func newTestRpcClient(t *testing.T, addresses []string, fanOut bool) *AggregatorRpcClient {
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	client, err := NewAggregatorRpcClient(addresses, fanOut, time.Second, AggregatorRpcSecurity{}, logger, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return client
+}
+
+func TestAggregatorRpcClientFailover(t *testing.T) {
+	_, rejecting := startTestAggregator(t, 1)
+	accepting, server := startTestAggregator(t, 0)
+	client := newTestRpcClient(t, []string{unreachableAddress(t), rejecting.Listener.Addr().String(), server.Listener.Addr().String()}, false)
+
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); err != nil {
+		t.Fatalf("Expected the response to be accepted by the last endpoint, got %v", err)
+	}
+	if len(accepting.responses) != 1 {
+		t.Fatalf("Expected the accepting aggregator to receive the response")
+	}
+
+	endpoints := client.Endpoints()
+	if endpoints[0].Healthy || !endpoints[2].Healthy || !endpoints[2].Primary {
+		t.Errorf("Expected the last endpoint to be the healthy primary, got %+v", endpoints)
+	}
+}
+
+func TestAggregatorRpcClientFanOut(t *testing.T) {
+	first, firstServer := startTestAggregator(t, 0)
+	second, secondServer := startTestAggregator(t, 0)
+	client := newTestRpcClient(t, []string{firstServer.Listener.Addr().String(), secondServer.Listener.Addr().String()}, true)
+
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(first.responses) != 1 || len(second.responses) != 1 {
+		t.Errorf("Expected every aggregator to receive the response")
+	}
+}
+
+func TestAggregatorRpcClientTypedReplies(t *testing.T) {
+	// A final rejection stops the failover, the next endpoint would reject it too
+	invalid, invalidServer := startTestAggregator(t, types.SignedTaskResponseInvalidSignature)
+	accepting, acceptingServer := startTestAggregator(t, types.SignedTaskResponseAccepted)
+	client := newTestRpcClient(t, []string{invalidServer.Listener.Addr().String(), acceptingServer.Listener.Addr().String()}, false)
+
+	err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{})
+	var rejectedErr *ResponseRejectedError
+	if !errors.As(err, &rejectedErr) || rejectedErr.Reply != types.SignedTaskResponseInvalidSignature || rejectedErr.Retryable() {
+		t.Fatalf("Expected a final invalid signature rejection, got %v", err)
+	}
+	if !errors.Is(err, ErrResponseRejected) {
+		t.Errorf("Expected the rejection to wrap ErrResponseRejected")
+	}
+	if len(invalid.responses) != 1 || len(accepting.responses) != 0 {
+		t.Errorf("Expected only the first aggregator to receive the response")
+	}
+
+	// Unknown task may be retried, so it fails over
+	_, unknownTaskServer := startTestAggregator(t, types.SignedTaskResponseUnknownTask)
+	client = newTestRpcClient(t, []string{unknownTaskServer.Listener.Addr().String(), acceptingServer.Listener.Addr().String()}, false)
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); err != nil {
+		t.Fatalf("Expected the response to fail over to the accepting aggregator, got %v", err)
+	}
+
+	// A duplicate means the aggregator already has the response
+	_, duplicateServer := startTestAggregator(t, types.SignedTaskResponseDuplicate)
+	client = newTestRpcClient(t, []string{duplicateServer.Listener.Addr().String()}, false)
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); err != nil {
+		t.Fatalf("Expected a duplicate to be treated as accepted, got %v", err)
+	}
+
+	// The aggregator only buffers responses for tasks it has not seen yet in memory, so they are not delivered
+	_, pendingServer := startTestAggregator(t, types.SignedTaskResponseAcceptedPending)
+	client = newTestRpcClient(t, []string{pendingServer.Listener.Addr().String()}, false)
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); !errors.Is(err, ErrResponsePending) {
+		t.Fatalf("Expected ErrResponsePending, got %v", err)
+	}
+}
+
+func TestAggregatorRpcClientStartsWithoutAggregator(t *testing.T) {
+	address := unreachableAddress(t)
+	client := newTestRpcClient(t, []string{address}, false)
+
+	err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{})
+	if !errors.Is(err, ErrNoAggregatorAvailable) {
+		t.Fatalf("Expected ErrNoAggregatorAvailable, got %v", err)
+	}
+
+	// Once the aggregator comes up, the health check reconnects to it
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("Could not listen on %s again: %v", address, err)
+	}
+	aggregator := &testAggregator{responses: make(chan types.SignedTaskResponse, 1)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("Aggregator", aggregator); err != nil {
+		t.Fatalf("Could not register test aggregator: %v", err)
+	}
+	httpServer := httptest.NewUnstartedServer(server)
+	httpServer.Listener.Close()
+	httpServer.Listener = listener
+	httpServer.Start()
+	defer httpServer.Close()
+
+	client.checkEndpoint(client.endpoints[0])
+	if err := client.SendSignedTaskResponseToAggregator(&types.SignedTaskResponse{}); err != nil {
+		t.Fatalf("Expected the response to be accepted after reconnecting, got %v", err)
+	}
+}
+
+func TestAggregatorRpcClientSignedRequestsOverTLS(t *testing.T) {
+	aggregator, server := newTestAggregator(t, types.SignedTaskResponseAccepted)
+	httpServer := httptest.NewTLSServer(server)
+	t.Cleanup(httpServer.Close)
+
+	rootCas := x509.NewCertPool()
+	rootCas.AddCert(httpServer.Certificate())
+	signingKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Could not generate key: %v", err)
+	}
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("Could not create logger: %v", err)
+	}
+	security := AggregatorRpcSecurity{TLSConfig: &tls.Config{RootCAs: rootCas}, SigningKey: signingKey}
+	client, err := NewAggregatorRpcClient([]string{httpServer.Listener.Addr().String()}, false, time.Second, security, logger, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	signedTaskResponse := &types.SignedTaskResponse{BatchIdentifierHash: [32]byte{1}}
+	if err := client.SendSignedTaskResponseToAggregator(signedTaskResponse); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(aggregator.responses) != 0 || len(aggregator.authenticated) != 1 {
+		t.Fatalf("Expected the response to be sent signed")
+	}
+
+	authenticated := <-aggregator.authenticated
+	signer, err := authenticated.Signer()
+	if err != nil || signer != crypto.PubkeyToAddress(signingKey.PublicKey) {
+		t.Errorf("Expected the request to be signed by the operator key, got %s (%v)", signer.Hex(), err)
+	}
+	if authenticated.SignedTaskResponse.BatchIdentifierHash != signedTaskResponse.BatchIdentifierHash {
+		t.Errorf("Expected the signed request to carry the response")
+	}
+}
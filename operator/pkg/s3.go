@@ -13,6 +13,9 @@ import (
 
 func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string, expectedMerkleRoot [32]byte, maxRetries int, retryDelay time.Duration) ([]VerificationData, error) {
 	o.Logger.Infof("Getting batch from data service, batchURL: %s", batchURL)
+	trace := taskTraceFromContext(ctx)
+	endDownload := trace.startSpan("download")
+	defer endDownload(errTaskStageFailed)
 
 	var resp *http.Response
 	var err error
@@ -88,16 +91,23 @@ func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string,
 		return nil, fmt.Errorf("batch size exceeds max batch size %d", o.Config.Operator.MaxBatchSize)
 	}
 
+	endDownload(nil)
+
 	// Checks if downloaded merkle root is the same as the expected one
 	o.Logger.Infof("Verifying batch merkle tree...")
+	endMerkleRootCheck := trace.startSpan("merkle_root_check")
 	merkle_root_check, err := merkle_tree.VerifyMerkleTreeBatch(batchBytes, expectedMerkleRoot)
 	if err != nil || !merkle_root_check {
+		endMerkleRootCheck(errTaskStageFailed)
 		return nil, fmt.Errorf("Error while verifying merkle tree batch")
 	}
+	endMerkleRootCheck(nil)
 	o.Logger.Infof("Batch merkle tree verified")
 
 	var batch []VerificationData
 
+	endDecode := trace.startSpan("decode")
+	defer endDecode(errTaskStageFailed)
 	decoder, err := createDecoderMode()
 	if err != nil {
 		return nil, fmt.Errorf("error creating CBOR decoder: %s", err)
@@ -113,6 +123,7 @@ func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string,
 			return nil, err
 		}
 	}
+	endDecode(nil)
 
 	return batch, nil
 }
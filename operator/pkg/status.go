@@ -0,0 +1,54 @@
+package operator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// OperatorStatus is the operator state reported by the status endpoint
+type OperatorStatus struct {
+	Address             string                     `json:"address"`
+	OperatorId          string                     `json:"operator_id"`
+	LastProcessedBlock  uint32                     `json:"last_processed_block"`
+	VerificationPolicy  VerificationPolicyStatus   `json:"verification_policy"`
+	ResponseOutboxDepth int                        `json:"response_outbox_depth"`
+	AggregatorEndpoints []AggregatorEndpointStatus `json:"aggregator_endpoints"`
+}
+
+func (o *Operator) Status() OperatorStatus {
+	return OperatorStatus{
+		Address:             o.Address.Hex(),
+		OperatorId:          "0x" + hex.EncodeToString(o.OperatorId[:]),
+		LastProcessedBlock:  o.LastProcessedBlock(),
+		VerificationPolicy:  o.verificationPolicy.Status(),
+		ResponseOutboxDepth: o.responseOutbox.Len(),
+		AggregatorEndpoints: o.aggRpcClient.Endpoints(),
+	}
+}
+
+// ServeStatus starts an HTTP server that reports the operator status as JSON on /status
+func (o *Operator) ServeStatus() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(o.Status()); err != nil {
+			o.Logger.Error("Error encoding operator status", "err", err)
+		}
+	})
+
+	server := http.Server{
+		Addr:              o.Config.Operator.StatusIpPortAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+
+	o.Logger.Info("Starting status server on address", "address", o.Config.Operator.StatusIpPortAddress)
+	return server.ListenAndServe()
+}
@@ -0,0 +1,82 @@
+package operator
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Error of the stages that end without reporting one, like a download that returns early
+var errTaskStageFailed = errors.New("stage failed")
+
+// taskTrace records how long the operator spends on each stage of a batch. Its context is sent along with the
+// signed task response, and the aggregator links it into the batch trace.
+// A nil taskTrace records nothing, so batches can be processed without one.
+type taskTrace struct {
+	mutex        sync.Mutex
+	traceContext types.TaskTraceContext
+}
+
+type taskTraceKey struct{}
+
+func newTaskTrace(batchMerkleRoot [32]byte) *taskTrace {
+	var spanId [8]byte
+	_, _ = rand.Read(spanId[:])
+	return &taskTrace{
+		traceContext: types.TaskTraceContext{
+			TraceId:   types.TaskTraceId(batchMerkleRoot),
+			SpanId:    spanId,
+			StartTime: time.Now().UnixNano(),
+		},
+	}
+}
+
+func contextWithTaskTrace(ctx context.Context, trace *taskTrace) context.Context {
+	return context.WithValue(ctx, taskTraceKey{}, trace)
+}
+
+// taskTraceFromContext returns nil if the context has no trace
+func taskTraceFromContext(ctx context.Context) *taskTrace {
+	trace, _ := ctx.Value(taskTraceKey{}).(*taskTrace)
+	return trace
+}
+
+// startSpan starts a stage, and returns the function that ends it with its error, if any.
+// Only the first call of the returned function ends the stage, so it can also be deferred.
+func (t *taskTrace) startSpan(name string) func(err error) {
+	if t == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			span := types.TaskSpan{Name: name, StartTime: start.UnixNano(), EndTime: time.Now().UnixNano()}
+			if err != nil {
+				span.Error = err.Error()
+			}
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			if len(t.traceContext.Spans) < types.MaxTaskTraceSpans {
+				t.traceContext.Spans = append(t.traceContext.Spans, span)
+			}
+		})
+	}
+}
+
+// finish ends the trace and returns its context
+func (t *taskTrace) finish() *types.TaskTraceContext {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	traceContext := t.traceContext
+	traceContext.EndTime = time.Now().UnixNano()
+	traceContext.Spans = append([]types.TaskSpan(nil), t.traceContext.Spans...)
+	return &traceContext
+}
@@ -0,0 +1,61 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+func TestTaskTraceRecordsStages(t *testing.T) {
+	batchMerkleRoot := [32]byte{1, 2, 3}
+	trace := newTaskTrace(batchMerkleRoot)
+	ctx := contextWithTaskTrace(context.Background(), trace)
+
+	endDownload := taskTraceFromContext(ctx).startSpan("download")
+	endDownload(nil)
+	// Only the first end counts, so a deferred end doesn't overwrite it
+	endDownload(errTaskStageFailed)
+
+	endVerify := taskTraceFromContext(ctx).startSpan("verify")
+	endVerify(errors.New("invalid proof"))
+
+	traceContext := trace.finish()
+	if traceContext.TraceId != types.TaskTraceId(batchMerkleRoot) {
+		t.Errorf("Expected the trace id of the batch, got %x", traceContext.TraceId)
+	}
+	if traceContext.EndTime < traceContext.StartTime {
+		t.Errorf("Expected the trace to end after it started")
+	}
+	if len(traceContext.Spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(traceContext.Spans))
+	}
+	if traceContext.Spans[0].Name != "download" || traceContext.Spans[0].Error != "" {
+		t.Errorf("Unexpected download span: %+v", traceContext.Spans[0])
+	}
+	if traceContext.Spans[1].Name != "verify" || traceContext.Spans[1].Error != "invalid proof" {
+		t.Errorf("Unexpected verify span: %+v", traceContext.Spans[1])
+	}
+}
+
+func TestTaskTraceSpansAreCapped(t *testing.T) {
+	trace := newTaskTrace([32]byte{1})
+	for i := 0; i < types.MaxTaskTraceSpans+5; i++ {
+		trace.startSpan("stage")(nil)
+	}
+	if spans := len(trace.finish().Spans); spans != types.MaxTaskTraceSpans {
+		t.Errorf("Expected %d spans, got %d", types.MaxTaskTraceSpans, spans)
+	}
+}
+
+func TestTaskTraceWithoutTrace(t *testing.T) {
+	trace := taskTraceFromContext(context.Background())
+	if trace != nil {
+		t.Fatalf("Expected no trace")
+	}
+	trace.startSpan("download")(nil)
+	if trace.finish() != nil {
+		t.Errorf("Expected no trace context")
+	}
+}
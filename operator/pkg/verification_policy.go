@@ -0,0 +1,142 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// VerificationClass groups proving systems by the resources their verification takes
+type VerificationClass uint8
+
+const (
+	LightVerification VerificationClass = iota
+	HeavyVerification
+)
+
+func (c VerificationClass) String() string {
+	if c == HeavyVerification {
+		return "heavy"
+	}
+	return "light"
+}
+
+// ProvingSystems are all the proving systems the operator can verify
+var ProvingSystems = []common.ProvingSystemId{common.GnarkPlonkBls12_381, common.GnarkPlonkBn254, common.Groth16Bn254, common.SP1, common.Risc0}
+
+// VerificationClassOf returns the resource class of a proving system.
+// zkVM proofs (SP1, Risc0) are heavy, gnark proofs are light.
+func VerificationClassOf(provingSystemId common.ProvingSystemId) VerificationClass {
+	switch provingSystemId {
+	case common.SP1, common.Risc0:
+		return HeavyVerification
+	default:
+		return LightVerification
+	}
+}
+
+// VerificationPolicy is the operator-local policy applied when verifying proofs.
+// It limits the concurrent verifications per resource class, sets per proving system
+// timeouts and acts as a local kill-switch for proving systems.
+type VerificationPolicy struct {
+	concurrency       map[VerificationClass]int
+	slots             map[VerificationClass]chan struct{}
+	timeouts          map[common.ProvingSystemId]time.Duration
+	disabledVerifiers map[common.ProvingSystemId]struct{}
+}
+
+// VerificationPolicyStatus is the effective policy, as reported on the status endpoint
+type VerificationPolicyStatus struct {
+	Concurrency       map[string]int    `json:"concurrency"`
+	Timeouts          map[string]string `json:"timeouts"`
+	DisabledVerifiers []string          `json:"disabled_verifiers"`
+}
+
+func NewVerificationPolicy(policyConfig config.VerificationPolicyConfig) (*VerificationPolicy, error) {
+	if policyConfig.HeavyVerificationsConcurrency < 0 || policyConfig.LightVerificationsConcurrency < 0 {
+		return nil, fmt.Errorf("verifications concurrency can't be negative")
+	}
+
+	policy := &VerificationPolicy{
+		concurrency: map[VerificationClass]int{
+			HeavyVerification: policyConfig.HeavyVerificationsConcurrency,
+			LightVerification: policyConfig.LightVerificationsConcurrency,
+		},
+		slots:             make(map[VerificationClass]chan struct{}),
+		timeouts:          make(map[common.ProvingSystemId]time.Duration),
+		disabledVerifiers: make(map[common.ProvingSystemId]struct{}),
+	}
+
+	for class, concurrency := range policy.concurrency {
+		if concurrency > 0 {
+			policy.slots[class] = make(chan struct{}, concurrency)
+		}
+	}
+
+	for provingSystem, timeout := range policyConfig.Timeouts {
+		provingSystemId, err := common.ProvingSystemIdFromString(provingSystem)
+		if err != nil {
+			return nil, err
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("timeout of %s can't be negative", provingSystem)
+		}
+		policy.timeouts[provingSystemId] = timeout
+	}
+
+	for _, provingSystem := range policyConfig.DisabledVerifiers {
+		provingSystemId, err := common.ProvingSystemIdFromString(provingSystem)
+		if err != nil {
+			return nil, err
+		}
+		policy.disabledVerifiers[provingSystemId] = struct{}{}
+	}
+
+	return policy, nil
+}
+
+// IsLocallyDisabled returns true if the operator refuses to verify proofs of the given proving system
+func (p *VerificationPolicy) IsLocallyDisabled(provingSystemId common.ProvingSystemId) bool {
+	_, ok := p.disabledVerifiers[provingSystemId]
+	return ok
+}
+
+// Timeout returns the max duration of a verification of the given proving system, 0 if it is not limited
+func (p *VerificationPolicy) Timeout(provingSystemId common.ProvingSystemId) time.Duration {
+	return p.timeouts[provingSystemId]
+}
+
+// Acquire blocks until there is a free verification slot in the class of the given proving system.
+// The returned function must be called to release the slot once the verification finishes.
+func (p *VerificationPolicy) Acquire(provingSystemId common.ProvingSystemId) func() {
+	slots, ok := p.slots[VerificationClassOf(provingSystemId)]
+	if !ok {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+func (p *VerificationPolicy) Status() VerificationPolicyStatus {
+	status := VerificationPolicyStatus{
+		Concurrency:       make(map[string]int),
+		Timeouts:          make(map[string]string),
+		DisabledVerifiers: []string{},
+	}
+	for class, concurrency := range p.concurrency {
+		status.Concurrency[class.String()] = concurrency
+	}
+	for provingSystemId, timeout := range p.timeouts {
+		name, _ := common.ProvingSystemIdToString(provingSystemId)
+		status.Timeouts[name] = timeout.String()
+	}
+	for provingSystemId := range p.disabledVerifiers {
+		name, _ := common.ProvingSystemIdToString(provingSystemId)
+		status.DisabledVerifiers = append(status.DisabledVerifiers, name)
+	}
+	sort.Strings(status.DisabledVerifiers)
+	return status
+}
@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+func TestVerificationClassOf(t *testing.T) {
+	heavy := []common.ProvingSystemId{common.SP1, common.Risc0}
+	light := []common.ProvingSystemId{common.GnarkPlonkBls12_381, common.GnarkPlonkBn254, common.Groth16Bn254}
+
+	for _, provingSystemId := range heavy {
+		if VerificationClassOf(provingSystemId) != HeavyVerification {
+			t.Errorf("Proving system %d should be heavy", provingSystemId)
+		}
+	}
+	for _, provingSystemId := range light {
+		if VerificationClassOf(provingSystemId) != LightVerification {
+			t.Errorf("Proving system %d should be light", provingSystemId)
+		}
+	}
+}
+
+func TestNewVerificationPolicy(t *testing.T) {
+	t.Run("Parses timeouts and disabled verifiers", func(t *testing.T) {
+		policy, err := NewVerificationPolicy(config.VerificationPolicyConfig{
+			HeavyVerificationsConcurrency: 1,
+			Timeouts:                      map[string]time.Duration{"SP1": time.Minute},
+			DisabledVerifiers:             []string{"Risc0"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if policy.Timeout(common.SP1) != time.Minute {
+			t.Errorf("Expected SP1 timeout of 1m, got %v", policy.Timeout(common.SP1))
+		}
+		if policy.Timeout(common.Groth16Bn254) != 0 {
+			t.Errorf("Expected no Groth16 timeout, got %v", policy.Timeout(common.Groth16Bn254))
+		}
+		if !policy.IsLocallyDisabled(common.Risc0) {
+			t.Errorf("Risc0 should be locally disabled")
+		}
+		if policy.IsLocallyDisabled(common.SP1) {
+			t.Errorf("SP1 should not be locally disabled")
+		}
+	})
+
+	t.Run("Rejects unknown proving systems", func(t *testing.T) {
+		_, err := NewVerificationPolicy(config.VerificationPolicyConfig{
+			DisabledVerifiers: []string{"Halo2"},
+		})
+		if err == nil {
+			t.Errorf("An error was expected for an unknown proving system")
+		}
+	})
+
+	t.Run("Rejects negative concurrency", func(t *testing.T) {
+		_, err := NewVerificationPolicy(config.VerificationPolicyConfig{
+			LightVerificationsConcurrency: -1,
+		})
+		if err == nil {
+			t.Errorf("An error was expected for a negative concurrency")
+		}
+	})
+}
+
+func TestVerificationPolicyAcquire(t *testing.T) {
+	policy, err := NewVerificationPolicy(config.VerificationPolicyConfig{HeavyVerificationsConcurrency: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	release := policy.Acquire(common.SP1)
+
+	acquired := make(chan struct{})
+	go func() {
+		releaseRisc0 := policy.Acquire(common.Risc0)
+		close(acquired)
+		releaseRisc0()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatalf("Heavy slot acquired twice with a concurrency of 1")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Light verifications are not limited
+	policy.Acquire(common.Groth16Bn254)()
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("Heavy slot was not released")
+	}
+}
@@ -2,3 +2,19 @@ CREATE TABLE IF NOT EXISTS operator_versions (
   address CHAR(42) PRIMARY KEY,
   version VARCHAR(16) NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS operator_heartbeats (
+  address CHAR(42) PRIMARY KEY,
+  operator_id CHAR(66) NOT NULL,
+  version VARCHAR(16) NOT NULL,
+  last_processed_block BIGINT NOT NULL,
+  verified_batches BIGINT NOT NULL,
+  rejected_batches BIGINT NOT NULL,
+  enabled_verifiers TEXT NOT NULL,
+  eth_rpc_healthy BOOLEAN NOT NULL,
+  eth_rpc_url TEXT NOT NULL,
+  eth_rpc_url_fallback TEXT NOT NULL,
+  eth_ws_url TEXT NOT NULL,
+  eth_ws_url_fallback TEXT NOT NULL,
+  last_seen_at BIGINT NOT NULL
+);
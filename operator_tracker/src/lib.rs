@@ -1,8 +1,12 @@
+use std::collections::HashMap;
+use std::sync::Mutex;
+
 use axum::http::StatusCode;
 use axum::{body::Body, response::IntoResponse};
 use base64::prelude::BASE64_STANDARD;
 use base64::Engine;
 use ethers::providers::{Http, Provider};
+use ethers::types::Address;
 use ethers::utils::keccak256;
 use ethers::{contract::abigen, core::types::Signature};
 use log::{debug, error, info};
@@ -22,12 +26,68 @@ pub struct OperatorVersionPayload {
     pub signature: String,
 }
 
+/// Heartbeats signed further than this from the tracker clock are rejected, so they can't be replayed later
+pub const HEARTBEAT_MAX_CLOCK_SKEW_SECS: i64 = 5 * 60;
+
+/// Body of POST /heartbeats. The signature is over the keccak256 of the payload bytes, as sent
+#[derive(serde::Deserialize)]
+pub struct SignedHeartbeatPayload {
+    pub payload: String,
+    pub signature: String,
+}
+
+/// Operator state reported on every heartbeat
+#[derive(serde::Deserialize, Debug)]
+pub struct Heartbeat {
+    pub version: String,
+    pub address: String,
+    pub operator_id: String,
+    pub last_processed_block: u32,
+    pub verified_batches: u64,
+    pub rejected_batches: u64,
+    #[serde(default)]
+    pub enabled_verifiers: Vec<String>,
+    pub eth_rpc_healthy: bool,
+    #[serde(default)]
+    pub eth_rpc_url: String,
+    #[serde(default)]
+    pub eth_rpc_url_fallback: String,
+    #[serde(default)]
+    pub eth_ws_url: String,
+    #[serde(default)]
+    pub eth_ws_url_fallback: String,
+    pub timestamp: i64,
+    pub nonce: String,
+}
+
+/// Nonces of the heartbeats accepted within the clock skew window, by operator address
+#[derive(Default)]
+pub struct HeartbeatNonces {
+    seen: Mutex<HashMap<(Address, String), i64>>,
+}
+
+impl HeartbeatNonces {
+    /// Records the nonce. Returns false if the operator already used it
+    pub fn insert(&self, address: Address, nonce: &str, timestamp: i64, now: i64) -> bool {
+        let mut seen = self.seen.lock().unwrap_or_else(|err| err.into_inner());
+        // Older heartbeats are rejected by their timestamp, so their nonces can be forgotten
+        seen.retain(|_, seen_timestamp| *seen_timestamp + HEARTBEAT_MAX_CLOCK_SKEW_SECS >= now);
+        let key = (address, nonce.to_string());
+        if seen.contains_key(&key) {
+            return false;
+        }
+        seen.insert(key, timestamp);
+        true
+    }
+}
+
 #[derive(serde::Serialize, Debug)]
 pub enum OperatorVersionError {
     InvalidSignature,
     OperatorNotRegistered,
     InternalServerError,
     BadRequest,
+    ReplayedHeartbeat,
 }
 
 impl IntoResponse for OperatorVersionError {
@@ -48,6 +108,9 @@ impl IntoResponse for OperatorVersionError {
             OperatorVersionError::BadRequest => builder
                 .status(axum::http::StatusCode::BAD_REQUEST)
                 .body(Body::from(err_msg)),
+            OperatorVersionError::ReplayedHeartbeat => builder
+                .status(axum::http::StatusCode::UNAUTHORIZED)
+                .body(Body::from(err_msg)),
         };
 
         builder.unwrap_or_default()
@@ -81,17 +144,39 @@ pub async fn create_or_update_operator_version(
         payload.version
     );
 
-    // check version matches v*.*.* format with regex
+    check_version(&payload.version)?;
+
+    // hash keccak256(version) and recover address from signature
+    let operator_address = recover_signer(&payload.signature, payload.version.as_bytes())?;
+
+    info!("Operator address: {:?}", operator_address);
+
+    check_operator_registered(registry_coordinator, operator_address).await?;
+
+    info!("Operator is registered, updating version");
+
+    // Convert to string
+    // Using {:?} because to_string() gives shortened address
+    let operator_address = format!("{:?}", operator_address);
+
+    store_operator_version(db, &operator_address, &payload.version).await
+}
+
+/// Checks the version matches the v*.*.* format
+fn check_version(version: &str) -> Result<(), OperatorVersionError> {
     if !regex::Regex::new(r"^v\d+\.\d+\.\d+$")
         .unwrap()
-        .is_match(&payload.version)
+        .is_match(version)
     {
         return Err(OperatorVersionError::InvalidSignature);
     }
+    Ok(())
+}
 
-    // decode base64 signature
+/// Recovers the address that signed the keccak256 of the message from a base64 encoded signature
+fn recover_signer(signature: &str, message: &[u8]) -> Result<Address, OperatorVersionError> {
     let signature = BASE64_STANDARD
-        .decode(payload.signature)
+        .decode(signature)
         .map_err(|_| OperatorVersionError::InvalidSignature)?;
 
     if signature.len() != 65 {
@@ -104,16 +189,16 @@ pub async fn create_or_update_operator_version(
         v: signature[64] as u64,
     };
 
-    // hash keccak256(version) and recover address from signature
-    let hashed_version = keccak256(payload.version.as_bytes());
-
-    let operator_address = signature
-        .recover(hashed_version)
-        .map_err(|_| OperatorVersionError::InvalidSignature)?;
-
-    info!("Operator address: {:?}", operator_address);
+    signature
+        .recover(keccak256(message))
+        .map_err(|_| OperatorVersionError::InvalidSignature)
+}
 
-    // Check if operator is registered on registry coordinator
+/// Checks the operator is registered on the registry coordinator
+async fn check_operator_registered(
+    registry_coordinator: &RegistryCoordinatorContract,
+    operator_address: Address,
+) -> Result<(), OperatorVersionError> {
     let status = registry_coordinator
         .get_operator_status(operator_address)
         .await
@@ -122,31 +207,32 @@ pub async fn create_or_update_operator_version(
     if status != 1 {
         return Err(OperatorVersionError::OperatorNotRegistered);
     }
+    Ok(())
+}
 
-    info!("Operator is registered, updating version");
-
-    // Convert to string
-    // Using {:?} because to_string() gives shortened address
-    let operator_address = format!("{:?}", operator_address);
-
+/// Stores the operator version. Returns the new row if the operator had no version yet
+async fn store_operator_version(
+    db: &PgPool,
+    operator_address: &String,
+    version: &String,
+) -> Result<Option<OperatorVersion>, OperatorVersionError> {
     // Store operator version in database
     // - If operator version already exists, update it
     // - If operator version does not exist, insert it
-    let row = match get_operator_version(db, &operator_address).await {
+    let row = match get_operator_version(db, operator_address).await {
         Ok(row) => row,
         Err(_) => return Err(OperatorVersionError::InternalServerError),
     };
 
     let (query, response) = if let Some(row) = row {
-        let version: String = row.version;
-        if version == payload.version {
+        if row.version == *version {
             debug!("Operator {} version already up to date", operator_address);
             return Ok(None); // No need to update
         }
 
         debug!(
             "Updating operator {} version from {} to {}",
-            operator_address, version, payload.version
+            operator_address, row.version, version
         );
 
         (
@@ -156,25 +242,109 @@ pub async fn create_or_update_operator_version(
     } else {
         debug!(
             "Inserting operator {} version {}",
-            operator_address, payload.version
+            operator_address, version
         );
 
         (
             sqlx::query("INSERT INTO operator_versions (address, version) VALUES ($1, $2)"),
             Some(OperatorVersion {
                 address: operator_address.clone(),
-                version: payload.version.clone(),
+                version: version.clone(),
             }),
         )
     };
 
-    db.execute(query.bind(&operator_address).bind(&payload.version))
+    db.execute(query.bind(operator_address).bind(version))
         .await
         .map_err(|_| OperatorVersionError::InternalServerError)?;
 
     Ok(response)
 }
 
+/// Checks the heartbeat is signed by the operator it reports, recently and with a new nonce
+pub fn verify_heartbeat(
+    signed_heartbeat: &SignedHeartbeatPayload,
+    nonces: &HeartbeatNonces,
+    now: i64,
+) -> Result<(Address, Heartbeat), OperatorVersionError> {
+    let operator_address = recover_signer(
+        &signed_heartbeat.signature,
+        signed_heartbeat.payload.as_bytes(),
+    )?;
+
+    let heartbeat: Heartbeat = serde_json::from_str(&signed_heartbeat.payload)
+        .map_err(|_| OperatorVersionError::BadRequest)?;
+    check_version(&heartbeat.version)?;
+
+    let reported_address: Address = heartbeat
+        .address
+        .parse()
+        .map_err(|_| OperatorVersionError::BadRequest)?;
+    if reported_address != operator_address {
+        return Err(OperatorVersionError::InvalidSignature);
+    }
+
+    if (now - heartbeat.timestamp).abs() > HEARTBEAT_MAX_CLOCK_SKEW_SECS {
+        return Err(OperatorVersionError::ReplayedHeartbeat);
+    }
+    if !nonces.insert(operator_address, &heartbeat.nonce, heartbeat.timestamp, now) {
+        return Err(OperatorVersionError::ReplayedHeartbeat);
+    }
+
+    Ok((operator_address, heartbeat))
+}
+
+/// Verifies a heartbeat, then stores it along with the operator version
+pub async fn record_heartbeat(
+    db: &PgPool,
+    registry_coordinator: &RegistryCoordinatorContract,
+    nonces: &HeartbeatNonces,
+    signed_heartbeat: SignedHeartbeatPayload,
+    now: i64,
+) -> Result<(), OperatorVersionError> {
+    let (operator_address, heartbeat) = verify_heartbeat(&signed_heartbeat, nonces, now)?;
+    debug!("Received heartbeat from operator {:?}", operator_address);
+
+    check_operator_registered(registry_coordinator, operator_address).await?;
+
+    // Using {:?} because to_string() gives shortened address
+    let operator_address = format!("{:?}", operator_address);
+    store_operator_version(db, &operator_address, &heartbeat.version).await?;
+
+    let query = sqlx::query(
+        "INSERT INTO operator_heartbeats (address, operator_id, version, last_processed_block, \
+         verified_batches, rejected_batches, enabled_verifiers, eth_rpc_healthy, eth_rpc_url, \
+         eth_rpc_url_fallback, eth_ws_url, eth_ws_url_fallback, last_seen_at) \
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) \
+         ON CONFLICT (address) DO UPDATE SET operator_id = EXCLUDED.operator_id, \
+         version = EXCLUDED.version, last_processed_block = EXCLUDED.last_processed_block, \
+         verified_batches = EXCLUDED.verified_batches, rejected_batches = EXCLUDED.rejected_batches, \
+         enabled_verifiers = EXCLUDED.enabled_verifiers, eth_rpc_healthy = EXCLUDED.eth_rpc_healthy, \
+         eth_rpc_url = EXCLUDED.eth_rpc_url, eth_rpc_url_fallback = EXCLUDED.eth_rpc_url_fallback, \
+         eth_ws_url = EXCLUDED.eth_ws_url, eth_ws_url_fallback = EXCLUDED.eth_ws_url_fallback, \
+         last_seen_at = EXCLUDED.last_seen_at",
+    )
+    .bind(&operator_address)
+    .bind(&heartbeat.operator_id)
+    .bind(&heartbeat.version)
+    .bind(heartbeat.last_processed_block as i64)
+    .bind(heartbeat.verified_batches as i64)
+    .bind(heartbeat.rejected_batches as i64)
+    .bind(heartbeat.enabled_verifiers.join(","))
+    .bind(heartbeat.eth_rpc_healthy)
+    .bind(&heartbeat.eth_rpc_url)
+    .bind(&heartbeat.eth_rpc_url_fallback)
+    .bind(&heartbeat.eth_ws_url)
+    .bind(&heartbeat.eth_ws_url_fallback)
+    .bind(now);
+
+    db.execute(query)
+        .await
+        .map_err(|_| OperatorVersionError::InternalServerError)?;
+
+    Ok(())
+}
+
 pub async fn list_operator_versions(
     db: &PgPool,
 ) -> Result<Vec<OperatorVersion>, OperatorVersionError> {
@@ -1,4 +1,5 @@
 use std::sync::Arc;
+use std::time::{SystemTime, UNIX_EPOCH};
 
 use axum::body::Body;
 use axum::extract::Path;
@@ -15,7 +16,10 @@ use log::debug;
 use log::info;
 use operator_tracker::create_or_update_operator_version;
 use operator_tracker::serialize_or_err;
-use operator_tracker::{OperatorVersionPayload, RegistryCoordinator, RegistryCoordinatorContract};
+use operator_tracker::{
+    HeartbeatNonces, OperatorVersionPayload, RegistryCoordinator, RegistryCoordinatorContract,
+    SignedHeartbeatPayload,
+};
 use sqlx::postgres::{PgPool, PgPoolOptions};
 use sqlx::Executor;
 
@@ -30,6 +34,7 @@ use sqlx::Executor;
 struct AppState {
     pool: PgPool,
     registry_coordinator: RegistryCoordinatorContract,
+    heartbeat_nonces: Arc<HeartbeatNonces>,
 }
 
 /// The main entry point for the application
@@ -83,6 +88,7 @@ async fn main() {
     let state = AppState {
         pool,
         registry_coordinator,
+        heartbeat_nonces: Arc::new(HeartbeatNonces::default()),
     };
 
     let listener = tokio::net::TcpListener::bind(listen_addr)
@@ -93,6 +99,7 @@ async fn main() {
         .route("/versions", post(post_operator_version))
         .route("/versions", get(list_operator_versions))
         .route("/versions/:address", get(get_operator_version))
+        .route("/heartbeats", post(post_operator_heartbeat))
         .with_state(state);
 
     axum::serve(listener, router)
@@ -140,6 +147,35 @@ async fn post_operator_version(
     }
 }
 
+async fn post_operator_heartbeat(
+    state: State<AppState>,
+    Json(payload): Json<SignedHeartbeatPayload>,
+) -> axum::http::Response<Body> {
+    let now = SystemTime::now()
+        .duration_since(UNIX_EPOCH)
+        .map(|duration| duration.as_secs() as i64)
+        .unwrap_or_default();
+
+    match operator_tracker::record_heartbeat(
+        &state.pool,
+        &state.registry_coordinator,
+        &state.heartbeat_nonces,
+        payload,
+        now,
+    )
+    .await
+    {
+        Ok(()) => axum::http::Response::builder()
+            .status(StatusCode::NO_CONTENT)
+            .body(Body::empty())
+            .unwrap_or_default(), // Should never fail but dont panic
+        Err(err) => {
+            info!("Heartbeat rejected: {:?}", err);
+            err.into_response()
+        }
+    }
+}
+
 async fn list_operator_versions(state: State<AppState>) -> axum::http::Response<Body> {
     let rows = match operator_tracker::list_operator_versions(&state.pool).await {
         Ok(rows) => rows,